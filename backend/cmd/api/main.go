@@ -2,6 +2,8 @@ package main
 
 import (
 	"aigpsservice/internal/config"
+	"aigpsservice/internal/domain"
+	"aigpsservice/internal/repository"
 	"aigpsservice/internal/router"
 	"aigpsservice/internal/service"
 	"aigpsservice/pkg/database"
@@ -25,16 +27,27 @@ func main() {
 	}
 	defer db.Close()
 
-	handler := router.SetupRouter(cfg, db)
+	err = service.RunMigrations(cfg, db)
+	if err != nil {
+		logger.Error.Fatal("Could not run migrations: %w", err)
+	}
+
+	authService := service.NewAuthService(repository.NewAuthRepository(db), cfg.JWTSecret)
+	if cfg.AdminAPIKey != "" {
+		if _, err := authService.EnsureAPIKey(context.Background(), "bootstrap admin", cfg.AdminAPIKey, domain.RoleAdmin); err != nil {
+			logger.Error.Fatalf("Failed to store the admin api key: %v", err)
+		}
+	}
+
+	jobService := service.NewJobService(repository.NewJobRepository(db), cfg.JobWorkers)
+
+	handler := router.SetupRouter(cfg, db, jobService, authService)
 	server := &http.Server{
 		Addr:    ":" + cfg.ServerPort,
 		Handler: handler,
 	}
 
-	err = service.RunMigrations(cfg, db)
-	if err != nil {
-		logger.Error.Fatal("Could not run migrations: %w", err)
-	}
+	jobService.Start()
 
 	go func() {
 		logger.Info.Printf("Server starting on port %s", cfg.ServerPort)
@@ -56,5 +69,9 @@ func main() {
 		logger.Error.Fatalf("Server forced to shutdown: %v", err)
 	}
 
+	if err := jobService.Shutdown(ctx); err != nil {
+		logger.Error.Printf("Job workers forced to stop: %v", err)
+	}
+
 	logger.Info.Println("Server exited")
 }
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"aigpsservice/internal/config"
+	"aigpsservice/internal/domain"
+	"aigpsservice/internal/repository"
+	"aigpsservice/internal/service"
+	"aigpsservice/pkg/database"
+	"aigpsservice/pkg/logger"
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+)
+
+// Imports POIs from a GeoJSON FeatureCollection:
+//
+//	go run ./cmd/import -geojson places.geojson -media media.zip -dry-run
+//
+// -media is either a directory or a zip archive with the files referenced
+// by the features. The import report is printed to stdout as JSON.
+func main() {
+	logger.Init()
+
+	geoJSONPath := flag.String("geojson", "", "path to the GeoJSON FeatureCollection")
+	mediaPath := flag.String("media", "", "directory or zip archive with the referenced media files")
+	dryRun := flag.Bool("dry-run", false, "only validate the features, save nothing")
+	flag.Parse()
+
+	if *geoJSONPath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg := config.Load()
+
+	db, err := database.NewPostgresDB(cfg)
+	if err != nil {
+		logger.Error.Fatal("Failed to connect to database:", err)
+	}
+	defer db.Close()
+
+	fileStorage, err := service.NewS3FileStorage(*cfg)
+	if err != nil {
+		logger.Error.Fatalf("Error init s3 file Storage: %v", err)
+	}
+
+	interestService := service.NewInterestService(repository.NewInterestRepository(db))
+	// The import never searches nearby, so ranking is left unweighted.
+	poiService := service.NewPOIService(
+		repository.NewPOIRepository(db), fileStorage, interestService,
+		repository.NewPlaybackRepository(db), domain.RankingWeights{},
+	)
+
+	geoJSONFile, err := os.Open(*geoJSONPath)
+	if err != nil {
+		logger.Error.Fatalf("Failed to open GeoJSON file: %v", err)
+	}
+	defer geoJSONFile.Close()
+
+	var media service.MediaSource
+	if *mediaPath != "" {
+		media, err = openMediaSource(*mediaPath)
+		if err != nil {
+			logger.Error.Fatalf("Failed to open media: %v", err)
+		}
+	}
+
+	// An interrupt stops the import between features, the POI being created
+	// has its uploaded files removed.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	report, err := poiService.ImportPOIs(ctx, geoJSONFile, media, *dryRun)
+	if err != nil {
+		logger.Error.Fatalf("Import failed: %v", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(report)
+
+	logger.Info.Printf("Import finished: total=%d created=%d failed=%d dry_run=%t",
+		report.Total, report.Created, report.Failed, report.DryRun)
+
+	if report.Failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func openMediaSource(path string) (service.MediaSource, error) {
+	if strings.ToLower(filepath.Ext(path)) != ".zip" {
+		return service.NewDirMediaSource(path), nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	// The file stays open until the process exits.
+	return service.NewZipMediaSource(file, info.Size())
+}
@@ -15,93 +15,52 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/poi/create": {
-            "post": {
-                "description": "Создает новую точку интереса с изображением и аудиофайлами",
-                "consumes": [
-                    "multipart/form-data"
+        "/api/admin/stats/pois": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает точки с наибольшим числом запусков за период: число запусков, дослушиваний, пропусков\nи долю дослушанных запусков. Только для admin",
+                "produces": [
+                    "application/json"
                 ],
                 "tags": [
-                    "POI"
+                    "Analytics"
                 ],
-                "summary": "Создание новой точки интереса",
+                "summary": "Статистика прослушиваний по точкам",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Название точки интереса",
-                        "name": "name",
-                        "in": "formData",
-                        "required": true
+                        "example": "2025-06-01T00:00:00Z",
+                        "description": "Начало периода (RFC3339)",
+                        "name": "from",
+                        "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Описание точки интереса",
-                        "name": "description",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "number",
-                        "description": "Широта",
-                        "name": "latitude",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "number",
-                        "description": "Долгота",
-                        "name": "longitude",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "array",
-                        "items": {
-                            "enum": [
-                                "nature",
-                                "architecture",
-                                "food",
-                                "history"
-                            ],
-                            "type": "string"
-                        },
-                        "collectionFormat": "multi",
-                        "description": "Интересы точки",
-                        "name": "interests",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "file",
-                        "description": "Изображение точки интереса",
-                        "name": "image",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "file",
-                        "description": "Короткое аудио",
-                        "name": "short_audio",
-                        "in": "formData",
-                        "required": true
+                        "example": "2025-07-01T00:00:00Z",
+                        "description": "Конец периода, не включая (RFC3339)",
+                        "name": "to",
+                        "in": "query"
                     },
                     {
-                        "type": "array",
-                        "items": {
-                            "type": "file"
-                        },
-                        "collectionFormat": "csv",
-                        "description": "Полные аудио файлы",
-                        "name": "full_audio",
-                        "in": "formData",
-                        "required": true
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Количество точек (от 1 до 500)",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.PointOfInterest"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.POIPlaybackSummary"
+                            }
                         }
                     },
                     "400": {
@@ -110,89 +69,64 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.Response"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/handler.Response"
                         }
-                    }
-                }
-            }
-        },
-        "/api/poi/delete": {
-            "delete": {
-                "description": "Удаляет точку интереса со всеми связанными файлами",
-                "tags": [
-                    "POI"
-                ],
-                "summary": "Удаление точки интереса по id",
-                "parameters": [
-                    {
-                        "type": "number",
-                        "example": 195,
-                        "description": "Id точки интереса",
-                        "name": "id",
-                        "in": "query",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "Успешное выполнение",
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "type": "boolean"
+                            "$ref": "#/definitions/handler.Response"
                         }
                     }
                 }
             }
         },
-        "/api/poi/nearby": {
+        "/api/admin/stats/pois/{id}": {
             "get": {
-                "description": "Возвращает ближайшие точки интереса по координатам",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает для точки за период долю дослушанных запусков в целом и по каждому аудио,\nгистограмму позиций пропуска с шагом bucket_seconds и число запусков по дням (UTC). Только для admin",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "POI"
+                    "Analytics"
                 ],
-                "summary": "Поиск ближайшей точки интереса",
+                "summary": "Статистика прослушиваний точки",
                 "parameters": [
                     {
-                        "type": "number",
-                        "example": 55.7558,
-                        "description": "Широта",
-                        "name": "latitude",
-                        "in": "query",
+                        "type": "integer",
+                        "example": 195,
+                        "description": "Id точки интереса",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     },
                     {
-                        "type": "number",
-                        "example": 37.6173,
-                        "description": "Долгота",
-                        "name": "longitude",
-                        "in": "query",
-                        "required": true
+                        "type": "string",
+                        "example": "2025-06-01T00:00:00Z",
+                        "description": "Начало периода (RFC3339)",
+                        "name": "from",
+                        "in": "query"
                     },
                     {
-                        "type": "number",
-                        "default": 150,
-                        "example": 100,
-                        "description": "Радиус в метрах",
-                        "name": "radius",
+                        "type": "string",
+                        "example": "2025-07-01T00:00:00Z",
+                        "description": "Конец периода, не включая (RFC3339)",
+                        "name": "to",
                         "in": "query"
                     },
                     {
-                        "type": "array",
-                        "items": {
-                            "enum": [
-                                "nature",
-                                "architecture",
-                                "food",
-                                "history"
-                            ],
-                            "type": "string"
-                        },
-                        "collectionFormat": "multi",
-                        "example": "[\"nature\", \"architecture\"]",
-                        "description": "Интересы точки",
-                        "name": "interests",
+                        "type": "number",
+                        "default": 10,
+                        "description": "Ширина интервала гистограммы пропусков в секундах (от 1 до 3600)",
+                        "name": "bucket_seconds",
                         "in": "query"
                     }
                 ],
@@ -200,148 +134,3142 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.PointOfInterest"
+                            "$ref": "#/definitions/domain.POIPlaybackStats"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
                         }
                     }
                 }
             }
         },
-        "/health": {
+        "/api/auth/keys": {
             "get": {
-                "description": "Проверяет доступность сервиса",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает все ключи, включая отозванные, без секретов. Только для admin",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "Health"
+                    "Auth"
                 ],
-                "summary": "Проверка здоровья сервиса",
+                "summary": "Список API ключей",
                 "responses": {
                     "200": {
-                        "description": "Успешный ответ",
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.APIKey"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/handler.Response"
                         }
                     }
                 }
-            }
-        },
-        "/s3/files/{path}": {
-            "get": {
-                "description": "Скачивает файл из S3 хранилища по указанному пути",
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Выдает ключ с ролью listener, editor или admin. Секрет возвращается только в этом ответе\nи передается в заголовке Authorization: Bearer \u003csecret\u003e. Только для admin",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "S3"
+                    "Auth"
                 ],
-                "summary": "Получить файл из S3",
+                "summary": "Создание API ключа",
                 "parameters": [
                     {
-                        "type": "string",
-                        "example": "\"images/photo.jpg\"",
-                        "description": "Путь к файлу в S3",
-                        "name": "path",
-                        "in": "path",
-                        "required": true
+                        "description": "Название и роль ключа",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreateAPIKeyRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Файл",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "type": "file"
-                        },
-                        "headers": {
-                            "Content-Length": {
-                                "type": "string",
-                                "description": "Размер файла в байтах"
-                            },
-                            "Content-Type": {
-                                "type": "string",
-                                "description": "MIME-тип файла"
-                            }
+                            "$ref": "#/definitions/domain.NewAPIKey"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
                         }
                     }
                 }
             }
         },
-        "/s3/health": {
-            "get": {
-                "description": "Проверяет соединение с S3 хранилищем",
-                "tags": [
-                    "S3"
+        "/api/auth/keys/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
-                "summary": "Проверка соединения с S3 хранилищем",
-                "responses": {}
-            }
-        },
-        "/s3/list": {
-            "get": {
-                "description": "Возвращает список файлов в S3 бакете с возможностью фильтрации по префиксу",
+                "description": "Отключает ключ навсегда. Только для admin",
                 "tags": [
-                    "S3"
+                    "Auth"
                 ],
-                "summary": "Получить список файлов",
+                "summary": "Отзыв API ключа",
                 "parameters": [
                     {
-                        "type": "string",
-                        "example": "\"images/\"",
-                        "description": "Префикс для фильтрации файлов",
-                        "name": "prefix",
-                        "in": "query"
+                        "type": "integer",
+                        "description": "Id ключа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/domain.S3ListResponse"
+                            "$ref": "#/definitions/handler.Response"
                         }
-                    }
-                }
-            }
-        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/events": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Сохраняет пачку событий (до 500) проигрывания аудио: started, completed или skipped с позицией в секундах.\nfile_id - id аудио из files точки. Без occurred_at используется время получения.\nС токеном пользователя события привязываются к нему. Пачка сохраняется целиком или не сохраняется",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Analytics"
+                ],
+                "summary": "Прием событий прослушивания",
+                "parameters": [
+                    {
+                        "description": "События",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.PlaybackEventsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/interests": {
+            "get": {
+                "description": "Возвращает все интересы с локализованными названиями, иконками и родителями.\nПоиск точек по родительскому интересу находит и точки с дочерними интересами",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Interests"
+                ],
+                "summary": "Список интересов",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.InterestType"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создает интерес. Id из строчных латинских букв, цифр и подчеркиваний,\nnames - названия по кодам языков, parent_id - необязательный родительский интерес",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Interests"
+                ],
+                "summary": "Создание интереса",
+                "parameters": [
+                    {
+                        "description": "Интерес",
+                        "name": "interest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.InterestType"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.InterestType"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/interests/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Interests"
+                ],
+                "summary": "Получение интереса по id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "history",
+                        "description": "Id интереса",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.InterestType"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет интерес и снимает его с точек. Интерес с дочерними интересами удалить нельзя",
+                "tags": [
+                    "Interests"
+                ],
+                "summary": "Удаление интереса",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "military",
+                        "description": "Id интереса",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Изменяет только переданные поля. names заменяются целиком,\nпустой parent_id делает интерес корневым, пустой icon удаляет иконку",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Interests"
+                ],
+                "summary": "Частичное изменение интереса",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "military",
+                        "description": "Id интереса",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Изменения",
+                        "name": "update",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.InterestTypeUpdate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.InterestType"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/jobs/{id}": {
+            "get": {
+                "description": "Возвращает статус, число попыток, последнюю ошибку и результат фоновой задачи",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Jobs"
+                ],
+                "summary": "Статус фоновой задачи",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "example": 42,
+                        "description": "Id задачи",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/poi": {
+            "get": {
+                "description": "Возвращает каталог точек интереса от новых к старым с постраничной навигацией по курсору",
+                "tags": [
+                    "POI"
+                ],
+                "summary": "Список точек интереса",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Интересы точки (id из /api/interests)",
+                        "name": "interests",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2025-01-01T00:00:00Z",
+                        "description": "Создана не раньше (RFC3339)",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2025-12-31T23:59:59Z",
+                        "description": "Создана не позже (RFC3339)",
+                        "name": "created_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "плотинка",
+                        "description": "Подстрока названия",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Курсор следующей страницы из next_cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Размер страницы (от 1 до 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.POIPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/poi/bbox": {
+            "get": {
+                "description": "Возвращает точки интереса внутри видимой области карты, от новых к старым.\ntruncated = true, если точек больше, чем limit",
+                "tags": [
+                    "POI"
+                ],
+                "summary": "Точки интереса в прямоугольнике карты",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "60.5,56.8,60.7,56.9",
+                        "description": "Ограничивающий прямоугольник minLon,minLat,maxLon,maxLat",
+                        "name": "bbox",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Интересы точки (id из /api/interests)",
+                        "name": "interests",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 500,
+                        "description": "Максимум точек (от 1 до 2000)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.AreaResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/poi/clusters": {
+            "get": {
+                "description": "Группирует точки интереса видимой области карты в кластеры по сетке, зависящей от масштаба:\nцентр, число точек, число точек по интересам и границы кластера. Для кластера из одной точки\nвозвращается poi_id. Начиная с масштаба 16 возвращаются сами точки в pois",
+                "tags": [
+                    "POI"
+                ],
+                "summary": "Кластеры точек интереса для карты",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "60.5,56.8,60.7,56.9",
+                        "description": "Ограничивающий прямоугольник minLon,minLat,maxLon,maxLat",
+                        "name": "bbox",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "example": 12,
+                        "description": "Масштаб карты (от 0 до 22)",
+                        "name": "zoom",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Интересы точки (id из /api/interests)",
+                        "name": "interests",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ClusterResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/poi/create": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создает новую точку интереса с изображением и аудиофайлами.\nФайлы передаются в S3 потоком по мере чтения формы, изображение до 10 МБ, аудио до 50 МБ, вся форма до 1 ГБ и 256 полей.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "tags": [
+                    "POI"
+                ],
+                "summary": "Создание новой точки интереса",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Название точки интереса",
+                        "name": "name",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Описание точки интереса",
+                        "name": "description",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "Широта",
+                        "name": "latitude",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "Долгота",
+                        "name": "longitude",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Интересы точки (id из /api/interests)",
+                        "name": "interests",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "Радиус срабатывания в метрах (до 5000)",
+                        "name": "trigger_radius",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Область срабатывания, GeoJSON Polygon или MultiPolygon без самопересечений, не дальше 5000 м от точки",
+                        "name": "geofence",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "file",
+                        "description": "Изображение точки интереса",
+                        "name": "image",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Короткое аудио",
+                        "name": "short_audio",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "file"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Полные аудио файлы",
+                        "name": "full_audio",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "en",
+                        "description": "Язык аудио (ISO 639), по умолчанию ru",
+                        "name": "language",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.PointOfInterest"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/poi/delete": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет точку интереса со всеми связанными файлами",
+                "tags": [
+                    "POI"
+                ],
+                "summary": "Удаление точки интереса по id",
+                "parameters": [
+                    {
+                        "type": "number",
+                        "example": 195,
+                        "description": "Id точки интереса",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успешное выполнение",
+                        "schema": {
+                            "type": "boolean"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/poi/export": {
+            "get": {
+                "description": "Потоково выгружает все точки интереса с интересами и публичными ссылками на файлы\nв формате GeoJSON, GPX (waypoints) или KML",
+                "tags": [
+                    "POI"
+                ],
+                "summary": "Выгрузка каталога точек интереса",
+                "parameters": [
+                    {
+                        "enum": [
+                            "geojson",
+                            "gpx",
+                            "kml"
+                        ],
+                        "type": "string",
+                        "default": "geojson",
+                        "description": "Формат выгрузки",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Интересы точки (id из /api/interests)",
+                        "name": "interests",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "60.5,56.8,60.7,56.9",
+                        "description": "Ограничивающий прямоугольник minLon,minLat,maxLon,maxLat",
+                        "name": "bbox",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Файл выгрузки",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/poi/import": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создает точки интереса из FeatureCollection. Свойства объекта: name, description, interests,\nimage, short_audio, full_audio, где файлы указываются по имени внутри zip архива media.\nКаждая точка сохраняется отдельно, ошибки по точкам возвращаются в отчете.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "tags": [
+                    "POI"
+                ],
+                "summary": "Массовый импорт точек интереса из GeoJSON",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "GeoJSON FeatureCollection",
+                        "name": "geojson",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Zip архив с изображениями и аудио",
+                        "name": "media",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Только проверить, ничего не сохраняя",
+                        "name": "dry_run",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ImportReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/poi/nearby": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает точку интереса с наибольшей оценкой score среди до 50 ближайших точек, в область\nсрабатывания которых попадают координаты. Это не обязательно самая близкая точка.\nОбластью срабатывания служит geofence точки, а для точек без него круг радиуса radius.\nЕсли передан limit, возвращает список из limit ближайших точек (с учетом offset).\nЕсли передано направление движения, ищет только точки впереди, с упреждением по скорости\nна время короткого аудио.\nС токеном пользователя без параметра interests используются интересы из его профиля.\nТочки упорядочены по оценке score: близость, совпадение с весами интересов, популярность\nпо прослушиваниям, штраф за недавно прослушанные пользователем точки и бонус за новые.\nЧасти оценки возвращаются в score для отладки.\nЯзык названия, описания и аудио выбирается по параметру lang или заголовку Accept-Language,\nа без них по языку профиля. Если перевода или аудио на нужном языке нет, используется русский.\nПользователю с narration_length = short полные аудио не возвращаются, если у точки есть короткое",
+                "tags": [
+                    "POI"
+                ],
+                "summary": "Поиск ближайшей точки интереса",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "en",
+                        "description": "Предпочитаемые языки через запятую (ISO 639)",
+                        "name": "lang",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "en-GB",
+                        "description": "Предпочитаемые языки, если не передан lang",
+                        "name": "Accept-Language",
+                        "in": "header"
+                    },
+                    {
+                        "type": "number",
+                        "example": 55.7558,
+                        "description": "Широта",
+                        "name": "latitude",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "example": 37.6173,
+                        "description": "Долгота",
+                        "name": "longitude",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "default": 500,
+                        "example": 100,
+                        "description": "Радиус срабатывания в метрах для точек без своего geofence",
+                        "name": "radius",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "example": "[\"nature\", \"architecture\"]",
+                        "description": "Интересы точки (id из /api/interests)",
+                        "name": "interests",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "example": 5,
+                        "description": "Количество ближайших точек (от 1 до 50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "example": 0,
+                        "description": "Сколько ближайших точек пропустить",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "example": 90,
+                        "description": "Направление движения в градусах по часовой стрелке от севера",
+                        "name": "heading",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "example": 16.7,
+                        "description": "Скорость движения в м/с",
+                        "name": "speed",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.PointOfInterest"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/poi/polygon": {
+            "post": {
+                "description": "Возвращает точки интереса внутри GeoJSON Polygon или MultiPolygon, от новых к старым.\ntruncated = true, если точек больше, чем limit (по умолчанию 500, максимум 2000)",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "POI"
+                ],
+                "summary": "Точки интереса внутри полигона",
+                "parameters": [
+                    {
+                        "description": "Полигон и фильтры",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.PolygonQueryRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.AreaResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/poi/route": {
+            "post": {
+                "description": "Возвращает точки интереса не дальше width метров (до 5000) от маршрута в порядке их прохождения.\nМаршрут передается либо в polyline (encoded polyline, 5 знаков после запятой), либо в line (GeoJSON LineString).\nУ каждой точки along_track_meters - расстояние от начала маршрута, distance_meters - расстояние до маршрута.\ntruncated = true, если точек больше, чем limit (по умолчанию 500, максимум 2000)",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "POI"
+                ],
+                "summary": "Точки интереса вдоль маршрута",
+                "parameters": [
+                    {
+                        "description": "Маршрут и фильтры",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.RouteQueryRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.AreaResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/poi/{id}": {
+            "get": {
+                "description": "Возвращает точку интереса со всеми файлами. Язык названия, описания и аудио выбирается\nпо параметру lang или заголовку Accept-Language. Если перевода или аудио на нужном языке нет,\nиспользуется русский",
+                "tags": [
+                    "POI"
+                ],
+                "summary": "Получение точки интереса по id",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "example": 195,
+                        "description": "Id точки интереса",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "en",
+                        "description": "Предпочитаемые языки через запятую (ISO 639)",
+                        "name": "lang",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "en-GB",
+                        "description": "Предпочитаемые языки, если не передан lang",
+                        "name": "Accept-Language",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.PointOfInterest"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Изменяет только переданные поля точки интереса. Новые изображение и короткое аудио заменяют старые,\nновые полные аудио добавляются в конец. Старые файлы удаляются из S3 после сохранения изменений.\nФайлы передаются в S3 потоком по мере чтения формы, изображение до 10 МБ, аудио до 50 МБ, вся форма до 1 ГБ и 256 полей.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "tags": [
+                    "POI"
+                ],
+                "summary": "Частичное изменение точки интереса",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "example": 195,
+                        "description": "Id точки интереса",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Название точки интереса",
+                        "name": "name",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Описание точки интереса",
+                        "name": "description",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Широта (вместе с долготой)",
+                        "name": "latitude",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Долгота (вместе с широтой)",
+                        "name": "longitude",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Новый набор интересов точки (id из /api/interests)",
+                        "name": "interests",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Новый радиус срабатывания в метрах (до 5000), пустое значение сбрасывает область срабатывания",
+                        "name": "trigger_radius",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Новая область срабатывания, GeoJSON Polygon или MultiPolygon без самопересечений, не дальше 5000 м от точки, пустое значение сбрасывает ее",
+                        "name": "geofence",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "file",
+                        "description": "Новое изображение",
+                        "name": "image",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "file",
+                        "description": "Новое короткое аудио",
+                        "name": "short_audio",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "file"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Полные аудио файлы, добавляемые в конец",
+                        "name": "full_audio",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Id удаляемых полных аудио файлов",
+                        "name": "remove_full_audio",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Id всех оставшихся полных аудио файлов в новом порядке",
+                        "name": "full_audio_order",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "example": "en",
+                        "description": "Язык изменяемых аудио (ISO 639), по умолчанию ru. Аудио других языков не затрагиваются",
+                        "name": "audio_language",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.PointOfInterest"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/poi/{id}/narrate": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Ставит в очередь фоновую задачу: рассказ по описанию точки генерируется через LLM, озвучивается\nчерез TTS и сохраняется как короткое и полные аудио точки на языке language, заменяя прежние\nаудио этого языка. Для языка, отличного от русского, используется перевод точки, если он есть.\nСтатус задачи доступен по /api/jobs/{id}",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "POI"
+                ],
+                "summary": "Генерация озвучки точки интереса",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "example": 195,
+                        "description": "Id точки интереса",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Параметры озвучки",
+                        "name": "options",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/domain.NarrationOptions"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/poi/{id}/translations": {
+            "get": {
+                "description": "Возвращает названия и описания точки на других языках, кроме русского",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "POI"
+                ],
+                "summary": "Переводы точки интереса",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "example": 195,
+                        "description": "Id точки интереса",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.POITranslation"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/poi/{id}/translations/{lang}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Сохраняет название и описание точки на языке lang. Русские название и описание хранятся в самой точке",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "POI"
+                ],
+                "summary": "Создание или замена перевода точки интереса",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "example": 195,
+                        "description": "Id точки интереса",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "en",
+                        "description": "Язык (ISO 639)",
+                        "name": "lang",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Название и описание",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.TranslationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.POITranslation"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет название и описание точки на языке lang. Аудио на этом языке не удаляются",
+                "tags": [
+                    "POI"
+                ],
+                "summary": "Удаление перевода точки интереса",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "example": 195,
+                        "description": "Id точки интереса",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "en",
+                        "description": "Язык (ISO 639)",
+                        "name": "lang",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/sessions": {
+            "post": {
+                "description": "Создает сессию, в которой сервер запоминает прослушанные точки и аудио.\nradius - радиус срабатывания точек без своего geofence, по умолчанию 500 м (до 5000),\ncooldown_seconds - пауза после истории, по умолчанию 30 с, -1 без паузы",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Начало сессии прослушивания",
+                "parameters": [
+                    {
+                        "description": "Параметры сессии",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.StartSessionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ListeningSession"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/sessions/{id}": {
+            "get": {
+                "description": "Возвращает сессию с последним местоположением и id прослушанных точек",
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Получение сессии прослушивания",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id сессии",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ListeningSession"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/sessions/{id}/location": {
+            "post": {
+                "description": "Запоминает местоположение и возвращает ближайшую непрослушанную точку, в geofence которой\nнаходится слушатель, с еще не проигранными аудио.\nПока идет пауза после истории, точка не возвращается, а cooldown_seconds показывает оставшееся время.\nС направлением и скоростью точки ищутся впереди, как в /api/poi/nearby.\nПользователю с narration_length = short полные аудио не возвращаются, если у точки есть короткое",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Обновление местоположения и следующая точка",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id сессии",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Местоположение",
+                        "name": "location",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.SessionLocation"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.SessionNext"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/sessions/{id}/plays": {
+            "post": {
+                "description": "Запоминает проигранные аудио точки и запускает паузу перед следующей историей.\nТочка считается прослушанной, когда проиграны все ее аудио, при completed = true или без file_ids",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Отметка прослушанного аудио",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id сессии",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Прослушанные аудио",
+                        "name": "play",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.SessionPlay"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/stream": {
+            "get": {
+                "description": "Открывает WebSocket, в который клиент отправляет {\"type\":\"location\",\"latitude\":..,\"longitude\":..}.\nСервер отвечает событиями entered при входе в geofence точки (для точек без него - ближе 50 м),\napproaching ближе 200 м к этой области и left при удалении от нее больше чем на 10 м после entered.\nПервым приходит {\"type\":\"ready\",\"resume_token\":..}, затем heartbeat каждые 20 с. Клиент должен отправлять\nсообщения (location или ping) хотя бы раз в 90 с. После обрыва переподключение с resume_token\nв течение 5 минут продолжает поток без повторных событий. Число соединений одного пользователя или API ключа, для анонимных клиентов - одного адреса, ограничено",
+                "tags": [
+                    "Stream"
+                ],
+                "summary": "Поток событий точек интереса (WebSocket)",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Интересы точки (id из /api/interests)",
+                        "name": "interests",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Токен продолжения потока",
+                        "name": "resume_token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols",
+                        "schema": {
+                            "$ref": "#/definitions/domain.POIEvent"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/tours": {
+            "get": {
+                "description": "Возвращает туры от новых к старым без подробностей о точках, с постраничной навигацией по курсору",
+                "tags": [
+                    "Tours"
+                ],
+                "summary": "Список туров",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Курсор следующей страницы из next_cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Размер страницы (от 1 до 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.TourPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создает тур из точек интереса в порядке poi_ids. Обложка и аудио вступления и завершения необязательны,\nфайлы передаются в S3 потоком по мере чтения формы, изображение до 10 МБ, аудио до 50 МБ, вся форма до 1 ГБ и 256 полей.\nРасстояние считается по прямым между точками, длительность - по скорости пешехода 4.5 км/ч",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "tags": [
+                    "Tours"
+                ],
+                "summary": "Создание тура",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Название тура",
+                        "name": "name",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Описание тура",
+                        "name": "description",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Id точек интереса в порядке прохождения",
+                        "name": "poi_ids",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Обложка",
+                        "name": "cover_image",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "file",
+                        "description": "Аудио вступления",
+                        "name": "intro_audio",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "file",
+                        "description": "Аудио завершения",
+                        "name": "outro_audio",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Tour"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/tours/plan": {
+            "get": {
+                "description": "Подбирает точки интереса по интересам вокруг стартовой точки и упорядочивает их\n(ближайший сосед и 2-opt), чтобы ходьба и прослушивание историй уложились в budget минут.\nПешее расстояние оценивается по прямой с коэффициентом 1.3, скорость 4.5 км/ч,\nдлительность аудио - по размеру файлов. format=gpx возвращает точки и маршрут в GPX",
+                "tags": [
+                    "Tours"
+                ],
+                "summary": "Автоматический пеший тур",
+                "parameters": [
+                    {
+                        "type": "number",
+                        "example": 56.8389,
+                        "description": "Широта старта",
+                        "name": "latitude",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "example": 60.6057,
+                        "description": "Долгота старта",
+                        "name": "longitude",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "example": 90,
+                        "description": "Время на тур в минутах (от 10 до 480)",
+                        "name": "budget",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Интересы точки (id из /api/interests)",
+                        "name": "interests",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Вернуться в точку старта",
+                        "name": "round_trip",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "gpx"
+                        ],
+                        "type": "string",
+                        "default": "json",
+                        "description": "Формат ответа",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.TourPlan"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/tours/{id}": {
+            "get": {
+                "description": "Возвращает тур с точками интереса в порядке прохождения",
+                "tags": [
+                    "Tours"
+                ],
+                "summary": "Получение тура по id",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "example": 1,
+                        "description": "Id тура",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Tour"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/tours/{id}/pois": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Заменяет последовательность точек тура. Так можно переставить, добавить или убрать точки",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tours"
+                ],
+                "summary": "Изменение порядка точек тура",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "example": 1,
+                        "description": "Id тура",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Id точек интереса в порядке прохождения",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.TourPOIsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Tour"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/login": {
+            "post": {
+                "description": "Проверяет почту и пароль и возвращает новый токен",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Вход пользователя",
+                "parameters": [
+                    {
+                        "description": "Почта и пароль",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.LoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.AuthToken"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/me": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает пользователя токена вместе с профилем",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Текущий пользователь",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.User"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/me/profile": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Заменяет профиль пользователя токена целиком. Интересы профиля используются в /api/poi/nearby,\nесли параметр interests не передан. narration_length = short оставляет в /api/poi/nearby\nи сессиях только короткое аудио, voice только сохраняется и пока не влияет на аудио",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Изменение профиля",
+                "parameters": [
+                    {
+                        "description": "Профиль",
+                        "name": "profile",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.UserProfile"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/register": {
+            "post": {
+                "description": "Создает пользователя с профилем и возвращает токен для заголовка Authorization: Bearer \u003ctoken\u003e.\nВес интереса от 0 до 1, по умолчанию 1. narration_length - short или full, по умолчанию full,\nlanguage по умолчанию ru. voice только сохраняется в профиле и пока не влияет на аудио",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Регистрация пользователя",
+                "parameters": [
+                    {
+                        "description": "Почта, пароль и профиль",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.RegisterRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.AuthToken"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "Проверяет доступность сервиса",
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Проверка здоровья сервиса",
+                "responses": {
+                    "200": {
+                        "description": "Успешный ответ",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/s3/files/{path}": {
+            "get": {
+                "description": "Скачивает файл из S3 хранилища по указанному пути.\nПоддерживает запросы диапазонов байт (Range, If-Range), в том числе нескольких диапазонов\n(multipart/byteranges), и условные запросы по ETag и Last-Modified (If-None-Match, If-Modified-Since)",
+                "tags": [
+                    "S3"
+                ],
+                "summary": "Получить файл из S3",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"images/photo.jpg\"",
+                        "description": "Путь к файлу в S3",
+                        "name": "path",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "bytes=0-1023",
+                        "description": "Диапазон байт",
+                        "name": "Range",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag имеющейся у клиента версии файла",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата имеющейся у клиента версии файла",
+                        "name": "If-Modified-Since",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Файл",
+                        "schema": {
+                            "type": "file"
+                        },
+                        "headers": {
+                            "Content-Length": {
+                                "type": "string",
+                                "description": "Размер файла в байтах"
+                            },
+                            "Content-Type": {
+                                "type": "string",
+                                "description": "MIME-тип файла"
+                            },
+                            "ETag": {
+                                "type": "string",
+                                "description": "ETag файла"
+                            }
+                        }
+                    },
+                    "206": {
+                        "description": "Запрошенный диапазон файла",
+                        "schema": {
+                            "type": "file"
+                        },
+                        "headers": {
+                            "Content-Range": {
+                                "type": "string",
+                                "description": "Отданный диапазон"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Файл не изменился"
+                    },
+                    "404": {
+                        "description": "Файл не найден"
+                    },
+                    "416": {
+                        "description": "Диапазон вне файла"
+                    }
+                }
+            }
+        },
+        "/s3/health": {
+            "get": {
+                "description": "Проверяет соединение с S3 хранилищем",
+                "tags": [
+                    "S3"
+                ],
+                "summary": "Проверка соединения с S3 хранилищем",
+                "responses": {}
+            }
+        },
+        "/s3/list": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает список файлов в S3 бакете с возможностью фильтрации по префиксу",
+                "tags": [
+                    "S3"
+                ],
+                "summary": "Получить список файлов",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"images/\"",
+                        "description": "Префикс для фильтрации файлов",
+                        "name": "prefix",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.S3ListResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tiles/poi/{z}/{x}/{y}": {
+            "get": {
+                "description": "Возвращает тайл Mapbox Vector Tile со слоем poi. Атрибуты объектов: id, name и interests\n(id интересов через запятую). ETag меняется при любом изменении точек или интересов,\nпоэтому после max-age клиент перепроверяет тайл через If-None-Match",
+                "produces": [
+                    "application/vnd.mapbox-vector-tile"
+                ],
+                "tags": [
+                    "Tiles"
+                ],
+                "summary": "Векторный тайл точек интереса",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "example": 12,
+                        "description": "Масштаб (от 0 до 22)",
+                        "name": "z",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "example": 2737,
+                        "description": "Номер тайла по x",
+                        "name": "x",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "1207.mvt",
+                        "description": "Номер тайла по y с расширением .mvt",
+                        "name": "y",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Интересы точки (id из /api/interests)",
+                        "name": "interests",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Тайл",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "304": {
+                        "description": "Тайл не изменился"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Response"
+                        }
+                    }
+                }
+            }
+        }
     },
     "definitions": {
+        "domain.APIKey": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "listener",
+                        "editor",
+                        "admin"
+                    ]
+                }
+            }
+        },
+        "domain.AreaResult": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.PointOfInterest"
+                    }
+                },
+                "truncated": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "domain.AuthToken": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/domain.User"
+                }
+            }
+        },
+        "domain.BoundingBox": {
+            "type": "object",
+            "properties": {
+                "max_latitude": {
+                    "type": "number"
+                },
+                "max_longitude": {
+                    "type": "number"
+                },
+                "min_latitude": {
+                    "type": "number"
+                },
+                "min_longitude": {
+                    "type": "number"
+                }
+            }
+        },
+        "domain.ClusterResult": {
+            "type": "object",
+            "properties": {
+                "clusters": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.POICluster"
+                    }
+                },
+                "pois": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.PointOfInterest"
+                    }
+                },
+                "truncated": {
+                    "type": "boolean"
+                },
+                "zoom": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.DailyPlays": {
+            "type": "object",
+            "properties": {
+                "day": {
+                    "type": "string",
+                    "example": "2025-06-01"
+                },
+                "plays": {
+                    "type": "integer"
+                }
+            }
+        },
         "domain.File": {
             "type": "object",
             "properties": {
                 "created_at": {
                     "type": "string"
                 },
-                "file_name": {
+                "file_name": {
+                    "type": "string"
+                },
+                "file_size": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_short": {
+                    "type": "boolean"
+                },
+                "language": {
+                    "description": "Language is the language of an audio track, empty for images.",
+                    "type": "string"
+                },
+                "mime_type": {
+                    "type": "string"
+                },
+                "s3_key": {
+                    "type": "string"
+                },
+                "serial_number": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.FilePlaybackStats": {
+            "type": "object",
+            "properties": {
+                "completed": {
+                    "type": "integer"
+                },
+                "completion_rate": {
+                    "description": "CompletionRate is the share of starts that were completed, zero\nwithout starts.",
+                    "type": "number"
+                },
+                "file_id": {
+                    "type": "integer"
+                },
+                "skipped": {
+                    "type": "integer"
+                },
+                "started": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.Geofence": {
+            "type": "object",
+            "properties": {
+                "polygon": {
+                    "type": "object"
+                },
+                "radius": {
+                    "type": "number",
+                    "example": 80
+                }
+            }
+        },
+        "domain.ImportReport": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer"
+                },
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.ImportResult"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.ImportResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "poi_id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.InterestType": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "icon": {
+                    "type": "string",
+                    "example": "icons/military.svg"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "military"
+                },
+                "names": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    },
+                    "example": {
+                        "en": "Military history",
+                        "ru": "Военная история"
+                    }
+                },
+                "parent_id": {
+                    "type": "string",
+                    "example": "history"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.InterestTypeUpdate": {
+            "type": "object",
+            "properties": {
+                "icon": {
                     "type": "string"
                 },
-                "file_size": {
+                "names": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "parent_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.InterestWeight": {
+            "type": "object",
+            "properties": {
+                "interest": {
+                    "type": "string",
+                    "example": "history"
+                },
+                "weight": {
+                    "type": "number",
+                    "example": 0.8
+                }
+            }
+        },
+        "domain.Job": {
+            "type": "object",
+            "properties": {
+                "attempts": {
                     "type": "integer"
                 },
+                "created_at": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
-                "is_short": {
-                    "type": "boolean"
+                "kind": {
+                    "type": "string"
                 },
-                "mime_type": {
+                "last_error": {
                     "type": "string"
                 },
-                "s3_key": {
+                "max_attempts": {
+                    "type": "integer"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "result": {
+                    "type": "object"
+                },
+                "run_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "running",
+                        "succeeded",
+                        "failed"
+                    ]
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ListeningSession": {
+            "type": "object",
+            "properties": {
+                "cooldown_seconds": {
+                    "description": "CooldownSeconds is the pause after a story before the next one is offered.",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "heard_poi_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "interests": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "last_played_at": {
+                    "type": "string"
+                },
+                "latitude": {
+                    "type": "number"
+                },
+                "longitude": {
+                    "type": "number"
+                },
+                "radius": {
+                    "description": "Radius is the trigger radius of POIs without their own geofence.",
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.NarrationOptions": {
+            "type": "object",
+            "properties": {
+                "facts": {
+                    "description": "Facts are passed to the LLM in addition to the description.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "language": {
+                    "description": "Language of the narration, the default language if empty. The name\nand description of this language are narrated when the POI has them.",
+                    "type": "string",
+                    "example": "en"
+                },
+                "sample_rate": {
+                    "type": "integer"
+                },
+                "speaker": {
+                    "description": "Speaker is the TTS voice of the language, such as aidar or baya for\nRussian and en_0 for English.",
+                    "type": "string",
+                    "example": "aidar"
+                }
+            }
+        },
+        "domain.NewAPIKey": {
+            "type": "object",
+            "properties": {
+                "key": {
+                    "$ref": "#/definitions/domain.APIKey"
+                },
+                "secret": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.POICluster": {
+            "type": "object",
+            "properties": {
+                "bbox": {
+                    "$ref": "#/definitions/domain.BoundingBox"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "interests": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "latitude": {
+                    "type": "number"
+                },
+                "longitude": {
+                    "type": "number"
+                },
+                "poi_id": {
+                    "description": "POIID is set when the cluster holds a single POI.",
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.POIEvent": {
+            "type": "object",
+            "properties": {
+                "distance_meters": {
+                    "type": "number"
+                },
+                "poi": {
+                    "$ref": "#/definitions/domain.PointOfInterest"
+                },
+                "seq": {
+                    "description": "Seq numbers the events of a stream, it continues after a resume.",
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "approaching",
+                        "entered",
+                        "left"
+                    ]
+                }
+            }
+        },
+        "domain.POIPage": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.PointOfInterest"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.POIPlaybackStats": {
+            "type": "object",
+            "properties": {
+                "completed": {
+                    "type": "integer"
+                },
+                "completion_rate": {
+                    "description": "CompletionRate is the share of starts that were completed, zero\nwithout starts.",
+                    "type": "number"
+                },
+                "daily_plays": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.DailyPlays"
+                    }
+                },
+                "files": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.FilePlaybackStats"
+                    }
+                },
+                "poi_id": {
+                    "type": "integer"
+                },
+                "skip_points": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.SkipBucket"
+                    }
+                },
+                "skipped": {
+                    "type": "integer"
+                },
+                "started": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.POIPlaybackSummary": {
+            "type": "object",
+            "properties": {
+                "completed": {
+                    "type": "integer"
+                },
+                "completion_rate": {
+                    "description": "CompletionRate is the share of starts that were completed, zero\nwithout starts.",
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "poi_id": {
+                    "type": "integer"
+                },
+                "skipped": {
+                    "type": "integer"
+                },
+                "started": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.POIScore": {
+            "type": "object",
+            "properties": {
+                "distance": {
+                    "description": "Distance grows as the POI gets closer, half the weight at the\ntrigger radius of the search.",
+                    "type": "number"
+                },
+                "interest": {
+                    "description": "Interest is the share of the listener's interest weight the POI\nmatches.",
+                    "type": "number"
+                },
+                "last_heard_at": {
                     "type": "string"
                 },
-                "serial_number": {
-                    "type": "integer"
+                "novelty": {
+                    "description": "Novelty is a bonus for a POI the listener has never heard.",
+                    "type": "number"
+                },
+                "plays": {
+                    "type": "integer"
+                },
+                "popularity": {
+                    "description": "Popularity grows with the plays of the POI by all listeners in the\nlast weeks, relative to the most played POI around.",
+                    "type": "number"
+                },
+                "recency": {
+                    "description": "Recency is a penalty for a POI the listener heard lately, it halves\nevery week.",
+                    "type": "number"
+                },
+                "total": {
+                    "type": "number"
+                }
+            }
+        },
+        "domain.POITranslation": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "The dam that gave birth to the city."
+                },
+                "language": {
+                    "type": "string",
+                    "example": "en"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "City Pond Dam"
+                }
+            }
+        },
+        "domain.PlaybackEvent": {
+            "type": "object",
+            "properties": {
+                "event": {
+                    "type": "string",
+                    "enum": [
+                        "started",
+                        "completed",
+                        "skipped"
+                    ],
+                    "example": "skipped"
+                },
+                "file_id": {
+                    "type": "integer",
+                    "example": 301
+                },
+                "occurred_at": {
+                    "description": "OccurredAt is when the event happened on the device, the time of\nreceipt if unset.",
+                    "type": "string",
+                    "example": "2025-06-01T12:00:00Z"
+                },
+                "position_seconds": {
+                    "description": "PositionSeconds is the playback position of the file when the event\nhappened.",
+                    "type": "number",
+                    "example": 42.5
+                },
+                "session_id": {
+                    "type": "string",
+                    "example": "5f0c6a52-8a5e-4c1f-9d4b-2f6a1c0e7b11"
                 }
             }
         },
         "domain.PointOfInterest": {
             "type": "object",
             "properties": {
+                "along_track_meters": {
+                    "description": "AlongTrackMeters is the distance from the start of a route to the\npoint of the route closest to the POI.",
+                    "type": "number"
+                },
+                "bearing": {
+                    "type": "number"
+                },
                 "created_at": {
                     "type": "string"
                 },
                 "description": {
                     "type": "string"
                 },
+                "distance_meters": {
+                    "type": "number"
+                },
                 "full_audio_files": {
                     "type": "array",
                     "items": {
                         "$ref": "#/definitions/domain.File"
                     }
                 },
+                "geofence": {
+                    "description": "Geofence is the own trigger area of the POI, if it has one.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.Geofence"
+                        }
+                    ]
+                },
+                "geofence_meters": {
+                    "description": "GeofenceMeters is the distance from the searched point to the trigger\narea of the POI, zero inside it.",
+                    "type": "number"
+                },
                 "id": {
                     "type": "integer"
                 },
@@ -354,6 +3282,10 @@ const docTemplate = `{
                         "type": "string"
                     }
                 },
+                "language": {
+                    "description": "Language is the language of the name and description.",
+                    "type": "string"
+                },
                 "latitude": {
                     "type": "number"
                 },
@@ -363,6 +3295,14 @@ const docTemplate = `{
                 "name": {
                     "type": "string"
                 },
+                "score": {
+                    "description": "Score explains the rank of the POI in a nearby search.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.POIScore"
+                        }
+                    ]
+                },
                 "short_audio_file": {
                     "$ref": "#/definitions/domain.File"
                 }
@@ -396,6 +3336,341 @@ const docTemplate = `{
                 }
             }
         },
+        "domain.SessionLocation": {
+            "type": "object",
+            "properties": {
+                "heading": {
+                    "type": "number",
+                    "example": 90
+                },
+                "latitude": {
+                    "type": "number",
+                    "example": 56.8389
+                },
+                "longitude": {
+                    "type": "number",
+                    "example": 60.6057
+                },
+                "speed": {
+                    "type": "number",
+                    "example": 1.4
+                }
+            }
+        },
+        "domain.SessionNext": {
+            "type": "object",
+            "properties": {
+                "cooldown_seconds": {
+                    "description": "CooldownSeconds is the time left until the next story may start.",
+                    "type": "integer"
+                },
+                "poi": {
+                    "description": "POI is the nearest POI not heard yet, unset while the cooldown lasts\nor when there is none around.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.PointOfInterest"
+                        }
+                    ]
+                },
+                "remaining_audio": {
+                    "description": "RemainingAudio is the audio of POI not played in the session yet,\nthe short audio first and then the full audio segments in order.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.File"
+                    }
+                }
+            }
+        },
+        "domain.SessionPlay": {
+            "type": "object",
+            "properties": {
+                "completed": {
+                    "description": "Completed marks the POI as heard even if some of its audio was skipped.",
+                    "type": "boolean"
+                },
+                "file_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        301,
+                        302
+                    ]
+                },
+                "poi_id": {
+                    "type": "integer",
+                    "example": 195
+                }
+            }
+        },
+        "domain.SkipBucket": {
+            "type": "object",
+            "properties": {
+                "from_seconds": {
+                    "type": "number"
+                },
+                "skips": {
+                    "type": "integer"
+                },
+                "to_seconds": {
+                    "type": "number"
+                }
+            }
+        },
+        "domain.Tour": {
+            "type": "object",
+            "properties": {
+                "cover_image": {
+                    "$ref": "#/definitions/domain.File"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "distance_meters": {
+                    "description": "DistanceMeters is the length of the straight legs between the stops.",
+                    "type": "number"
+                },
+                "duration_seconds": {
+                    "description": "DurationSeconds is the estimated walking time of the tour.",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "intro_audio": {
+                    "$ref": "#/definitions/domain.File"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "outro_audio": {
+                    "$ref": "#/definitions/domain.File"
+                },
+                "poi_ids": {
+                    "description": "POIIDs are the stops of the tour in walking order.",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "pois": {
+                    "description": "POIs are the stops themselves, filled when a single tour is read.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.PointOfInterest"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.TourPage": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Tour"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.TourPlan": {
+            "type": "object",
+            "properties": {
+                "audio_seconds": {
+                    "type": "integer"
+                },
+                "budget_seconds": {
+                    "type": "integer"
+                },
+                "distance_meters": {
+                    "type": "number"
+                },
+                "duration_seconds": {
+                    "type": "integer"
+                },
+                "latitude": {
+                    "type": "number"
+                },
+                "longitude": {
+                    "type": "number"
+                },
+                "round_trip": {
+                    "type": "boolean"
+                },
+                "stops": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.TourPlanStop"
+                    }
+                },
+                "walking_seconds": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.TourPlanStop": {
+            "type": "object",
+            "properties": {
+                "arrival_seconds": {
+                    "description": "ArrivalSeconds is the time from the start until the stop is reached.",
+                    "type": "integer"
+                },
+                "audio_seconds": {
+                    "description": "AudioSeconds is the estimated length of the stories of the stop.",
+                    "type": "integer"
+                },
+                "leg_meters": {
+                    "description": "LegMeters is the estimated walking distance from the previous stop,\nor from the start for the first one.",
+                    "type": "number"
+                },
+                "poi": {
+                    "$ref": "#/definitions/domain.PointOfInterest"
+                }
+            }
+        },
+        "domain.User": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "profile": {
+                    "$ref": "#/definitions/domain.UserProfile"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.UserProfile": {
+            "type": "object",
+            "properties": {
+                "interests": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.InterestWeight"
+                    }
+                },
+                "language": {
+                    "type": "string",
+                    "example": "ru"
+                },
+                "narration_length": {
+                    "description": "NarrationLength tells whether the user wants only the short audio or\nthe full story.",
+                    "type": "string",
+                    "enum": [
+                        "short",
+                        "full"
+                    ],
+                    "example": "full"
+                },
+                "voice": {
+                    "description": "Voice is the preferred TTS voice, empty for the default one.",
+                    "type": "string",
+                    "enum": [
+                        "aidar",
+                        "baya"
+                    ]
+                }
+            }
+        },
+        "handler.CreateAPIKeyRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "CMS editor"
+                },
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "listener",
+                        "editor",
+                        "admin"
+                    ],
+                    "example": "editor"
+                }
+            }
+        },
+        "handler.LoginRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "listener@example.com"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "correct horse"
+                }
+            }
+        },
+        "handler.PlaybackEventsRequest": {
+            "type": "object",
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.PlaybackEvent"
+                    }
+                }
+            }
+        },
+        "handler.PolygonQueryRequest": {
+            "type": "object",
+            "properties": {
+                "interests": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "history",
+                        "nature"
+                    ]
+                },
+                "limit": {
+                    "type": "integer",
+                    "example": 500
+                },
+                "polygon": {
+                    "description": "Polygon is a GeoJSON Polygon or MultiPolygon geometry.",
+                    "type": "object"
+                }
+            }
+        },
+        "handler.RegisterRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "listener@example.com"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "correct horse"
+                },
+                "profile": {
+                    "$ref": "#/definitions/domain.UserProfile"
+                }
+            }
+        },
         "handler.Response": {
             "type": "object",
             "properties": {
@@ -404,6 +3679,101 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "handler.RouteQueryRequest": {
+            "type": "object",
+            "properties": {
+                "interests": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "history",
+                        "nature"
+                    ]
+                },
+                "limit": {
+                    "type": "integer",
+                    "example": 500
+                },
+                "line": {
+                    "description": "Line is a GeoJSON LineString geometry.",
+                    "type": "object"
+                },
+                "polyline": {
+                    "description": "Polyline is the route in the encoded polyline format with five decimal places.",
+                    "type": "string",
+                    "example": "_p~iF~ps|U_ulLnnqC"
+                },
+                "width": {
+                    "description": "Width is the distance from the route in meters.",
+                    "type": "number",
+                    "example": 300
+                }
+            }
+        },
+        "handler.StartSessionRequest": {
+            "type": "object",
+            "properties": {
+                "cooldown_seconds": {
+                    "description": "CooldownSeconds is the pause after a story, -1 disables it.",
+                    "type": "integer",
+                    "example": 30
+                },
+                "interests": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "history",
+                        "architecture"
+                    ]
+                },
+                "radius": {
+                    "description": "Radius is the trigger radius of POIs without their own geofence.",
+                    "type": "integer",
+                    "example": 500
+                }
+            }
+        },
+        "handler.TourPOIsRequest": {
+            "type": "object",
+            "properties": {
+                "poi_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        195,
+                        12,
+                        48
+                    ]
+                }
+            }
+        },
+        "handler.TranslationRequest": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "The dam that gave birth to the city."
+                },
+                "name": {
+                    "type": "string",
+                    "example": "City Pond Dam"
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "BearerAuth": {
+            "description": "API ключ или токен пользователя в виде \"Bearer \u003csecret\u003e\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
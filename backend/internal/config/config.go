@@ -20,6 +20,32 @@ type Config struct {
 	S3SecretKey string
 	S3UseSSL    bool
 	S3Bucket    string
+
+	// PublicBaseURL is the externally reachable address of the service,
+	// used to build links to files served by the S3 proxy.
+	PublicBaseURL string
+
+	MLBaseURL string
+
+	// JobWorkers is the number of background jobs run concurrently.
+	JobWorkers int
+
+	// StreamMaxConnsPerClient caps the open event streams of one user or
+	// API key, or of one address for anonymous clients.
+	StreamMaxConnsPerClient int
+
+	// AdminAPIKey is stored as an admin API key on startup, so the first
+	// keys can be issued. Empty disables it.
+	AdminAPIKey string
+	// JWTSecret signs user tokens. Empty means a random secret per start.
+	JWTSecret string
+
+	// Rank*Weight weigh the parts of the score that orders nearby POIs.
+	RankDistanceWeight   float64
+	RankInterestWeight   float64
+	RankPopularityWeight float64
+	RankRecencyWeight    float64
+	RankNoveltyWeight    float64
 }
 
 var configInstance *Config
@@ -39,6 +65,23 @@ func Load() *Config {
 			S3SecretKey: getEnv("S3_SECRET_KEY_AIGPSSERVICE", "minioadmin"),
 			S3UseSSL:    getEnvBool("S3_USE_SSL_AIGPSSERVICE", false),
 			S3Bucket:    getEnv("S3_BUCKET_AIGPSSERVICE", "default"),
+
+			PublicBaseURL: getEnv("PUBLIC_BASE_URL_AIGPSSERVICE", "http://localhost:8080"),
+
+			MLBaseURL: getEnv("ML_BASE_URL_AIGPSSERVICE", "http://localhost:8000"),
+
+			JobWorkers: getEnvInt("JOB_WORKERS_AIGPSSERVICE", 2),
+
+			StreamMaxConnsPerClient: getEnvInt("STREAM_MAX_CONNECTIONS_AIGPSSERVICE", 3),
+
+			AdminAPIKey: getEnv("ADMIN_API_KEY_AIGPSSERVICE", ""),
+			JWTSecret:   getEnv("JWT_SECRET_AIGPSSERVICE", ""),
+
+			RankDistanceWeight:   getEnvFloat("RANK_DISTANCE_WEIGHT_AIGPSSERVICE", 1),
+			RankInterestWeight:   getEnvFloat("RANK_INTEREST_WEIGHT_AIGPSSERVICE", 0.5),
+			RankPopularityWeight: getEnvFloat("RANK_POPULARITY_WEIGHT_AIGPSSERVICE", 0.3),
+			RankRecencyWeight:    getEnvFloat("RANK_RECENCY_WEIGHT_AIGPSSERVICE", 1),
+			RankNoveltyWeight:    getEnvFloat("RANK_NOVELTY_WEIGHT_AIGPSSERVICE", 0.2),
 		}
 	})
 	return configInstance
@@ -66,6 +109,24 @@ func getEnvBool(key string, defaultValue bool) bool {
 	return defaultValue
 }
 
+func getEnvInt(key string, defaultValue int) int {
+	if value, exists := os.LookupEnv(key); exists {
+		if intValue, err := strconv.Atoi(value); err == nil {
+			return intValue
+		}
+	}
+	return defaultValue
+}
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value, exists := os.LookupEnv(key); exists {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
 func (c *Config) GetDBConnectionString() string {
 	return fmt.Sprintf("host=%s port=%s dbname=%s user=%s password=%s sslmode=disable",
 		c.DBHost,
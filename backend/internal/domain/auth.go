@@ -0,0 +1,51 @@
+package domain
+
+import "time"
+
+// Roles ordered by their rights, each role may do what the previous ones may.
+const (
+	RoleListener = "listener"
+	RoleEditor   = "editor"
+	RoleAdmin    = "admin"
+)
+
+var roleRanks = map[string]int{
+	RoleListener: 1,
+	RoleEditor:   2,
+	RoleAdmin:    3,
+}
+
+// IsRole reports whether role is one of the known roles.
+func IsRole(role string) bool {
+	_, ok := roleRanks[role]
+	return ok
+}
+
+// Principal is the authenticated caller of a request, either an API key or
+// a signed-in user.
+type Principal struct {
+	Role     string
+	APIKeyID int64
+	UserID   int64
+}
+
+// HasRole reports whether the principal has at least the rights of role.
+func (p *Principal) HasRole(role string) bool {
+	return p != nil && roleRanks[p.Role] >= roleRanks[role]
+}
+
+// APIKey describes an API key without its secret.
+type APIKey struct {
+	ID        int64      `json:"id"`
+	Name      string     `json:"name"`
+	Prefix    string     `json:"prefix"`
+	Role      string     `json:"role" enums:"listener,editor,admin"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// NewAPIKey is a created API key with its secret, which is not stored.
+type NewAPIKey struct {
+	Key    *APIKey `json:"key"`
+	Secret string  `json:"secret"`
+}
@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+// InterestType is an entry of the interest taxonomy. A POI tagged with a
+// child interest also matches searches by its parent.
+type InterestType struct {
+	ID        string            `json:"id" example:"military"`
+	ParentID  *string           `json:"parent_id,omitempty" example:"history"`
+	Names     map[string]string `json:"names" example:"ru:Военная история,en:Military history"`
+	Icon      string            `json:"icon,omitempty" example:"icons/military.svg"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// InterestTypeUpdate is a partial edit of an interest type. Nil fields are
+// left unchanged; an empty ParentID makes the interest a root.
+type InterestTypeUpdate struct {
+	ParentID *string           `json:"parent_id,omitempty"`
+	Names    map[string]string `json:"names,omitempty"`
+	Icon     *string           `json:"icon,omitempty"`
+}
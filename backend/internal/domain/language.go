@@ -0,0 +1,57 @@
+package domain
+
+// DefaultLanguage is the language of the names, descriptions and audio
+// stored with the POIs themselves, and the fallback for missing
+// translations.
+const DefaultLanguage = "ru"
+
+// POITranslation is the name and description of a POI in a language other
+// than the default one.
+type POITranslation struct {
+	Language    string `json:"language" example:"en"`
+	Name        string `json:"name" example:"City Pond Dam"`
+	Description string `json:"description" example:"The dam that gave birth to the city."`
+}
+
+// SelectAudio sets ShortAudioFile and FullAudioFiles to the audio tracks of
+// the first of languages the POI has audio in, or else of DefaultLanguage.
+func (p *PointOfInterest) SelectAudio(languages ...string) {
+	chosen := DefaultLanguage
+	for _, language := range languages {
+		if p.hasAudio(language) {
+			chosen = language
+			break
+		}
+	}
+
+	p.ShortAudioFile = nil
+	p.FullAudioFiles = []*File{}
+	for _, file := range p.AudioFiles {
+		if file.Language != chosen {
+			continue
+		}
+		if file.IsShort {
+			p.ShortAudioFile = file
+		} else {
+			p.FullAudioFiles = append(p.FullAudioFiles, file)
+		}
+	}
+}
+
+// ApplyNarrationLength drops the full audio chosen by SelectAudio when the
+// listener only wants the short story. A POI without short audio keeps its
+// full audio, so there is still something to play.
+func (p *PointOfInterest) ApplyNarrationLength(length string) {
+	if length == NarrationShort && p.ShortAudioFile != nil {
+		p.FullAudioFiles = []*File{}
+	}
+}
+
+func (p *PointOfInterest) hasAudio(language string) bool {
+	for _, file := range p.AudioFiles {
+		if file.Language == language {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,9 @@
 package domain
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type File struct {
 	ID           int64     `json:"id"`
@@ -11,6 +14,8 @@ type File struct {
 	SerialNumber int64     `json:"serial_number"`
 	IsShort      bool      `json:"is_short"`
 	CreatedAt    time.Time `json:"created_at"`
+	// Language is the language of an audio track, empty for images.
+	Language string `json:"language,omitempty"`
 }
 
 type PointOfInterest struct {
@@ -24,10 +29,200 @@ type PointOfInterest struct {
 	FullAudioFiles []*File   `json:"full_audio_files,omitempty"`
 	ShortAudioFile *File     `json:"short_audio_file,omitempty"`
 	CreatedAt      time.Time `json:"created_at"`
+	DistanceMeters *float64  `json:"distance_meters,omitempty"`
+	Bearing        *float64  `json:"bearing,omitempty"`
+	// AlongTrackMeters is the distance from the start of a route to the
+	// point of the route closest to the POI.
+	AlongTrackMeters *float64 `json:"along_track_meters,omitempty"`
+	// Geofence is the own trigger area of the POI, if it has one.
+	Geofence *Geofence `json:"geofence,omitempty"`
+	// GeofenceMeters is the distance from the searched point to the trigger
+	// area of the POI, zero inside it.
+	GeofenceMeters *float64 `json:"geofence_meters,omitempty"`
+	// Score explains the rank of the POI in a nearby search.
+	Score *POIScore `json:"score,omitempty"`
+	// Language is the language of the name and description.
+	Language string `json:"language,omitempty"`
+	// AudioFiles are the audio tracks in every language. ShortAudioFile
+	// and FullAudioFiles are the ones of the language chosen by SelectAudio.
+	AudioFiles []*File `json:"-"`
 }
 
-type POIRepository interface {
-	FindNearestPOI(latitude, longitude float64, radius int) (*PointOfInterest, error)
+// Geofence is the area where the story of a POI is triggered: a circle of
+// Radius meters around the POI or a GeoJSON Polygon or MultiPolygon.
+// POIs without one are triggered within the radius of the search.
+type Geofence struct {
+	Radius  *float64        `json:"radius,omitempty" example:"80"`
+	Polygon json.RawMessage `json:"polygon,omitempty" swaggertype:"object"`
+}
+
+// POIUpdate is a partial edit of a POI. Nil fields are left unchanged.
+type POIUpdate struct {
+	Name        *string
+	Description *string
+	Latitude    *float64
+	Longitude   *float64
+	Interests   []string
+	// Geofence replaces the trigger area, an empty one resets it to the
+	// radius of the search.
+	Geofence *Geofence
+
+	ImageFile      *File
+	ShortAudioFile *File
+	// NewFullAudioFiles are appended after the existing full audio segments.
+	NewFullAudioFiles []*File
+	// RemoveFullAudioIDs are ids of full audio segments to delete.
+	RemoveFullAudioIDs []int64
+	// FullAudioOrder lists ids of all remaining full audio segments in their
+	// new order. Serial numbers are reassigned from it.
+	FullAudioOrder []int64
+	// AudioLanguage is the language of the audio tracks the update changes,
+	// DefaultLanguage if empty. Audio in other languages is left as is.
+	AudioLanguage string
+}
+
+// POIListFilter narrows down the POI catalog listing. Zero fields match everything.
+type POIListFilter struct {
+	Interests   []string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	// Name matches POIs whose name contains it, case-insensitively.
+	Name string
+}
+
+// POICursor is the position of the last POI of a listing page.
+type POICursor struct {
+	CreatedAt time.Time `json:"created_at"`
+	ID        int64     `json:"id"`
+}
+
+type POIPage struct {
+	Items      []*PointOfInterest `json:"items"`
+	NextCursor string             `json:"next_cursor,omitempty"`
+}
+
+// BoundingBox is a WGS 84 rectangle.
+type BoundingBox struct {
+	MinLongitude float64 `json:"min_longitude"`
+	MinLatitude  float64 `json:"min_latitude"`
+	MaxLongitude float64 `json:"max_longitude"`
+	MaxLatitude  float64 `json:"max_latitude"`
+}
+
+// POIExportFilter narrows down the exported POIs. Zero fields match everything.
+type POIExportFilter struct {
+	Interests   []string
+	BoundingBox *BoundingBox
+}
+
+// AreaQuery selects the POIs inside a map area, given either as a bounding
+// box or as a GeoJSON Polygon or MultiPolygon geometry.
+type AreaQuery struct {
+	BoundingBox *BoundingBox
+	Polygon     json.RawMessage
+	Interests   []string
+	Limit       int
+}
+
+// AreaResult is the POIs found in an area. Truncated is set when more POIs
+// matched than the limit allowed.
+type AreaResult struct {
+	Items     []*PointOfInterest `json:"items"`
+	Truncated bool               `json:"truncated"`
+}
+
+// CorridorQuery selects the POIs within Width meters of a route.
+type CorridorQuery struct {
+	// Line is the route as [longitude, latitude] positions.
+	Line      [][]float64
+	Width     float64
+	Interests []string
+	Limit     int
+}
+
+// ClusterQuery asks for the POIs of a map viewport grouped into grid cells.
+type ClusterQuery struct {
+	BoundingBox BoundingBox
+	Interests   []string
+	// CellSize is the grid step in degrees, derived from the zoom level.
+	CellSize float64
+	Limit    int
+}
+
+// POICluster is a group of nearby POIs shown as one map marker.
+type POICluster struct {
+	Latitude    float64        `json:"latitude"`
+	Longitude   float64        `json:"longitude"`
+	Count       int            `json:"count"`
+	Interests   map[string]int `json:"interests"`
+	BoundingBox BoundingBox    `json:"bbox"`
+	// POIID is set when the cluster holds a single POI.
+	POIID *int64 `json:"poi_id,omitempty"`
+}
+
+// ClusterResult holds clusters at low zoom levels and individual POIs once
+// the map is zoomed in far enough.
+type ClusterResult struct {
+	Zoom      int                `json:"zoom"`
+	Clusters  []*POICluster      `json:"clusters,omitempty"`
+	POIs      []*PointOfInterest `json:"pois,omitempty"`
+	Truncated bool               `json:"truncated"`
+}
+
+// NearbyQuery describes a search for POIs around a traveler.
+type NearbyQuery struct {
+	Latitude  float64
+	Longitude float64
+	// Radius is the trigger radius of POIs without their own geofence.
+	Radius int
+	// Margin also returns POIs whose trigger area is at most Margin meters
+	// away.
+	Margin    float64
+	Interests []string
+	Limit     int
+	Offset    int
+	// Heading is the direction of travel in degrees clockwise from north.
+	Heading *float64
+	// Speed is the speed of travel in meters per second.
+	Speed *float64
+	// ExcludeIDs are POIs that must not be returned.
+	ExcludeIDs []int64
+}
+
+// NarrationOptions tune the narration generated for a POI.
+type NarrationOptions struct {
+	// Facts are passed to the LLM in addition to the description.
+	Facts []string `json:"facts,omitempty"`
+	// Speaker is the TTS voice of the language, such as aidar or baya for
+	// Russian and en_0 for English.
+	Speaker    string `json:"speaker,omitempty" example:"aidar"`
+	SampleRate int    `json:"sample_rate,omitempty"`
+	// Language of the narration, the default language if empty. The name
+	// and description of this language are narrated when the POI has them.
+	Language string `json:"language,omitempty" example:"en"`
+}
+
+const (
+	ImportStatusCreated = "created"
+	ImportStatusValid   = "valid"
+	ImportStatusFailed  = "failed"
+)
+
+// ImportResult is the outcome of importing one GeoJSON feature.
+type ImportResult struct {
+	Index  int    `json:"index"`
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	POIID  int64  `json:"poi_id,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type ImportReport struct {
+	DryRun  bool           `json:"dry_run"`
+	Total   int            `json:"total"`
+	Created int            `json:"created"`
+	Failed  int            `json:"failed"`
+	Results []ImportResult `json:"results"`
 }
 
 type S3FileInfo struct {
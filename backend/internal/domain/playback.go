@@ -0,0 +1,81 @@
+package domain
+
+import "time"
+
+const (
+	PlaybackStarted   = "started"
+	PlaybackCompleted = "completed"
+	PlaybackSkipped   = "skipped"
+)
+
+// PlaybackEvent is a report of the app about playing an audio file.
+type PlaybackEvent struct {
+	FileID int64  `json:"file_id" example:"301"`
+	Event  string `json:"event" enums:"started,completed,skipped" example:"skipped"`
+	// PositionSeconds is the playback position of the file when the event
+	// happened.
+	PositionSeconds float64 `json:"position_seconds" example:"42.5"`
+	// OccurredAt is when the event happened on the device, the time of
+	// receipt if unset.
+	OccurredAt *time.Time `json:"occurred_at,omitempty" example:"2025-06-01T12:00:00Z"`
+	SessionID  string     `json:"session_id,omitempty" example:"5f0c6a52-8a5e-4c1f-9d4b-2f6a1c0e7b11"`
+}
+
+// StatsPeriod limits the events counted in statistics, unset bounds are open.
+type StatsPeriod struct {
+	From *time.Time
+	To   *time.Time
+}
+
+// PlaybackCounts are the playback events of a POI or a file.
+type PlaybackCounts struct {
+	Started   int64 `json:"started"`
+	Completed int64 `json:"completed"`
+	Skipped   int64 `json:"skipped"`
+	// CompletionRate is the share of starts that were completed, zero
+	// without starts.
+	CompletionRate float64 `json:"completion_rate"`
+}
+
+// SetCompletionRate computes CompletionRate from the counts.
+func (c *PlaybackCounts) SetCompletionRate() {
+	c.CompletionRate = 0
+	if c.Started > 0 {
+		c.CompletionRate = float64(c.Completed) / float64(c.Started)
+	}
+}
+
+// POIPlaybackSummary is a line of the POI statistics overview.
+type POIPlaybackSummary struct {
+	POIID int64  `json:"poi_id"`
+	Name  string `json:"name,omitempty"`
+	PlaybackCounts
+}
+
+// FilePlaybackStats are the playback counts of one audio file of a POI.
+type FilePlaybackStats struct {
+	FileID int64 `json:"file_id"`
+	PlaybackCounts
+}
+
+// SkipBucket counts skips with a position in [FromSeconds, ToSeconds).
+type SkipBucket struct {
+	FromSeconds float64 `json:"from_seconds"`
+	ToSeconds   float64 `json:"to_seconds"`
+	Skips       int64   `json:"skips"`
+}
+
+// DailyPlays is the number of started plays of a UTC day.
+type DailyPlays struct {
+	Day   string `json:"day" example:"2025-06-01"`
+	Plays int64  `json:"plays"`
+}
+
+// POIPlaybackStats is the playback statistics of one POI.
+type POIPlaybackStats struct {
+	POIID int64 `json:"poi_id"`
+	PlaybackCounts
+	Files      []*FilePlaybackStats `json:"files"`
+	SkipPoints []*SkipBucket        `json:"skip_points"`
+	DailyPlays []*DailyPlays        `json:"daily_plays"`
+}
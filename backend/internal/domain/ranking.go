@@ -0,0 +1,41 @@
+package domain
+
+import "time"
+
+// RankingWeights weigh the parts of the score that orders nearby POIs. A
+// zero weight turns its part off, all zero means ordering by distance.
+type RankingWeights struct {
+	Distance   float64
+	Interest   float64
+	Popularity float64
+	Recency    float64
+	Novelty    float64
+}
+
+// POIScore explains the rank of a POI in a nearby search. The parts are
+// already multiplied by their weights and add up to Total.
+type POIScore struct {
+	Total float64 `json:"total"`
+	// Distance grows as the POI gets closer, half the weight at the
+	// trigger radius of the search.
+	Distance float64 `json:"distance"`
+	// Interest is the share of the listener's interest weight the POI
+	// matches.
+	Interest float64 `json:"interest"`
+	// Popularity grows with the plays of the POI by all listeners in the
+	// last weeks, relative to the most played POI around.
+	Popularity float64 `json:"popularity"`
+	// Recency is a penalty for a POI the listener heard lately, it halves
+	// every week.
+	Recency float64 `json:"recency"`
+	// Novelty is a bonus for a POI the listener has never heard.
+	Novelty     float64    `json:"novelty"`
+	Plays       int64      `json:"plays"`
+	LastHeardAt *time.Time `json:"last_heard_at,omitempty"`
+}
+
+// POIPlayHistory is how a POI was played, by everyone and by one listener.
+type POIPlayHistory struct {
+	Plays       int64
+	LastHeardAt *time.Time
+}
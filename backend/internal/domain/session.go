@@ -0,0 +1,48 @@
+package domain
+
+import "time"
+
+// ListeningSession is one trip of a listener. The server remembers what was
+// played in it, so the same story is not offered twice.
+type ListeningSession struct {
+	ID        string   `json:"id"`
+	Interests []string `json:"interests"`
+	// Radius is the trigger radius of POIs without their own geofence.
+	Radius int `json:"radius"`
+	// CooldownSeconds is the pause after a story before the next one is offered.
+	CooldownSeconds int        `json:"cooldown_seconds"`
+	Latitude        *float64   `json:"latitude,omitempty"`
+	Longitude       *float64   `json:"longitude,omitempty"`
+	HeardPOIIDs     []int64    `json:"heard_poi_ids"`
+	LastPlayedAt    *time.Time `json:"last_played_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// SessionLocation is a position update of a listener.
+type SessionLocation struct {
+	Latitude  float64  `json:"latitude" example:"56.8389"`
+	Longitude float64  `json:"longitude" example:"60.6057"`
+	Heading   *float64 `json:"heading,omitempty" example:"90"`
+	Speed     *float64 `json:"speed,omitempty" example:"1.4"`
+}
+
+// SessionNext is what a listener should play next.
+type SessionNext struct {
+	// POI is the nearest POI not heard yet, unset while the cooldown lasts
+	// or when there is none around.
+	POI *PointOfInterest `json:"poi,omitempty"`
+	// RemainingAudio is the audio of POI not played in the session yet,
+	// the short audio first and then the full audio segments in order.
+	RemainingAudio []*File `json:"remaining_audio,omitempty"`
+	// CooldownSeconds is the time left until the next story may start.
+	CooldownSeconds int `json:"cooldown_seconds"`
+}
+
+// SessionPlay reports audio of a POI played in a session.
+type SessionPlay struct {
+	POIID   int64   `json:"poi_id" example:"195"`
+	FileIDs []int64 `json:"file_ids,omitempty" example:"301,302"`
+	// Completed marks the POI as heard even if some of its audio was skipped.
+	Completed bool `json:"completed,omitempty"`
+}
@@ -0,0 +1,17 @@
+package domain
+
+const (
+	POIEventApproaching = "approaching"
+	POIEventEntered     = "entered"
+	POIEventLeft        = "left"
+)
+
+// POIEvent tells a streaming client that it came close to, entered or left
+// the trigger area of a POI.
+type POIEvent struct {
+	Type           string           `json:"type" enums:"approaching,entered,left"`
+	POI            *PointOfInterest `json:"poi"`
+	DistanceMeters float64          `json:"distance_meters"`
+	// Seq numbers the events of a stream, it continues after a resume.
+	Seq int64 `json:"seq"`
+}
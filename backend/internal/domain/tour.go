@@ -0,0 +1,70 @@
+package domain
+
+import "time"
+
+const (
+	TourFileCover = "cover"
+	TourFileIntro = "intro"
+	TourFileOutro = "outro"
+)
+
+// Tour is a curated walk along POIs in a fixed order.
+type Tour struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// POIIDs are the stops of the tour in walking order.
+	POIIDs []int64 `json:"poi_ids"`
+	// POIs are the stops themselves, filled when a single tour is read.
+	POIs       []*PointOfInterest `json:"pois,omitempty"`
+	CoverImage *File              `json:"cover_image,omitempty"`
+	IntroAudio *File              `json:"intro_audio,omitempty"`
+	OutroAudio *File              `json:"outro_audio,omitempty"`
+	// DistanceMeters is the length of the straight legs between the stops.
+	DistanceMeters float64 `json:"distance_meters"`
+	// DurationSeconds is the estimated walking time of the tour.
+	DurationSeconds int       `json:"duration_seconds"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+type TourPage struct {
+	Items      []*Tour `json:"items"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+}
+
+// TourPlanRequest asks for a walk from a start point that fits into a time
+// budget.
+type TourPlanRequest struct {
+	Latitude      float64
+	Longitude     float64
+	BudgetSeconds int
+	Interests     []string
+	// RoundTrip makes the walk end at the start point.
+	RoundTrip bool
+}
+
+// TourPlanStop is a POI of a planned walk.
+type TourPlanStop struct {
+	POI *PointOfInterest `json:"poi"`
+	// LegMeters is the estimated walking distance from the previous stop,
+	// or from the start for the first one.
+	LegMeters float64 `json:"leg_meters"`
+	// ArrivalSeconds is the time from the start until the stop is reached.
+	ArrivalSeconds int `json:"arrival_seconds"`
+	// AudioSeconds is the estimated length of the stories of the stop.
+	AudioSeconds int `json:"audio_seconds"`
+}
+
+// TourPlan is an automatically planned walk.
+type TourPlan struct {
+	Latitude        float64         `json:"latitude"`
+	Longitude       float64         `json:"longitude"`
+	RoundTrip       bool            `json:"round_trip"`
+	Stops           []*TourPlanStop `json:"stops"`
+	DistanceMeters  float64         `json:"distance_meters"`
+	WalkingSeconds  int             `json:"walking_seconds"`
+	AudioSeconds    int             `json:"audio_seconds"`
+	DurationSeconds int             `json:"duration_seconds"`
+	BudgetSeconds   int             `json:"budget_seconds"`
+}
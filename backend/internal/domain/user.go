@@ -0,0 +1,50 @@
+package domain
+
+import "time"
+
+const (
+	NarrationShort = "short"
+	NarrationFull  = "full"
+)
+
+// InterestWeight is an interest of a user with how much it matters to them,
+// from 0 exclusive to 1.
+type InterestWeight struct {
+	Interest string  `json:"interest" example:"history"`
+	Weight   float64 `json:"weight" example:"0.8"`
+}
+
+// UserProfile holds the listening preferences of a user.
+type UserProfile struct {
+	Interests []InterestWeight `json:"interests"`
+	// Voice is the preferred TTS voice, empty for the default one.
+	Voice string `json:"voice,omitempty" enums:"aidar,baya"`
+	// NarrationLength tells whether the user wants only the short audio or
+	// the full story.
+	NarrationLength string `json:"narration_length" enums:"short,full" example:"full"`
+	Language        string `json:"language" example:"ru"`
+}
+
+// InterestIDs returns the interests of the profile without their weights.
+func (p *UserProfile) InterestIDs() []string {
+	ids := make([]string, 0, len(p.Interests))
+	for _, interest := range p.Interests {
+		ids = append(ids, interest.Interest)
+	}
+	return ids
+}
+
+type User struct {
+	ID        int64       `json:"id"`
+	Email     string      `json:"email"`
+	Profile   UserProfile `json:"profile"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// AuthToken is a signed token of a user, passed as "Authorization: Bearer".
+type AuthToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+	User      *User     `json:"user"`
+}
@@ -0,0 +1,212 @@
+package handler
+
+import (
+	"aigpsservice/internal/domain"
+	"aigpsservice/internal/repository"
+	"aigpsservice/internal/service"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type AuthHandler struct {
+	authService *service.AuthService
+}
+
+func NewAuthHandler(authService *service.AuthService) *AuthHandler {
+	return &AuthHandler{
+		authService: authService,
+	}
+}
+
+// publicWrites are the write endpoints open to anonymous callers: searches
+// sent as POST, the listening sessions and playback events of the app and
+// signing up or in.
+var publicWrites = []string{
+	"/api/poi/polygon",
+	"/api/poi/route",
+	"/api/sessions",
+	"/api/events",
+	"/api/users/register",
+	"/api/users/login",
+}
+
+// requiredRole returns the role needed for a request, empty if it is public.
+// Reads are public, except the S3 listing, API key management, the admin
+// statistics and the account of the caller. Any other write needs an editor.
+func requiredRole(r *http.Request) string {
+	path := r.URL.Path
+	switch {
+	case strings.HasPrefix(path, "/api/auth/"), strings.HasPrefix(path, "/api/admin/"):
+		return domain.RoleAdmin
+	case path == "/s3/list":
+		return domain.RoleEditor
+	case path == "/api/users/me" || strings.HasPrefix(path, "/api/users/me/"):
+		return domain.RoleListener
+	case r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions:
+		return ""
+	}
+
+	for _, public := range publicWrites {
+		if path == public || strings.HasPrefix(path, public+"/") {
+			return ""
+		}
+	}
+	return domain.RoleEditor
+}
+
+// Middleware authenticates the caller from an "Authorization: Bearer" or
+// X-API-Key header and rejects requests the caller may not make. Invalid
+// credentials are rejected even on public endpoints.
+func (h *AuthHandler) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var principal *domain.Principal
+		if credential := credentialFrom(r); credential != "" {
+			var err error
+			principal, err = h.authService.Authenticate(r.Context(), credential)
+			if errors.Is(err, service.ErrUnauthenticated) {
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				writeError(w, http.StatusUnauthorized, err.Error())
+				return
+			}
+			if err != nil {
+				writeError(w, http.StatusInternalServerError, "Failed to authenticate: "+err.Error())
+				return
+			}
+			r = r.WithContext(service.WithPrincipal(r.Context(), principal))
+		}
+
+		if role := requiredRole(r); role != "" {
+			if principal == nil {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				writeError(w, http.StatusUnauthorized, "Authentication required")
+				return
+			}
+			if !principal.HasRole(role) {
+				writeError(w, http.StatusForbidden, "The "+role+" role is required")
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func credentialFrom(r *http.Request) string {
+	if scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " "); ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	return r.Header.Get("X-API-Key")
+}
+
+// CreateAPIKeyRequest is the body of a new API key.
+type CreateAPIKeyRequest struct {
+	Name string `json:"name" example:"CMS editor"`
+	Role string `json:"role" enums:"listener,editor,admin" example:"editor"`
+}
+
+// HandleAPIKeys routes requests to the API key collection by method.
+func (h *AuthHandler) HandleAPIKeys(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.ListAPIKeys(w, r)
+	case http.MethodPost:
+		h.CreateAPIKey(w, r)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// ListAPIKeys godoc
+// @Tags Auth
+// @Summary Список API ключей
+// @Description Возвращает все ключи, включая отозванные, без секретов. Только для admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} domain.APIKey
+// @Failure 401 {object} Response
+// @Failure 403 {object} Response
+// @Router /api/auth/keys [get]
+func (h *AuthHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.authService.ListAPIKeys(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to list api keys: "+err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{Data: keys})
+}
+
+// CreateAPIKey godoc
+// @Tags Auth
+// @Summary Создание API ключа
+// @Description Выдает ключ с ролью listener, editor или admin. Секрет возвращается только в этом ответе
+// @Description и передается в заголовке Authorization: Bearer <secret>. Только для admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body CreateAPIKeyRequest true "Название и роль ключа"
+// @Success 201 {object} domain.NewAPIKey
+// @Failure 400 {object} Response
+// @Failure 401 {object} Response
+// @Failure 403 {object} Response
+// @Router /api/auth/keys [post]
+func (h *AuthHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var request CreateAPIKeyRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	key, err := h.authService.CreateAPIKey(r.Context(), request.Name, request.Role)
+	if err != nil {
+		writeAuthError(w, err, "Failed to create api key: ")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, Response{Data: key})
+}
+
+// RevokeAPIKey godoc
+// @Tags Auth
+// @Summary Отзыв API ключа
+// @Description Отключает ключ навсегда. Только для admin
+// @Security BearerAuth
+// @Param id path int true "Id ключа"
+// @Success 204
+// @Failure 401 {object} Response
+// @Failure 403 {object} Response
+// @Failure 404 {object} Response
+// @Router /api/auth/keys/{id} [delete]
+func (h *AuthHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid id format")
+		return
+	}
+
+	if err := h.authService.RevokeAPIKey(r.Context(), id); err != nil {
+		writeAuthError(w, err, "Failed to revoke api key: ")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeAuthError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, repository.ErrAPIKeyNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrInvalidAPIKey):
+		writeError(w, http.StatusBadRequest, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, message+err.Error())
+	}
+}
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		size    int64
+		want    []byteRange
+		wantErr error
+	}{
+		{name: "first bytes", header: "bytes=0-99", size: 1000, want: []byteRange{{0, 99}}},
+		{name: "open end", header: "bytes=900-", size: 1000, want: []byteRange{{900, 999}}},
+		{name: "end past size", header: "bytes=900-5000", size: 1000, want: []byteRange{{900, 999}}},
+		{name: "suffix", header: "bytes=-100", size: 1000, want: []byteRange{{900, 999}}},
+		{name: "suffix longer than object", header: "bytes=-5000", size: 1000, want: []byteRange{{0, 999}}},
+		{name: "spaces", header: "bytes= 0 - 9 , 20-29", size: 1000, want: []byteRange{{0, 9}, {20, 29}}},
+		{name: "overlapping kept as asked", header: "bytes=0-499,400-599", size: 1000, want: []byteRange{{0, 499}, {400, 599}}},
+		{name: "unsatisfiable part skipped", header: "bytes=2000-2999,0-9", size: 1000, want: []byteRange{{0, 9}}},
+		{name: "no header", header: "", size: 1000},
+		{name: "other unit", header: "items=0-9", size: 1000},
+		{name: "no ranges", header: "bytes=", size: 1000},
+		{name: "only commas", header: "bytes= , ", size: 1000},
+		{name: "no dash", header: "bytes=10", size: 1000},
+		{name: "end before start", header: "bytes=10-5", size: 1000},
+		{name: "not a number", header: "bytes=a-b", size: 1000},
+		{name: "dash only", header: "bytes=-", size: 1000},
+		{name: "too many ranges", header: "bytes=0-0,1-1,2-2,3-3,4-4,5-5,6-6,7-7,8-8,9-9,10-10,11-11,12-12,13-13,14-14,15-15,16-16", size: 1000},
+		{name: "start past size", header: "bytes=1000-", size: 1000, wantErr: errRangeNotSatisfiable},
+		{name: "zero suffix", header: "bytes=-0", size: 1000, wantErr: errRangeNotSatisfiable},
+		{name: "empty object", header: "bytes=-100", size: 0, wantErr: errRangeNotSatisfiable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseRange(tt.header, tt.size)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ranges = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	modified := time.Date(2024, 5, 1, 12, 0, 0, 500, time.UTC)
+
+	tests := []struct {
+		name         string
+		method       string
+		headers      map[string]string
+		etag         string
+		lastModified time.Time
+		want         bool
+	}{
+		{name: "no conditions", etag: `"abc"`, lastModified: modified, want: false},
+		{name: "etag matches", headers: map[string]string{"If-None-Match": `"abc"`}, etag: `"abc"`, want: true},
+		{name: "etag in list", headers: map[string]string{"If-None-Match": `"x", "abc"`}, etag: `"abc"`, want: true},
+		{name: "etag differs", headers: map[string]string{"If-None-Match": `"x"`}, etag: `"abc"`, want: false},
+		{name: "star", headers: map[string]string{"If-None-Match": "*"}, etag: `"abc"`, want: true},
+		{name: "weak client tag", headers: map[string]string{"If-None-Match": `W/"abc"`}, etag: `"abc"`, want: true},
+		{name: "weak object tag", headers: map[string]string{"If-None-Match": `"abc"`}, etag: `W/"abc"`, want: true},
+		{name: "head", method: http.MethodHead, headers: map[string]string{"If-None-Match": `"abc"`}, etag: `"abc"`, want: true},
+		{name: "not a read", method: http.MethodPost, headers: map[string]string{"If-None-Match": `"abc"`}, etag: `"abc"`, want: false},
+		{
+			name: "etag wins over date",
+			headers: map[string]string{
+				"If-None-Match":     `"x"`,
+				"If-Modified-Since": modified.Add(time.Hour).Format(http.TimeFormat),
+			},
+			etag: `"abc"`, lastModified: modified, want: false,
+		},
+		{name: "not modified since", headers: map[string]string{"If-Modified-Since": modified.Format(http.TimeFormat)}, etag: `"abc"`, lastModified: modified, want: true},
+		{name: "modified since", headers: map[string]string{"If-Modified-Since": modified.Add(-time.Second).Format(http.TimeFormat)}, etag: `"abc"`, lastModified: modified, want: false},
+		{name: "bad date", headers: map[string]string{"If-Modified-Since": "yesterday"}, etag: `"abc"`, lastModified: modified, want: false},
+		{name: "unknown modification time", headers: map[string]string{"If-Modified-Since": modified.Format(http.TimeFormat)}, etag: `"abc"`, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			r := httptest.NewRequest(method, "/", nil)
+			for key, value := range tt.headers {
+				r.Header.Set(key, value)
+			}
+			if got := notModified(r, tt.etag, tt.lastModified); got != tt.want {
+				t.Errorf("notModified = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRangeApplies(t *testing.T) {
+	modified := time.Date(2024, 5, 1, 12, 0, 0, 500, time.UTC)
+
+	tests := []struct {
+		name    string
+		ifRange string
+		etag    string
+		want    bool
+	}{
+		{name: "no condition", ifRange: "", etag: `"abc"`, want: true},
+		{name: "same etag", ifRange: `"abc"`, etag: `"abc"`, want: true},
+		{name: "other etag", ifRange: `"x"`, etag: `"abc"`, want: false},
+		{name: "weak client tag", ifRange: `W/"abc"`, etag: `"abc"`, want: false},
+		{name: "weak object tag", ifRange: `"abc"`, etag: `W/"abc"`, want: false},
+		{name: "same date", ifRange: modified.Format(http.TimeFormat), etag: `"abc"`, want: true},
+		{name: "other date", ifRange: modified.Add(-time.Second).Format(http.TimeFormat), etag: `"abc"`, want: false},
+		{name: "bad date", ifRange: "yesterday", etag: `"abc"`, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.ifRange != "" {
+				r.Header.Set("If-Range", tt.ifRange)
+			}
+			if got := rangeApplies(r, tt.etag, modified); got != tt.want {
+				t.Errorf("rangeApplies = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"aigpsservice/internal/domain"
+	"aigpsservice/internal/service"
+	"aigpsservice/pkg/logger"
+	"fmt"
+	"net/http"
+)
+
+type ExportHandler struct {
+	exportService   *service.ExportService
+	interestService *service.InterestService
+}
+
+func NewExportHandler(exportService *service.ExportService, interestService *service.InterestService) *ExportHandler {
+	return &ExportHandler{
+		exportService:   exportService,
+		interestService: interestService,
+	}
+}
+
+// ExportPOIs godoc
+// @Tags POI
+// @Summary Выгрузка каталога точек интереса
+// @Description Потоково выгружает все точки интереса с интересами и публичными ссылками на файлы
+// @Description в формате GeoJSON, GPX (waypoints) или KML
+// @Param format query string false "Формат выгрузки" Enums(geojson, gpx, kml) default(geojson)
+// @Param interests query []string false "Интересы точки (id из /api/interests)" CollectionFormat(multi)
+// @Param bbox query string false "Ограничивающий прямоугольник minLon,minLat,maxLon,maxLat" example(60.5,56.8,60.7,56.9)
+// @Success 200 {file} byte "Файл выгрузки"
+// @Failure 400 {object} Response
+// @Router /api/poi/export [get]
+func (h *ExportHandler) ExportPOIs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	query := r.URL.Query()
+
+	format := query.Get("format")
+	if format == "" {
+		format = service.ExportFormatGeoJSON
+	}
+
+	contentType, ext, err := h.exportService.ContentType(format)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	filter := domain.POIExportFilter{Interests: query["interests"]}
+	if err := h.interestService.ValidateInterests(r.Context(), filter.Interests); err != nil {
+		writeInterestsError(w, err)
+		return
+	}
+	if bboxStr := query.Get("bbox"); bboxStr != "" {
+		filter.BoundingBox, err = parseBoundingBox(bboxStr)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"poi%s\"", ext))
+
+	// The status is already sent once streaming starts, so a failure can
+	// only cut the file short.
+	err = h.exportService.Export(r.Context(), w, format, filter)
+	if err != nil {
+		logger.Error.Printf("Failed to export POIs as %s: %v", format, err)
+	}
+}
@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"aigpsservice/internal/domain"
+	"aigpsservice/internal/repository"
+	"aigpsservice/internal/service"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+type InterestHandler struct {
+	interestService *service.InterestService
+}
+
+func NewInterestHandler(interestService *service.InterestService) *InterestHandler {
+	return &InterestHandler{
+		interestService: interestService,
+	}
+}
+
+// HandleInterests routes requests to the interest collection by method.
+func (h *InterestHandler) HandleInterests(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.ListInterests(w, r)
+	case http.MethodPost:
+		h.CreateInterest(w, r)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// HandleInterest routes requests to a single interest by method.
+func (h *InterestHandler) HandleInterest(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetInterest(w, r)
+	case http.MethodPatch:
+		h.UpdateInterest(w, r)
+	case http.MethodDelete:
+		h.DeleteInterest(w, r)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// ListInterests godoc
+// @Tags Interests
+// @Summary Список интересов
+// @Description Возвращает все интересы с локализованными названиями, иконками и родителями.
+// @Description Поиск точек по родительскому интересу находит и точки с дочерними интересами
+// @Produce json
+// @Success 200 {array} domain.InterestType
+// @Failure 500 {object} Response
+// @Router /api/interests [get]
+func (h *InterestHandler) ListInterests(w http.ResponseWriter, r *http.Request) {
+	interests, err := h.interestService.ListInterests(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to list interests: "+err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{Data: interests})
+}
+
+// GetInterest godoc
+// @Tags Interests
+// @Summary Получение интереса по id
+// @Produce json
+// @Param id path string true "Id интереса" example(history)
+// @Success 200 {object} domain.InterestType
+// @Failure 404 {object} Response
+// @Router /api/interests/{id} [get]
+func (h *InterestHandler) GetInterest(w http.ResponseWriter, r *http.Request) {
+	interest, err := h.interestService.GetInterest(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeInterestTypeError(w, err, "Failed to get interest: ")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{Data: interest})
+}
+
+// CreateInterest godoc
+// @Tags Interests
+// @Summary Создание интереса
+// @Description Создает интерес. Id из строчных латинских букв, цифр и подчеркиваний,
+// @Description names - названия по кодам языков, parent_id - необязательный родительский интерес
+// @Accept json
+// @Produce json
+// @Param interest body domain.InterestType true "Интерес"
+// @Success 201 {object} domain.InterestType
+// @Failure 400 {object} Response
+// @Failure 409 {object} Response
+// @Security BearerAuth
+// @Router /api/interests [post]
+func (h *InterestHandler) CreateInterest(w http.ResponseWriter, r *http.Request) {
+	var interest domain.InterestType
+	if err := json.NewDecoder(r.Body).Decode(&interest); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	created, err := h.interestService.CreateInterest(r.Context(), &interest)
+	if err != nil {
+		writeInterestTypeError(w, err, "Failed to create interest: ")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, Response{Data: created})
+}
+
+// UpdateInterest godoc
+// @Tags Interests
+// @Summary Частичное изменение интереса
+// @Description Изменяет только переданные поля. names заменяются целиком,
+// @Description пустой parent_id делает интерес корневым, пустой icon удаляет иконку
+// @Accept json
+// @Produce json
+// @Param id path string true "Id интереса" example(military)
+// @Param update body domain.InterestTypeUpdate true "Изменения"
+// @Success 200 {object} domain.InterestType
+// @Failure 400 {object} Response
+// @Failure 404 {object} Response
+// @Security BearerAuth
+// @Router /api/interests/{id} [patch]
+func (h *InterestHandler) UpdateInterest(w http.ResponseWriter, r *http.Request) {
+	var update domain.InterestTypeUpdate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	updated, err := h.interestService.UpdateInterest(r.Context(), r.PathValue("id"), &update)
+	if err != nil {
+		writeInterestTypeError(w, err, "Failed to update interest: ")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{Data: updated})
+}
+
+// DeleteInterest godoc
+// @Tags Interests
+// @Summary Удаление интереса
+// @Description Удаляет интерес и снимает его с точек. Интерес с дочерними интересами удалить нельзя
+// @Param id path string true "Id интереса" example(military)
+// @Success 204
+// @Failure 404 {object} Response
+// @Failure 409 {object} Response
+// @Security BearerAuth
+// @Router /api/interests/{id} [delete]
+func (h *InterestHandler) DeleteInterest(w http.ResponseWriter, r *http.Request) {
+	if err := h.interestService.DeleteInterest(r.Context(), r.PathValue("id")); err != nil {
+		writeInterestTypeError(w, err, "Failed to delete interest: ")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeInterestTypeError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, repository.ErrInterestNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, repository.ErrInterestExists), errors.Is(err, repository.ErrInterestHasChildren):
+		writeError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrInvalidInterestType), errors.Is(err, repository.ErrInterestParentNotFound):
+		writeError(w, http.StatusBadRequest, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, message+err.Error())
+	}
+}
+
+// writeInterestsError answers a request whose interests filter or tags
+// failed validation.
+func writeInterestsError(w http.ResponseWriter, err error) {
+	if errors.Is(err, service.ErrUnknownInterest) {
+		writeError(w, http.StatusBadRequest, "Invalid interests: "+err.Error())
+		return
+	}
+	writeError(w, http.StatusInternalServerError, "Failed to check interests: "+err.Error())
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"aigpsservice/internal/repository"
+	"aigpsservice/internal/service"
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+type JobHandler struct {
+	jobService *service.JobService
+}
+
+func NewJobHandler(jobService *service.JobService) *JobHandler {
+	return &JobHandler{
+		jobService: jobService,
+	}
+}
+
+// GetJob godoc
+// @Tags Jobs
+// @Summary Статус фоновой задачи
+// @Description Возвращает статус, число попыток, последнюю ошибку и результат фоновой задачи
+// @Produce json
+// @Param id path int true "Id задачи" example(42)
+// @Success 200 {object} domain.Job
+// @Failure 400 {object} Response
+// @Failure 404 {object} Response
+// @Failure 500 {object} Response
+// @Router /api/jobs/{id} [get]
+func (h *JobHandler) GetJob(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid id format")
+		return
+	}
+
+	job, err := h.jobService.GetJob(r.Context(), id)
+	if errors.Is(err, repository.ErrJobNotFound) {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to get job: "+err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{Data: job})
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"aigpsservice/internal/domain"
+	"aigpsservice/internal/service"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	// maxFormValueBytes limits a single text field of a streamed form.
+	maxFormValueBytes = 1 << 20
+	// maxFormParts limits the number of fields and files of a streamed form.
+	maxFormParts = 256
+	// maxFormBytes limits the whole body of a streamed form. Files have their
+	// own limits, this one bounds a form of many files.
+	maxFormBytes = 1 << 30
+)
+
+var (
+	errInvalidForm  = errors.New("failed to parse form data")
+	errFormTooLarge = errors.New("form is too large")
+)
+
+// streamMultipart reads a multipart/form-data body part by part without
+// buffering files. Text fields are collected into the returned values, file
+// parts are passed to onFile in the order they arrive and must be consumed
+// before it returns. Parse errors wrap errInvalidForm, a form over
+// maxFormParts or maxFormBytes fails with errFormTooLarge; other errors of
+// onFile are returned as is.
+func streamMultipart(w http.ResponseWriter, r *http.Request, onFile func(part *multipart.Part) error) (url.Values, error) {
+	body := &formBody{ReadCloser: http.MaxBytesReader(w, r.Body, maxFormBytes)}
+	r.Body = body
+
+	values, err := readMultipart(r, onFile)
+	// The storage client does not keep the reader error in its chain, so
+	// the body reports the overflow itself.
+	if body.tooLarge {
+		return nil, fmt.Errorf("%w: maximum allowed size is %d bytes", errFormTooLarge, maxFormBytes)
+	}
+	return values, err
+}
+
+func readMultipart(r *http.Request, onFile func(part *multipart.Part) error) (url.Values, error) {
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidForm, err)
+	}
+
+	values := make(url.Values)
+	for parts := 0; ; parts++ {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return values, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errInvalidForm, err)
+		}
+		if parts == maxFormParts {
+			part.Close()
+			return nil, fmt.Errorf("%w: at most %d fields and files are allowed", errFormTooLarge, maxFormParts)
+		}
+
+		if part.FileName() == "" {
+			value, err := io.ReadAll(io.LimitReader(part, maxFormValueBytes+1))
+			part.Close()
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", errInvalidForm, err)
+			}
+			if len(value) > maxFormValueBytes {
+				return nil, fmt.Errorf("%w: field %s is too large", errInvalidForm, part.FormName())
+			}
+			values.Add(part.FormName(), string(value))
+			continue
+		}
+
+		err = onFile(part)
+		part.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// formBody remembers whether the request body went over its limit.
+type formBody struct {
+	io.ReadCloser
+	tooLarge bool
+}
+
+func (b *formBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && err != io.EOF {
+		var maxBytesErr *http.MaxBytesError
+		b.tooLarge = b.tooLarge || errors.As(err, &maxBytesErr)
+	}
+	return n, err
+}
+
+// newPartFile describes a streamed file part. The size is set once the file
+// is uploaded.
+func newPartFile(part *multipart.Part, isShort bool, serialNumber int64) *domain.File {
+	return &domain.File{
+		FileName:     part.FileName(),
+		MimeType:     part.Header.Get("Content-Type"),
+		IsShort:      isShort,
+		SerialNumber: serialNumber,
+		CreatedAt:    time.Now(),
+	}
+}
+
+// writeUploadError maps a failed streamed upload to a response status.
+func writeUploadError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, errInvalidForm):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrFileTooLarge), errors.Is(err, errFormTooLarge):
+		writeError(w, http.StatusRequestEntityTooLarge, err.Error())
+	case errors.Is(err, service.ErrUnsupportedFileType):
+		writeError(w, http.StatusUnsupportedMediaType, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, message+err.Error())
+	}
+}
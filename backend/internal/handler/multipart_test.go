@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"aigpsservice/internal/domain"
+	"aigpsservice/internal/service"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testBoundary = "test-boundary"
+
+// discardFileStorage reads uploads to the end and keeps nothing, like a
+// storage that streams files away.
+type discardFileStorage struct{}
+
+func (discardFileStorage) UploadFile(ctx context.Context, file io.Reader, fileData *domain.File) (string, error) {
+	size, err := io.Copy(io.Discard, file)
+	if err != nil {
+		return "", err
+	}
+	fileData.FileSize = size
+	return "audio/" + fileData.FileName, nil
+}
+
+func (discardFileStorage) DeleteFile(s3Key string) error { return nil }
+
+func (discardFileStorage) DeleteFiles(s3Keys []string) error { return nil }
+
+// zeroReader yields n zero bytes without holding them in memory.
+type zeroReader struct {
+	n int64
+}
+
+func (z *zeroReader) Read(p []byte) (int, error) {
+	if z.n <= 0 {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > z.n {
+		p = p[:z.n]
+	}
+	clear(p)
+	z.n -= int64(len(p))
+	return len(p), nil
+}
+
+// audioFormRequest returns a request with a form of one text field and one
+// audio file of size bytes.
+func audioFormRequest(size int64) *http.Request {
+	head := "--" + testBoundary + "\r\n" +
+		"Content-Disposition: form-data; name=\"name\"\r\n\r\n" +
+		"Dam\r\n" +
+		"--" + testBoundary + "\r\n" +
+		"Content-Disposition: form-data; name=\"full_audio\"; filename=\"story.mp3\"\r\n" +
+		"Content-Type: audio/mpeg\r\n\r\n"
+	tail := "\r\n--" + testBoundary + "--\r\n"
+
+	body := io.MultiReader(strings.NewReader(head), &zeroReader{n: size}, strings.NewReader(tail))
+	r := httptest.NewRequest(http.MethodPost, "/api/poi/create", body)
+	r.Header.Set("Content-Type", "multipart/form-data; boundary="+testBoundary)
+	return r
+}
+
+// BenchmarkStreamAudioUpload shows that streaming a file to storage takes
+// the same memory whatever its size.
+func BenchmarkStreamAudioUpload(b *testing.B) {
+	poiService := service.NewPOIService(nil, discardFileStorage{}, nil, nil, domain.RankingWeights{})
+
+	for _, megabytes := range []int64{1, 10, 50} {
+		size := megabytes << 20
+		b.Run(fmt.Sprintf("%dMB", megabytes), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(size)
+
+			for b.Loop() {
+				w := httptest.NewRecorder()
+				r := audioFormRequest(size)
+				uploads := poiService.NewFileUploads(r.Context())
+
+				var file *domain.File
+				form, err := streamMultipart(w, r, func(part *multipart.Part) error {
+					file = newPartFile(part, false, 1)
+					return uploads.UploadAudio(part, file)
+				})
+				if err != nil {
+					b.Fatalf("streamMultipart() error = %v", err)
+				}
+				if form.Get("name") != "Dam" || file == nil || file.FileSize != size {
+					b.Fatalf("streamMultipart() read name %q and file %+v, want Dam and %d bytes", form.Get("name"), file, size)
+				}
+			}
+		})
+	}
+}
+
+func TestStreamMultipartTooManyParts(t *testing.T) {
+	var body strings.Builder
+	for i := 0; i <= maxFormParts; i++ {
+		fmt.Fprintf(&body, "--%s\r\nContent-Disposition: form-data; name=\"interests\"\r\n\r\nhistory\r\n", testBoundary)
+	}
+	fmt.Fprintf(&body, "--%s--\r\n", testBoundary)
+
+	r := httptest.NewRequest(http.MethodPost, "/api/poi/create", strings.NewReader(body.String()))
+	r.Header.Set("Content-Type", "multipart/form-data; boundary="+testBoundary)
+
+	_, err := streamMultipart(httptest.NewRecorder(), r, func(part *multipart.Part) error { return nil })
+	if !errors.Is(err, errFormTooLarge) {
+		t.Fatalf("streamMultipart() error = %v, want %v", err, errFormTooLarge)
+	}
+}
+
+func TestWriteUploadError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "invalid form", err: fmt.Errorf("%w: unexpected EOF", errInvalidForm), want: http.StatusBadRequest},
+		{name: "form too large", err: fmt.Errorf("%w: too many parts", errFormTooLarge), want: http.StatusRequestEntityTooLarge},
+		{name: "file too large", err: fmt.Errorf("failed to upload audio a.mp3: %w", service.ErrFileTooLarge), want: http.StatusRequestEntityTooLarge},
+		{name: "unsupported type", err: fmt.Errorf("%w: image type %q", service.ErrUnsupportedFileType, "image/gif"), want: http.StatusUnsupportedMediaType},
+		{name: "storage failure", err: errors.New("connection reset"), want: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			writeUploadError(w, tt.err, "Failed to create point of interest: ")
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestUploadAudioUnsupportedType(t *testing.T) {
+	poiService := service.NewPOIService(nil, discardFileStorage{}, nil, nil, domain.RankingWeights{})
+	uploads := poiService.NewFileUploads(context.Background())
+
+	file := &domain.File{FileName: "story.flac", MimeType: "audio/flac"}
+	err := uploads.UploadAudio(strings.NewReader("fLaC"), file)
+	if !errors.Is(err, service.ErrUnsupportedFileType) {
+		t.Fatalf("UploadAudio() error = %v, want %v", err, service.ErrUnsupportedFileType)
+	}
+}
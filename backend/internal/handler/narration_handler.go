@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"aigpsservice/internal/domain"
+	"aigpsservice/internal/repository"
+	"aigpsservice/internal/service"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+type NarrationHandler struct {
+	narrationService *service.NarrationService
+}
+
+func NewNarrationHandler(narrationService *service.NarrationService) *NarrationHandler {
+	return &NarrationHandler{
+		narrationService: narrationService,
+	}
+}
+
+// NarratePOI godoc
+// @Tags POI
+// @Summary Генерация озвучки точки интереса
+// @Description Ставит в очередь фоновую задачу: рассказ по описанию точки генерируется через LLM, озвучивается
+// @Description через TTS и сохраняется как короткое и полные аудио точки на языке language, заменяя прежние
+// @Description аудио этого языка. Для языка, отличного от русского, используется перевод точки, если он есть.
+// @Description Статус задачи доступен по /api/jobs/{id}
+// @Accept json
+// @Param id path int true "Id точки интереса" example(195)
+// @Param options body domain.NarrationOptions false "Параметры озвучки"
+// @Success 202 {object} domain.Job
+// @Failure 400 {object} Response
+// @Failure 404 {object} Response
+// @Failure 500 {object} Response
+// @Security BearerAuth
+// @Router /api/poi/{id}/narrate [post]
+func (h *NarrationHandler) NarratePOI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	idPOI, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid id format")
+		return
+	}
+
+	var options domain.NarrationOptions
+	if err := json.NewDecoder(r.Body).Decode(&options); err != nil && err != io.EOF {
+		writeError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	job, err := h.narrationService.EnqueueNarratePOI(r.Context(), idPOI, options)
+	if errors.Is(err, repository.ErrPOINotFound) {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if errors.Is(err, service.ErrInvalidNarrationOptions) {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to schedule narration: "+err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, Response{Data: job})
+}
@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"aigpsservice/internal/domain"
+	"aigpsservice/internal/service"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+func parseIDs(values []string) ([]int64, error) {
+	ids := make([]int64, 0, len(values))
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// parseBoundingBox parses a "minLon,minLat,maxLon,maxLat" bounding box.
+func parseBoundingBox(value string) (*domain.BoundingBox, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 4 {
+		return nil, fmt.Errorf("bbox must be minLon,minLat,maxLon,maxLat")
+	}
+
+	coords := make([]float64, 4)
+	for i, part := range parts {
+		coord, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, fmt.Errorf("bbox must be minLon,minLat,maxLon,maxLat")
+		}
+		coords[i] = coord
+	}
+
+	bbox := &domain.BoundingBox{
+		MinLongitude: coords[0],
+		MinLatitude:  coords[1],
+		MaxLongitude: coords[2],
+		MaxLatitude:  coords[3],
+	}
+
+	if bbox.MinLongitude < -180 || bbox.MaxLongitude > 180 || bbox.MinLatitude < -90 || bbox.MaxLatitude > 90 {
+		return nil, fmt.Errorf("bbox is out of range")
+	}
+	if bbox.MinLongitude > bbox.MaxLongitude || bbox.MinLatitude > bbox.MaxLatitude {
+		return nil, fmt.Errorf("bbox minimum must not exceed maximum")
+	}
+
+	return bbox, nil
+}
+
+// parseGeofence reads the trigger_radius and geofence form fields. It returns
+// nil if neither is passed, and an empty geofence if the passed ones are
+// empty.
+func parseGeofence(form url.Values) (*domain.Geofence, error) {
+	if !form.Has("trigger_radius") && !form.Has("geofence") {
+		return nil, nil
+	}
+
+	geofence := &domain.Geofence{}
+	if value := form.Get("trigger_radius"); value != "" {
+		radius, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("trigger_radius must be a number")
+		}
+		geofence.Radius = &radius
+	}
+	if value := form.Get("geofence"); value != "" {
+		if !json.Valid([]byte(value)) {
+			return nil, fmt.Errorf("geofence must be a GeoJSON Polygon or MultiPolygon")
+		}
+		geofence.Polygon = json.RawMessage(value)
+	}
+	return geofence, nil
+}
+
+// parseLanguage reads a language form field, an empty value stays empty.
+func parseLanguage(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	language := service.NormalizeLanguage(value)
+	if language == "" {
+		return "", fmt.Errorf("language must be an ISO 639 code such as en")
+	}
+	return language, nil
+}
+
+// preferredLanguages returns the languages the client asked for, most
+// preferred first: the lang parameter if passed, or else the languages of
+// the Accept-Language header ordered by quality. Regional tags such as en-GB
+// count as their language.
+func preferredLanguages(r *http.Request) ([]string, error) {
+	if value := r.URL.Query().Get("lang"); value != "" {
+		languages := make([]string, 0)
+		for _, tag := range strings.Split(value, ",") {
+			language := service.NormalizeLanguage(tag)
+			if language == "" {
+				return nil, fmt.Errorf("lang must be a comma separated list of ISO 639 codes such as en")
+			}
+			if !slices.Contains(languages, language) {
+				languages = append(languages, language)
+			}
+		}
+		return languages, nil
+	}
+
+	type weighted struct {
+		language string
+		quality  float64
+	}
+	ranges := make([]weighted, 0)
+	for _, entry := range strings.Split(r.Header.Get("Accept-Language"), ",") {
+		tag, params, _ := strings.Cut(entry, ";")
+		language := service.NormalizeLanguage(tag)
+		if language == "" {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range strings.Split(params, ";") {
+			if value, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				parsed, err := strconv.ParseFloat(value, 64)
+				if err != nil {
+					quality = 0
+				} else {
+					quality = parsed
+				}
+			}
+		}
+		if quality <= 0 {
+			continue
+		}
+		ranges = append(ranges, weighted{language: language, quality: quality})
+	}
+
+	slices.SortStableFunc(ranges, func(a, b weighted) int {
+		return cmp.Compare(b.quality, a.quality)
+	})
+
+	languages := make([]string, 0, len(ranges))
+	for _, entry := range ranges {
+		if !slices.Contains(languages, entry.language) {
+			languages = append(languages, entry.language)
+		}
+	}
+	return languages, nil
+}
@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"aigpsservice/internal/domain"
+	"aigpsservice/internal/repository"
+	"aigpsservice/internal/service"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type PlaybackHandler struct {
+	playbackService *service.PlaybackService
+}
+
+func NewPlaybackHandler(playbackService *service.PlaybackService) *PlaybackHandler {
+	return &PlaybackHandler{
+		playbackService: playbackService,
+	}
+}
+
+// PlaybackEventsRequest is a batch of playback events.
+type PlaybackEventsRequest struct {
+	Events []*domain.PlaybackEvent `json:"events"`
+}
+
+// RecordEvents godoc
+// @Tags Analytics
+// @Summary Прием событий прослушивания
+// @Description Сохраняет пачку событий (до 500) проигрывания аудио: started, completed или skipped с позицией в секундах.
+// @Description file_id - id аудио из files точки. Без occurred_at используется время получения.
+// @Description С токеном пользователя события привязываются к нему. Пачка сохраняется целиком или не сохраняется
+// @Security BearerAuth
+// @Accept json
+// @Param request body PlaybackEventsRequest true "События"
+// @Success 204
+// @Failure 400 {object} Response
+// @Router /api/events [post]
+func (h *PlaybackHandler) RecordEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var request PlaybackEventsRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	if err := h.playbackService.RecordEvents(r.Context(), request.Events); err != nil {
+		writePlaybackError(w, err, "Failed to record events: ")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListPOIStats godoc
+// @Tags Analytics
+// @Summary Статистика прослушиваний по точкам
+// @Description Возвращает точки с наибольшим числом запусков за период: число запусков, дослушиваний, пропусков
+// @Description и долю дослушанных запусков. Только для admin
+// @Security BearerAuth
+// @Produce json
+// @Param from query string false "Начало периода (RFC3339)" example(2025-06-01T00:00:00Z)
+// @Param to query string false "Конец периода, не включая (RFC3339)" example(2025-07-01T00:00:00Z)
+// @Param limit query int false "Количество точек (от 1 до 500)" default(50)
+// @Success 200 {array} domain.POIPlaybackSummary
+// @Failure 400 {object} Response
+// @Failure 401 {object} Response
+// @Failure 403 {object} Response
+// @Router /api/admin/stats/pois [get]
+func (h *PlaybackHandler) ListPOIStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	query := r.URL.Query()
+	period, err := parseStatsPeriod(query)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var limit int
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid limit format")
+			return
+		}
+	}
+
+	stats, err := h.playbackService.ListPOIStats(r.Context(), period, limit)
+	if err != nil {
+		writePlaybackError(w, err, "Failed to get stats: ")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{Data: stats})
+}
+
+// GetPOIStats godoc
+// @Tags Analytics
+// @Summary Статистика прослушиваний точки
+// @Description Возвращает для точки за период долю дослушанных запусков в целом и по каждому аудио,
+// @Description гистограмму позиций пропуска с шагом bucket_seconds и число запусков по дням (UTC). Только для admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Id точки интереса" example(195)
+// @Param from query string false "Начало периода (RFC3339)" example(2025-06-01T00:00:00Z)
+// @Param to query string false "Конец периода, не включая (RFC3339)" example(2025-07-01T00:00:00Z)
+// @Param bucket_seconds query number false "Ширина интервала гистограммы пропусков в секундах (от 1 до 3600)" default(10)
+// @Success 200 {object} domain.POIPlaybackStats
+// @Failure 400 {object} Response
+// @Failure 401 {object} Response
+// @Failure 403 {object} Response
+// @Router /api/admin/stats/pois/{id} [get]
+func (h *PlaybackHandler) GetPOIStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid id format")
+		return
+	}
+
+	query := r.URL.Query()
+	period, err := parseStatsPeriod(query)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var bucketSeconds float64
+	if bucketStr := query.Get("bucket_seconds"); bucketStr != "" {
+		bucketSeconds, err = strconv.ParseFloat(bucketStr, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid bucket_seconds format")
+			return
+		}
+	}
+
+	stats, err := h.playbackService.GetPOIStats(r.Context(), id, period, bucketSeconds)
+	if err != nil {
+		writePlaybackError(w, err, "Failed to get stats: ")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{Data: stats})
+}
+
+func parseStatsPeriod(query url.Values) (domain.StatsPeriod, error) {
+	var period domain.StatsPeriod
+	if fromStr := query.Get("from"); fromStr != "" {
+		from, err := time.Parse(time.RFC3339, fromStr)
+		if err != nil {
+			return period, errors.New("Invalid from format, RFC3339 expected")
+		}
+		period.From = &from
+	}
+	if toStr := query.Get("to"); toStr != "" {
+		to, err := time.Parse(time.RFC3339, toStr)
+		if err != nil {
+			return period, errors.New("Invalid to format, RFC3339 expected")
+		}
+		period.To = &to
+	}
+	return period, nil
+}
+
+func writePlaybackError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidPlaybackEvent),
+		errors.Is(err, service.ErrInvalidStatsQuery),
+		errors.Is(err, repository.ErrPlaybackFileNotFound):
+		writeError(w, http.StatusBadRequest, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, message+err.Error())
+	}
+}
@@ -2,9 +2,10 @@ package handler
 
 import (
 	"aigpsservice/internal/domain"
+	"aigpsservice/internal/repository"
 	"aigpsservice/internal/service"
-	"aigpsservice/pkg/logger"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"mime/multipart"
 	"net/http"
@@ -13,180 +14,798 @@ import (
 )
 
 type POIHandler struct {
-	poiService *service.POIService
+	poiService      *service.POIService
+	interestService *service.InterestService
+	userService     *service.UserService
 }
 
-func NewPOIHandler(poiService *service.POIService) *POIHandler {
+func NewPOIHandler(poiService *service.POIService, interestService *service.InterestService, userService *service.UserService) *POIHandler {
 	return &POIHandler{
-		poiService: poiService,
+		poiService:      poiService,
+		interestService: interestService,
+		userService:     userService,
 	}
 }
 
-type Response struct {
-	Data  any    `json:"data,omitempty"`
-	Error string `json:"error,omitempty"`
-}
-
-func (h *POIHandler) writeJSON(w http.ResponseWriter, status int, data any) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	json.NewEncoder(w).Encode(data)
-}
-
-func (h *POIHandler) writeError(w http.ResponseWriter, status int, message string) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	json.NewEncoder(w).Encode(Response{Error: message})
-}
-
 // CreatePOI godoc
 // @Tags POI
 // @Summary Создание новой точки интереса
-// @Description Создает новую точку интереса с изображением и аудиофайлами
+// @Description Создает новую точку интереса с изображением и аудиофайлами.
+// @Description Файлы передаются в S3 потоком по мере чтения формы, изображение до 10 МБ, аудио до 50 МБ, вся форма до 1 ГБ и 256 полей.
 // @Accept multipart/form-data
 // @Param name formData string true "Название точки интереса"
 // @Param description formData string true "Описание точки интереса"
 // @Param latitude formData number true "Широта"
 // @Param longitude formData number true "Долгота"
-// @Param interests formData []string true "Интересы точки" CollectionFormat(multi) Enums(nature, architecture, food, history)
+// @Param interests formData []string true "Интересы точки (id из /api/interests)" CollectionFormat(multi)
+// @Param trigger_radius formData number false "Радиус срабатывания в метрах (до 5000)"
+// @Param geofence formData string false "Область срабатывания, GeoJSON Polygon или MultiPolygon без самопересечений, не дальше 5000 м от точки"
 // @Param image formData file true "Изображение точки интереса"
 // @Param short_audio formData file true "Короткое аудио"
 // @Param full_audio formData []file true "Полные аудио файлы"
+// @Param language formData string false "Язык аудио (ISO 639), по умолчанию ru" example(en)
 // @Success 201 {object} domain.PointOfInterest
 // @Failure 400 {object} Response
+// @Failure 413 {object} Response
+// @Failure 415 {object} Response
 // @Failure 500 {object} Response
+// @Security BearerAuth
 // @Router /api/poi/create [post]
 func (h *POIHandler) CreatePOI(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		h.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
 		return
 	}
 
-	err := r.ParseMultipartForm(32 << 20)
+	// Files are streamed to S3 while the form is read and removed again
+	// unless the POI is saved.
+	uploads := h.poiService.NewFileUploads(r.Context())
+	defer uploads.Discard()
+
+	var imageFileData, shortAudio *domain.File
+	fullAudioFileData := make([]*domain.File, 0)
+
+	form, err := streamMultipart(w, r, func(part *multipart.Part) error {
+		switch part.FormName() {
+		case "image":
+			if imageFileData != nil {
+				return fmt.Errorf("%w: only one image is allowed", errInvalidForm)
+			}
+			imageFileData = newPartFile(part, false, 0)
+			return uploads.UploadImage(part, imageFileData)
+		case "short_audio":
+			if shortAudio != nil {
+				return fmt.Errorf("%w: only one short audio is allowed", errInvalidForm)
+			}
+			shortAudio = newPartFile(part, true, 1)
+			return uploads.UploadAudio(part, shortAudio)
+		case "full_audio":
+			fullAudio := newPartFile(part, false, int64(len(fullAudioFileData)+1))
+			fullAudioFileData = append(fullAudioFileData, fullAudio)
+			return uploads.UploadAudio(part, fullAudio)
+		default:
+			return nil
+		}
+	})
 	if err != nil {
-		h.writeError(w, http.StatusBadRequest, "Failed to parse form data: "+err.Error())
+		writeUploadError(w, err, "Failed to create point of interest: ")
 		return
 	}
 
-	name := r.FormValue("name")
-	description := r.FormValue("description")
-	latStr := r.FormValue("latitude")
-	lngStr := r.FormValue("longitude")
-	interests := r.Form["interests"]
+	name := form.Get("name")
+	description := form.Get("description")
+	latStr := form.Get("latitude")
+	lngStr := form.Get("longitude")
+	interests := form["interests"]
 
 	if name == "" || description == "" || latStr == "" || lngStr == "" {
-		h.writeError(w, http.StatusBadRequest, "Missing required fields: name, description, latitude, longitude")
+		writeError(w, http.StatusBadRequest, "Missing required fields: name, description, latitude, longitude")
 		return
 	}
 
 	latitude, err := strconv.ParseFloat(latStr, 64)
 	if err != nil {
-		h.writeError(w, http.StatusBadRequest, "Invalid latitude format")
+		writeError(w, http.StatusBadRequest, "Invalid latitude format")
 		return
 	}
 
 	longitude, err := strconv.ParseFloat(lngStr, 64)
 	if err != nil {
-		h.writeError(w, http.StatusBadRequest, "Invalid longitude format")
+		writeError(w, http.StatusBadRequest, "Invalid longitude format")
+		return
+	}
+
+	if err := h.interestService.ValidateInterests(r.Context(), interests); err != nil {
+		writeInterestsError(w, err)
+		return
+	}
+
+	geofence, err := parseGeofence(form)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if geofence != nil && geofence.Radius == nil && geofence.Polygon == nil {
+		geofence = nil
+	}
+
+	if imageFileData == nil {
+		writeError(w, http.StatusBadRequest, "Image file is required")
+		return
+	}
+
+	audioLanguage, err := parseLanguage(form.Get("language"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if shortAudio != nil {
+		shortAudio.Language = audioLanguage
+	}
+	for _, fullAudio := range fullAudioFileData {
+		fullAudio.Language = audioLanguage
+	}
+
+	poiRequest := &domain.PointOfInterest{
+		Name:           name,
+		Description:    description,
+		Latitude:       latitude,
+		Longitude:      longitude,
+		Interests:      interests,
+		CreatedAt:      time.Now(),
+		ImageFile:      imageFileData,
+		ShortAudioFile: shortAudio,
+		FullAudioFiles: fullAudioFileData,
+		Geofence:       geofence,
+	}
+
+	createdPOI, err := h.poiService.CreateUploadedPOI(r.Context(), uploads, poiRequest)
+	if errors.Is(err, service.ErrInvalidPOI) ||
+		errors.Is(err, service.ErrInvalidGeofence) ||
+		errors.Is(err, repository.ErrInvalidGeofence) {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to create point of interest: "+err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, Response{Data: createdPOI})
+}
+
+// ImportPOIs godoc
+// @Tags POI
+// @Summary Массовый импорт точек интереса из GeoJSON
+// @Description Создает точки интереса из FeatureCollection. Свойства объекта: name, description, interests,
+// @Description image, short_audio, full_audio, где файлы указываются по имени внутри zip архива media.
+// @Description Каждая точка сохраняется отдельно, ошибки по точкам возвращаются в отчете.
+// @Accept multipart/form-data
+// @Param geojson formData file true "GeoJSON FeatureCollection"
+// @Param media formData file false "Zip архив с изображениями и аудио"
+// @Param dry_run formData boolean false "Только проверить, ничего не сохраняя" default(false)
+// @Success 200 {object} domain.ImportReport
+// @Failure 400 {object} Response
+// @Failure 500 {object} Response
+// @Security BearerAuth
+// @Router /api/poi/import [post]
+func (h *POIHandler) ImportPOIs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	err := r.ParseMultipartForm(32 << 20)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Failed to parse form data: "+err.Error())
 		return
 	}
 
-	for _, interest := range interests {
-		if interest != "nature" && interest != "architecture" && interest != "food" && interest != "history" {
-			h.writeError(w, http.StatusBadRequest,
-				fmt.Sprintf("Invalid value '%s'. Only 'nature', 'architecture', 'food', 'history' allowed", interest))
+	dryRun := false
+	if dryRunStr := r.FormValue("dry_run"); dryRunStr != "" {
+		dryRun, err = strconv.ParseBool(dryRunStr)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid dry_run format")
 			return
 		}
 	}
 
-	imageFile, imageHeader, err := r.FormFile("image")
+	geoJSONFile, _, err := r.FormFile("geojson")
 	if err != nil {
-		h.writeError(w, http.StatusBadRequest, "Image file is required: "+err.Error())
+		writeError(w, http.StatusBadRequest, "GeoJSON file is required: "+err.Error())
 		return
 	}
-	defer imageFile.Close()
+	defer geoJSONFile.Close()
 
-	poiRequest := &domain.PointOfInterest{
-		Name:        name,
-		Description: description,
-		Latitude:    latitude,
-		Longitude:   longitude,
-		Interests:   interests,
-		CreatedAt:   time.Now(),
-	}
-
-	var shortAudioFile multipart.File
-	var shortAudioHeader *multipart.FileHeader
-	shortAudioFile, shortAudioHeader, err = r.FormFile("short_audio")
-	if err == nil && shortAudioHeader != nil {
-		defer shortAudioFile.Close()
-
-		shortAudio := &domain.File{
-			FileName:     shortAudioHeader.Filename,
-			FileSize:     shortAudioHeader.Size,
-			MimeType:     shortAudioHeader.Header.Get("Content-Type"),
-			IsShort:      true,
-			SerialNumber: 1,
-			CreatedAt:    time.Now(),
+	var media service.MediaSource
+	mediaFile, mediaHeader, err := r.FormFile("media")
+	if err == nil {
+		defer mediaFile.Close()
+
+		media, err = service.NewZipMediaSource(mediaFile, mediaHeader.Size)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
 		}
-		poiRequest.ShortAudioFile = shortAudio
 	}
 
-	fullAudioFiles := make([]multipart.File, 0)
-	fullAudioFileData := make([]*domain.File, 0)
+	report, err := h.poiService.ImportPOIs(r.Context(), geoJSONFile, media, dryRun)
+	if errors.Is(err, service.ErrInvalidImport) {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to import points of interest: "+err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{Data: report})
+}
+
+// HandlePOI routes requests to a single POI by method.
+func (h *POIHandler) HandlePOI(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetPOI(w, r)
+	case http.MethodPatch:
+		h.UpdatePOI(w, r)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// GetPOI godoc
+// @Tags POI
+// @Summary Получение точки интереса по id
+// @Description Возвращает точку интереса со всеми файлами. Язык названия, описания и аудио выбирается
+// @Description по параметру lang или заголовку Accept-Language. Если перевода или аудио на нужном языке нет,
+// @Description используется русский
+// @Param id path int true "Id точки интереса" example(195)
+// @Param lang query string false "Предпочитаемые языки через запятую (ISO 639)" example(en)
+// @Param Accept-Language header string false "Предпочитаемые языки, если не передан lang" example(en-GB)
+// @Success 200 {object} domain.PointOfInterest
+// @Failure 400 {object} Response
+// @Failure 404 {object} Response
+// @Router /api/poi/{id} [get]
+func (h *POIHandler) GetPOI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	idPOI, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid id format")
+		return
+	}
+
+	languages, err := preferredLanguages(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	w.Header().Set("Vary", "Accept-Language")
+
+	poi, err := h.poiService.GetPOI(r.Context(), idPOI, languages)
+	if errors.Is(err, repository.ErrPOINotFound) {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to get point of interest: "+err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{Data: poi})
+}
+
+// ListPOIs godoc
+// @Tags POI
+// @Summary Список точек интереса
+// @Description Возвращает каталог точек интереса от новых к старым с постраничной навигацией по курсору
+// @Param interests query []string false "Интересы точки (id из /api/interests)" CollectionFormat(multi)
+// @Param created_from query string false "Создана не раньше (RFC3339)" example(2025-01-01T00:00:00Z)
+// @Param created_to query string false "Создана не позже (RFC3339)" example(2025-12-31T23:59:59Z)
+// @Param name query string false "Подстрока названия" example(плотинка)
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Param limit query int false "Размер страницы (от 1 до 100)" default(20)
+// @Success 200 {object} domain.POIPage
+// @Failure 400 {object} Response
+// @Failure 500 {object} Response
+// @Router /api/poi [get]
+func (h *POIHandler) ListPOIs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	query := r.URL.Query()
+
+	filter := domain.POIListFilter{
+		Interests: query["interests"],
+		Name:      query.Get("name"),
+	}
+
+	if err := h.interestService.ValidateInterests(r.Context(), filter.Interests); err != nil {
+		writeInterestsError(w, err)
+		return
+	}
+
+	if createdFromStr := query.Get("created_from"); createdFromStr != "" {
+		createdFrom, err := time.Parse(time.RFC3339, createdFromStr)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid created_from format, RFC3339 expected")
+			return
+		}
+		filter.CreatedFrom = &createdFrom
+	}
+
+	if createdToStr := query.Get("created_to"); createdToStr != "" {
+		createdTo, err := time.Parse(time.RFC3339, createdToStr)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid created_to format, RFC3339 expected")
+			return
+		}
+		filter.CreatedTo = &createdTo
+	}
+
+	limit := 20
+	if limitStr := query.Get("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "The limit must be a number")
+			return
+		}
+	}
+
+	page, err := h.poiService.ListPOIs(r.Context(), filter, query.Get("cursor"), limit)
+	if err != nil {
+		writePOIQueryError(w, err, "Failed to list points of interest: ")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{Data: page})
+}
+
+// FindPOIsInBoundingBox godoc
+// @Tags POI
+// @Summary Точки интереса в прямоугольнике карты
+// @Description Возвращает точки интереса внутри видимой области карты, от новых к старым.
+// @Description truncated = true, если точек больше, чем limit
+// @Param bbox query string true "Ограничивающий прямоугольник minLon,minLat,maxLon,maxLat" example(60.5,56.8,60.7,56.9)
+// @Param interests query []string false "Интересы точки (id из /api/interests)" CollectionFormat(multi)
+// @Param limit query int false "Максимум точек (от 1 до 2000)" default(500)
+// @Success 200 {object} domain.AreaResult
+// @Failure 400 {object} Response
+// @Failure 500 {object} Response
+// @Router /api/poi/bbox [get]
+func (h *POIHandler) FindPOIsInBoundingBox(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	query := r.URL.Query()
+
+	bboxStr := query.Get("bbox")
+	if bboxStr == "" {
+		writeError(w, http.StatusBadRequest, "Missing required parameter: bbox")
+		return
+	}
+
+	bbox, err := parseBoundingBox(bboxStr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	areaQuery := domain.AreaQuery{
+		BoundingBox: bbox,
+		Interests:   query["interests"],
+	}
+
+	if err := h.interestService.ValidateInterests(r.Context(), areaQuery.Interests); err != nil {
+		writeInterestsError(w, err)
+		return
+	}
 
-	if r.MultipartForm != nil && r.MultipartForm.File != nil {
-		if files, ok := r.MultipartForm.File["full_audio"]; ok {
-			for i, fileHeader := range files {
-				file, err := fileHeader.Open()
-				if err != nil {
-					logger.Error.Printf("Failed to open full audio file %s: %v\n", fileHeader.Filename, err)
-					continue
-				}
-
-				fullAudio := &domain.File{
-					FileName:     fileHeader.Filename,
-					FileSize:     fileHeader.Size,
-					MimeType:     fileHeader.Header.Get("Content-Type"),
-					IsShort:      false,
-					SerialNumber: int64(i + 1),
-					CreatedAt:    time.Now(),
-				}
-
-				fullAudioFiles = append(fullAudioFiles, file)
-				fullAudioFileData = append(fullAudioFileData, fullAudio)
+	if limitStr := query.Get("limit"); limitStr != "" {
+		areaQuery.Limit, err = strconv.Atoi(limitStr)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "The limit must be a number")
+			return
+		}
+	}
+
+	result, err := h.poiService.FindPOIsInArea(r.Context(), areaQuery)
+	if err != nil {
+		writePOIQueryError(w, err, "Failed to find points of interest: ")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{Data: result})
+}
+
+// ClusterPOIs godoc
+// @Tags POI
+// @Summary Кластеры точек интереса для карты
+// @Description Группирует точки интереса видимой области карты в кластеры по сетке, зависящей от масштаба:
+// @Description центр, число точек, число точек по интересам и границы кластера. Для кластера из одной точки
+// @Description возвращается poi_id. Начиная с масштаба 16 возвращаются сами точки в pois
+// @Param bbox query string true "Ограничивающий прямоугольник minLon,minLat,maxLon,maxLat" example(60.5,56.8,60.7,56.9)
+// @Param zoom query int true "Масштаб карты (от 0 до 22)" example(12)
+// @Param interests query []string false "Интересы точки (id из /api/interests)" CollectionFormat(multi)
+// @Success 200 {object} domain.ClusterResult
+// @Failure 400 {object} Response
+// @Failure 500 {object} Response
+// @Router /api/poi/clusters [get]
+func (h *POIHandler) ClusterPOIs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	query := r.URL.Query()
+
+	bboxStr := query.Get("bbox")
+	zoomStr := query.Get("zoom")
+	if bboxStr == "" || zoomStr == "" {
+		writeError(w, http.StatusBadRequest, "Missing required parameters: bbox, zoom")
+		return
+	}
+
+	bbox, err := parseBoundingBox(bboxStr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	zoom, err := strconv.Atoi(zoomStr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "The zoom must be a number")
+		return
+	}
+
+	interests := query["interests"]
+	if err := h.interestService.ValidateInterests(r.Context(), interests); err != nil {
+		writeInterestsError(w, err)
+		return
+	}
+
+	result, err := h.poiService.ClusterPOIs(r.Context(), *bbox, zoom, interests)
+	if err != nil {
+		writePOIQueryError(w, err, "Failed to cluster points of interest: ")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{Data: result})
+}
+
+// PolygonQueryRequest is the body of a search for POIs inside a polygon.
+type PolygonQueryRequest struct {
+	// Polygon is a GeoJSON Polygon or MultiPolygon geometry.
+	Polygon   json.RawMessage `json:"polygon" swaggertype:"object"`
+	Interests []string        `json:"interests,omitempty" example:"history,nature"`
+	Limit     int             `json:"limit,omitempty" example:"500"`
+}
+
+// FindPOIsInPolygon godoc
+// @Tags POI
+// @Summary Точки интереса внутри полигона
+// @Description Возвращает точки интереса внутри GeoJSON Polygon или MultiPolygon, от новых к старым.
+// @Description truncated = true, если точек больше, чем limit (по умолчанию 500, максимум 2000)
+// @Accept json
+// @Param request body PolygonQueryRequest true "Полигон и фильтры"
+// @Success 200 {object} domain.AreaResult
+// @Failure 400 {object} Response
+// @Failure 500 {object} Response
+// @Router /api/poi/polygon [post]
+func (h *POIHandler) FindPOIsInPolygon(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var request PolygonQueryRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+	if len(request.Polygon) == 0 || string(request.Polygon) == "null" {
+		writeError(w, http.StatusBadRequest, "Missing required field: polygon")
+		return
+	}
+
+	if err := h.interestService.ValidateInterests(r.Context(), request.Interests); err != nil {
+		writeInterestsError(w, err)
+		return
+	}
+
+	result, err := h.poiService.FindPOIsInArea(r.Context(), domain.AreaQuery{
+		Polygon:   request.Polygon,
+		Interests: request.Interests,
+		Limit:     request.Limit,
+	})
+	if err != nil {
+		writePOIQueryError(w, err, "Failed to find points of interest: ")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{Data: result})
+}
+
+// RouteQueryRequest is the body of a search for POIs along a route. The
+// route is given either as an encoded polyline or as a GeoJSON LineString.
+type RouteQueryRequest struct {
+	// Polyline is the route in the encoded polyline format with five decimal places.
+	Polyline string `json:"polyline,omitempty" example:"_p~iF~ps|U_ulLnnqC"`
+	// Line is a GeoJSON LineString geometry.
+	Line json.RawMessage `json:"line,omitempty" swaggertype:"object"`
+	// Width is the distance from the route in meters.
+	Width     float64  `json:"width" example:"300"`
+	Interests []string `json:"interests,omitempty" example:"history,nature"`
+	Limit     int      `json:"limit,omitempty" example:"500"`
+}
+
+// FindPOIsAlongRoute godoc
+// @Tags POI
+// @Summary Точки интереса вдоль маршрута
+// @Description Возвращает точки интереса не дальше width метров (до 5000) от маршрута в порядке их прохождения.
+// @Description Маршрут передается либо в polyline (encoded polyline, 5 знаков после запятой), либо в line (GeoJSON LineString).
+// @Description У каждой точки along_track_meters - расстояние от начала маршрута, distance_meters - расстояние до маршрута.
+// @Description truncated = true, если точек больше, чем limit (по умолчанию 500, максимум 2000)
+// @Accept json
+// @Param request body RouteQueryRequest true "Маршрут и фильтры"
+// @Success 200 {object} domain.AreaResult
+// @Failure 400 {object} Response
+// @Failure 500 {object} Response
+// @Router /api/poi/route [post]
+func (h *POIHandler) FindPOIsAlongRoute(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var request RouteQueryRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	hasLine := len(request.Line) > 0 && string(request.Line) != "null"
+	if (request.Polyline != "") == hasLine {
+		writeError(w, http.StatusBadRequest, "Exactly one of polyline and line is required")
+		return
+	}
+
+	var line [][]float64
+	var err error
+	if hasLine {
+		line, err = service.ParseLineString(request.Line)
+	} else {
+		line, err = service.DecodePolyline(request.Polyline)
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.interestService.ValidateInterests(r.Context(), request.Interests); err != nil {
+		writeInterestsError(w, err)
+		return
+	}
+
+	result, err := h.poiService.FindPOIsAlongRoute(r.Context(), domain.CorridorQuery{
+		Line:      line,
+		Width:     request.Width,
+		Interests: request.Interests,
+		Limit:     request.Limit,
+	})
+	if err != nil {
+		writePOIQueryError(w, err, "Failed to find points of interest: ")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{Data: result})
+}
+
+// UpdatePOI godoc
+// @Tags POI
+// @Summary Частичное изменение точки интереса
+// @Description Изменяет только переданные поля точки интереса. Новые изображение и короткое аудио заменяют старые,
+// @Description новые полные аудио добавляются в конец. Старые файлы удаляются из S3 после сохранения изменений.
+// @Description Файлы передаются в S3 потоком по мере чтения формы, изображение до 10 МБ, аудио до 50 МБ, вся форма до 1 ГБ и 256 полей.
+// @Accept multipart/form-data
+// @Param id path int true "Id точки интереса" example(195)
+// @Param name formData string false "Название точки интереса"
+// @Param description formData string false "Описание точки интереса"
+// @Param latitude formData number false "Широта (вместе с долготой)"
+// @Param longitude formData number false "Долгота (вместе с широтой)"
+// @Param interests formData []string false "Новый набор интересов точки (id из /api/interests)" CollectionFormat(multi)
+// @Param trigger_radius formData number false "Новый радиус срабатывания в метрах (до 5000), пустое значение сбрасывает область срабатывания"
+// @Param geofence formData string false "Новая область срабатывания, GeoJSON Polygon или MultiPolygon без самопересечений, не дальше 5000 м от точки, пустое значение сбрасывает ее"
+// @Param image formData file false "Новое изображение"
+// @Param short_audio formData file false "Новое короткое аудио"
+// @Param full_audio formData []file false "Полные аудио файлы, добавляемые в конец"
+// @Param remove_full_audio formData []int false "Id удаляемых полных аудио файлов" CollectionFormat(multi)
+// @Param full_audio_order formData []int false "Id всех оставшихся полных аудио файлов в новом порядке" CollectionFormat(multi)
+// @Param audio_language formData string false "Язык изменяемых аудио (ISO 639), по умолчанию ru. Аудио других языков не затрагиваются" example(en)
+// @Success 200 {object} domain.PointOfInterest
+// @Failure 400 {object} Response
+// @Failure 404 {object} Response
+// @Failure 413 {object} Response
+// @Failure 415 {object} Response
+// @Failure 500 {object} Response
+// @Security BearerAuth
+// @Router /api/poi/{id} [patch]
+func (h *POIHandler) UpdatePOI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPatch {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	idPOI, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid id format")
+		return
+	}
+
+	update := &domain.POIUpdate{}
+
+	// Files are streamed to S3 while the form is read and removed again
+	// unless the update is saved.
+	uploads := h.poiService.NewFileUploads(r.Context())
+	defer uploads.Discard()
+
+	form, err := streamMultipart(w, r, func(part *multipart.Part) error {
+		switch part.FormName() {
+		case "image":
+			if update.ImageFile != nil {
+				return fmt.Errorf("%w: only one image is allowed", errInvalidForm)
+			}
+			update.ImageFile = newPartFile(part, false, 0)
+			return uploads.UploadImage(part, update.ImageFile)
+		case "short_audio":
+			if update.ShortAudioFile != nil {
+				return fmt.Errorf("%w: only one short audio is allowed", errInvalidForm)
 			}
+			update.ShortAudioFile = newPartFile(part, true, 1)
+			return uploads.UploadAudio(part, update.ShortAudioFile)
+		case "full_audio":
+			fullAudio := newPartFile(part, false, 0)
+			update.NewFullAudioFiles = append(update.NewFullAudioFiles, fullAudio)
+			return uploads.UploadAudio(part, fullAudio)
+		default:
+			return nil
 		}
+	})
+	if err != nil {
+		writeUploadError(w, err, "Failed to update point of interest: ")
+		return
 	}
-	poiRequest.FullAudioFiles = fullAudioFileData
 
-	imageFileData := &domain.File{
-		FileName:     imageHeader.Filename,
-		FileSize:     imageHeader.Size,
-		MimeType:     imageHeader.Header.Get("Content-Type"),
-		IsShort:      false,
-		SerialNumber: 0,
-		CreatedAt:    time.Now(),
+	if values, ok := form["name"]; ok && len(values) > 0 {
+		update.Name = &values[0]
+	}
+	if values, ok := form["description"]; ok && len(values) > 0 {
+		update.Description = &values[0]
 	}
 
-	createdPOI, err := h.poiService.CreatePOI(poiRequest, imageFileData, imageFile, shortAudioFile, fullAudioFiles)
+	latStr := form.Get("latitude")
+	lngStr := form.Get("longitude")
+	if latStr != "" || lngStr != "" {
+		if latStr == "" || lngStr == "" {
+			writeError(w, http.StatusBadRequest, "Fields latitude and longitude must be passed together")
+			return
+		}
+
+		latitude, err := strconv.ParseFloat(latStr, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid latitude format")
+			return
+		}
+
+		longitude, err := strconv.ParseFloat(lngStr, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid longitude format")
+			return
+		}
+
+		update.Latitude = &latitude
+		update.Longitude = &longitude
+	}
+
+	if values, ok := form["interests"]; ok {
+		interests := make([]string, 0, len(values))
+		for _, interest := range values {
+			if interest == "" {
+				continue
+			}
+			interests = append(interests, interest)
+		}
+		if err := h.interestService.ValidateInterests(r.Context(), interests); err != nil {
+			writeInterestsError(w, err)
+			return
+		}
+		update.Interests = interests
+	}
+
+	update.Geofence, err = parseGeofence(form)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	update.RemoveFullAudioIDs, err = parseIDs(form["remove_full_audio"])
 	if err != nil {
-		h.writeError(w, http.StatusInternalServerError, "Failed to create point of interest: "+err.Error())
+		writeError(w, http.StatusBadRequest, "Invalid remove_full_audio format")
 		return
 	}
 
-	h.writeJSON(w, http.StatusCreated, Response{Data: createdPOI})
+	update.FullAudioOrder, err = parseIDs(form["full_audio_order"])
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid full_audio_order format")
+		return
+	}
+
+	update.AudioLanguage, err = parseLanguage(form.Get("audio_language"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	updatedPOI, err := h.poiService.UpdatePOI(r.Context(), idPOI, update, uploads)
+	if errors.Is(err, repository.ErrPOINotFound) {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if errors.Is(err, service.ErrInvalidPOI) ||
+		errors.Is(err, service.ErrInvalidGeofence) ||
+		errors.Is(err, repository.ErrInvalidGeofence) ||
+		errors.Is(err, repository.ErrInvalidAudioChange) {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to update point of interest: "+err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{Data: updatedPOI})
 }
 
 // FindNearestPOI godoc
 // @Tags POI
 // @Summary Поиск ближайшей точки интереса
-// @Description Возвращает ближайшие точки интереса по координатам
+// @Description Возвращает точку интереса с наибольшей оценкой score среди до 50 ближайших точек, в область
+// @Description срабатывания которых попадают координаты. Это не обязательно самая близкая точка.
+// @Description Областью срабатывания служит geofence точки, а для точек без него круг радиуса radius.
+// @Description Если передан limit, возвращает список из limit ближайших точек (с учетом offset).
+// @Description Если передано направление движения, ищет только точки впереди, с упреждением по скорости
+// @Description на время короткого аудио.
+// @Description С токеном пользователя без параметра interests используются интересы из его профиля.
+// @Description Точки упорядочены по оценке score: близость, совпадение с весами интересов, популярность
+// @Description по прослушиваниям, штраф за недавно прослушанные пользователем точки и бонус за новые.
+// @Description Части оценки возвращаются в score для отладки.
+// @Description Язык названия, описания и аудио выбирается по параметру lang или заголовку Accept-Language,
+// @Description а без них по языку профиля. Если перевода или аудио на нужном языке нет, используется русский.
+// @Description Пользователю с narration_length = short полные аудио не возвращаются, если у точки есть короткое
+// @Security BearerAuth
+// @Param lang query string false "Предпочитаемые языки через запятую (ISO 639)" example(en)
+// @Param Accept-Language header string false "Предпочитаемые языки, если не передан lang" example(en-GB)
 // @Param latitude query number true "Широта" example(55.7558)
 // @Param longitude query number true "Долгота" example(37.6173)
-// @Param radius query number false "Радиус в метрах" example(100) default(500)
-// @Param interests query []string false "Интересы точки" CollectionFormat(multi) Enums(nature, architecture, food, history) example(["nature", "architecture"])
+// @Param radius query number false "Радиус срабатывания в метрах для точек без своего geofence" example(100) default(500)
+// @Param interests query []string false "Интересы точки (id из /api/interests)" CollectionFormat(multi) example(["nature", "architecture"])
+// @Param limit query int false "Количество ближайших точек (от 1 до 50)" example(5)
+// @Param offset query int false "Сколько ближайших точек пропустить" example(0) default(0)
+// @Param heading query number false "Направление движения в градусах по часовой стрелке от севера" example(90)
+// @Param speed query number false "Скорость движения в м/с" example(16.7)
 // @Success 200 {object} domain.PointOfInterest
+// @Failure 400 {object} Response
+// @Failure 404 {object} Response
+// @Failure 500 {object} Response
 // @Router /api/poi/nearby [get]
 func (h *POIHandler) FindNearestPOI(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -194,54 +813,150 @@ func (h *POIHandler) FindNearestPOI(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	w.Header().Set("Vary", "Accept-Language")
+
 	query := r.URL.Query()
 
 	latStr := query.Get("latitude")
 	lngStr := query.Get("longitude")
 	radiusStr := query.Get("radius")
-	interests := query["interests"]
+	interests, explicit := query["interests"]
+
+	languages, err := preferredLanguages(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
-	for _, interest := range interests {
-		if interest != "nature" && interest != "architecture" && interest != "food" && interest != "history" {
-			h.writeError(w, http.StatusBadRequest,
-				fmt.Sprintf("Invalid value '%s'. Only 'nature', 'architecture', 'food', 'history' allowed", interest))
+	profile, err := h.userService.CurrentProfile(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to get user profile: "+err.Error())
+		return
+	}
+
+	// Interests passed in the query weigh the same, the ones of the profile
+	// keep their weights.
+	var weights []domain.InterestWeight
+	if explicit {
+		if err := h.interestService.ValidateInterests(r.Context(), interests); err != nil {
+			writeInterestsError(w, err)
 			return
 		}
+		for _, interest := range interests {
+			weights = append(weights, domain.InterestWeight{Interest: interest, Weight: 1})
+		}
+	} else if profile != nil {
+		interests = profile.InterestIDs()
+		weights = profile.Interests
 	}
 
-	if radiusStr == "" {
-		radiusStr = "500"
+	if len(languages) == 0 && profile != nil {
+		if language := service.NormalizeLanguage(profile.Language); language != "" {
+			languages = []string{language}
+		}
 	}
-	radius, err := strconv.Atoi(radiusStr)
-	if err != nil {
-		h.writeError(w, http.StatusBadRequest, "The radius must be a number")
-		return
+
+	var radius int
+	if radiusStr != "" {
+		radius, err = strconv.Atoi(radiusStr)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "The radius must be a number")
+			return
+		}
 	}
 
 	if latStr == "" || lngStr == "" {
-		h.writeError(w, http.StatusBadRequest, "Parameters 'latitude' and 'longitude' are required")
+		writeError(w, http.StatusBadRequest, "Parameters 'latitude' and 'longitude' are required")
 		return
 	}
 
 	lat, err := strconv.ParseFloat(latStr, 64)
 	if err != nil {
-		h.writeError(w, http.StatusBadRequest, "Invalid latitude format")
+		writeError(w, http.StatusBadRequest, "Invalid latitude format")
 		return
 	}
 
 	lng, err := strconv.ParseFloat(lngStr, 64)
 	if err != nil {
-		h.writeError(w, http.StatusBadRequest, "Invalid longitude format")
+		writeError(w, http.StatusBadRequest, "Invalid longitude format")
+		return
+	}
+
+	nearbyQuery := domain.NearbyQuery{
+		Latitude:  lat,
+		Longitude: lng,
+		Radius:    radius,
+		Interests: interests,
+	}
+
+	if headingStr := query.Get("heading"); headingStr != "" {
+		heading, err := strconv.ParseFloat(headingStr, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid heading format")
+			return
+		}
+		nearbyQuery.Heading = &heading
+	}
+
+	if speedStr := query.Get("speed"); speedStr != "" {
+		speed, err := strconv.ParseFloat(speedStr, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid speed format")
+			return
+		}
+		nearbyQuery.Speed = &speed
+	}
+
+	if query.Has("limit") {
+		nearbyQuery.Limit, err = strconv.Atoi(query.Get("limit"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "The limit must be a number")
+			return
+		}
+
+		if offsetStr := query.Get("offset"); offsetStr != "" {
+			nearbyQuery.Offset, err = strconv.Atoi(offsetStr)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "The offset must be a number")
+				return
+			}
+		}
+
+		pois, err := h.poiService.RankNearestPOIs(r.Context(), nearbyQuery, weights, languages)
+		if err != nil {
+			writePOIQueryError(w, err, "Failed to find points of interest: ")
+			return
+		}
+		applyNarrationLength(pois, profile)
+
+		writeJSON(w, http.StatusOK, Response{Data: pois})
 		return
 	}
 
-	poi, err := h.poiService.FindNearestPOI(lat, lng, radius, interests)
+	nearbyQuery.Limit = 1
+	pois, err := h.poiService.RankNearestPOIs(r.Context(), nearbyQuery, weights, languages)
 	if err != nil {
-		h.writeError(w, http.StatusNotFound, err.Error())
+		writePOIQueryError(w, err, "Failed to find points of interest: ")
 		return
 	}
+	if len(pois) == 0 {
+		writeError(w, http.StatusNotFound, "no points of interest found")
+		return
+	}
+	applyNarrationLength(pois, profile)
+
+	writeJSON(w, http.StatusOK, Response{Data: pois[0]})
+}
 
-	h.writeJSON(w, http.StatusOK, Response{Data: poi})
+// applyNarrationLength leaves the POIs only the audio the profile of the
+// listener asks for. Anonymous listeners get all of it.
+func applyNarrationLength(pois []*domain.PointOfInterest, profile *domain.UserProfile) {
+	if profile == nil {
+		return
+	}
+	for _, poi := range pois {
+		poi.ApplyNarrationLength(profile.NarrationLength)
+	}
 }
 
 // DeletePOI godoc
@@ -250,10 +965,11 @@ func (h *POIHandler) FindNearestPOI(w http.ResponseWriter, r *http.Request) {
 // @Description Удаляет точку интереса со всеми связанными файлами
 // @Param id query number true "Id точки интереса" example(195)
 // @Success 200 {boolean} true "Успешное выполнение"
+// @Security BearerAuth
 // @Router /api/poi/delete [delete]
 func (h *POIHandler) DeletePOI(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodDelete {
-		h.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
 		return
 	}
 
@@ -261,22 +977,22 @@ func (h *POIHandler) DeletePOI(w http.ResponseWriter, r *http.Request) {
 
 	idPOIStr := query.Get("id")
 	if idPOIStr == "" {
-		h.writeError(w, http.StatusBadRequest, "Missing required field: id")
+		writeError(w, http.StatusBadRequest, "Missing required field: id")
 		return
 	}
 	idPOI, err := strconv.Atoi(idPOIStr)
 	if err != nil {
-		h.writeError(w, http.StatusBadRequest, "Invalid id format")
+		writeError(w, http.StatusBadRequest, "Invalid id format")
 		return
 	}
 
-	resultDelete, err := h.poiService.DeletePOI(idPOI)
+	resultDelete, err := h.poiService.DeletePOI(r.Context(), idPOI)
 	if err != nil {
-		h.writeError(w, http.StatusNotFound, err.Error())
+		writeError(w, http.StatusNotFound, err.Error())
 		return
 	}
 
-	h.writeJSON(w, http.StatusOK, Response{Data: resultDelete})
+	writeJSON(w, http.StatusOK, Response{Data: resultDelete})
 }
 
 // HealthCheck godoc
@@ -291,7 +1007,17 @@ func (h *POIHandler) HealthCheck(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	h.writeJSON(w, http.StatusOK, Response{Data: map[string]string{
+	writeJSON(w, http.StatusOK, Response{Data: map[string]string{
 		"status": "OK",
 	}})
 }
+
+// writePOIQueryError answers a failed POI search: invalid parameters are
+// the client's mistake, anything else is ours.
+func writePOIQueryError(w http.ResponseWriter, err error, message string) {
+	if errors.Is(err, service.ErrInvalidPOIQuery) {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeError(w, http.StatusInternalServerError, message+err.Error())
+}
@@ -6,10 +6,13 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
+	"net/textproto"
 	"net/url"
 	"path/filepath"
 	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -61,11 +64,22 @@ func NewS3Proxy(cfg *config.Config) (*S3Proxy, error) {
 // GetFile godoc
 // @Tags S3
 // @Summary Получить файл из S3
-// @Description Скачивает файл из S3 хранилища по указанному пути
+// @Description Скачивает файл из S3 хранилища по указанному пути.
+// @Description Поддерживает запросы диапазонов байт (Range, If-Range), в том числе нескольких диапазонов
+// @Description (multipart/byteranges), и условные запросы по ETag и Last-Modified (If-None-Match, If-Modified-Since)
 // @Param path path string true "Путь к файлу в S3" example("images/photo.jpg")
+// @Param Range header string false "Диапазон байт" example(bytes=0-1023)
+// @Param If-None-Match header string false "ETag имеющейся у клиента версии файла"
+// @Param If-Modified-Since header string false "Дата имеющейся у клиента версии файла"
 // @Success 200 {file} byte "Файл"
+// @Success 206 {file} byte "Запрошенный диапазон файла"
+// @Success 304 "Файл не изменился"
+// @Failure 404 "Файл не найден"
+// @Failure 416 "Диапазон вне файла"
 // @Header 200 {string} Content-Type "MIME-тип файла"
 // @Header 200 {string} Content-Length "Размер файла в байтах"
+// @Header 200 {string} ETag "ETag файла"
+// @Header 206 {string} Content-Range "Отданный диапазон"
 // @Router /s3/files/{path} [get]
 func (p *S3Proxy) ProxyGet(w http.ResponseWriter, r *http.Request) {
 	// Исправляем путь - убираем "/s3/files/"
@@ -80,21 +94,66 @@ func (p *S3Proxy) ProxyGet(w http.ResponseWriter, r *http.Request) {
 		objectPath = decodedPath
 	}
 
-	logger.Info.Printf("=== S3 PROXY DEBUG ===")
-	logger.Info.Printf("Request path: %s", r.URL.Path)
-	logger.Info.Printf("Object path: %s", objectPath)
-	logger.Info.Printf("Bucket: %s", p.bucket)
+	ctx := r.Context()
 
-	ctx := context.Background()
-
-	_, err = p.client.StatObject(ctx, p.bucket, objectPath, minio.StatObjectOptions{})
+	objInfo, err := p.client.StatObject(ctx, p.bucket, objectPath, minio.StatObjectOptions{})
 	if err != nil {
 		logger.Error.Printf("File not found in S3: %s/%s, error: %v", p.bucket, objectPath, err)
 		http.Error(w, "File not found", http.StatusNotFound)
 		return
 	}
 
-	object, err := p.client.GetObject(ctx, p.bucket, objectPath, minio.GetObjectOptions{})
+	etag := quoteETag(objInfo.ETag)
+	contentType := getContentType(objectPath)
+
+	w.Header().Set("Accept-Ranges", "bytes")
+	w.Header().Set("Last-Modified", objInfo.LastModified.UTC().Format(http.TimeFormat))
+	w.Header().Set("ETag", etag)
+	if isStaticFile(objectPath) {
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+	}
+
+	if notModified(r, etag, objInfo.LastModified) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=\"%s\"", filepath.Base(objectPath)))
+
+	var ranges []byteRange
+	if rangeHeader := r.Header.Get("Range"); rangeHeader != "" && rangeApplies(r, etag, objInfo.LastModified) {
+		ranges, err = parseRange(rangeHeader, objInfo.Size)
+		if err != nil {
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", objInfo.Size))
+			http.Error(w, "Requested range not satisfiable", http.StatusRequestedRangeNotSatisfiable)
+			return
+		}
+	}
+
+	switch len(ranges) {
+	case 0:
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Length", strconv.FormatInt(objInfo.Size, 10))
+		p.serveObject(w, r, objectPath, nil, http.StatusOK)
+	case 1:
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Length", strconv.FormatInt(ranges[0].length(), 10))
+		w.Header().Set("Content-Range", ranges[0].contentRange(objInfo.Size))
+		p.serveObject(w, r, objectPath, &ranges[0], http.StatusPartialContent)
+	default:
+		p.serveRanges(w, r, objectPath, contentType, objInfo.Size, ranges)
+	}
+}
+
+// serveObject streams the object, or one range of it, with the given status.
+// Headers must already be set.
+func (p *S3Proxy) serveObject(w http.ResponseWriter, r *http.Request, objectPath string, br *byteRange, status int) {
+	if r.Method == http.MethodHead {
+		w.WriteHeader(status)
+		return
+	}
+
+	object, err := p.getObject(r.Context(), objectPath, br)
 	if err != nil {
 		logger.Error.Printf("Error getting object: %v", err)
 		http.Error(w, fmt.Sprintf("Failed to get object: %v", err), http.StatusInternalServerError)
@@ -102,33 +161,99 @@ func (p *S3Proxy) ProxyGet(w http.ResponseWriter, r *http.Request) {
 	}
 	defer object.Close()
 
-	objInfo, err := object.Stat()
-	if err != nil {
-		logger.Error.Printf("Error stating object: %v", err)
-		http.Error(w, "File not found", http.StatusNotFound)
+	// The request to S3 is only made on the first read or stat, so failures
+	// are caught here before the status is sent.
+	if _, err := object.Stat(); err != nil {
+		logger.Error.Printf("Error getting object: %v", err)
+		http.Error(w, fmt.Sprintf("Failed to get object: %v", err), http.StatusInternalServerError)
 		return
 	}
 
-	logger.Info.Printf("File found: %s, size: %d bytes", objectPath, objInfo.Size)
+	w.WriteHeader(status)
+	if _, err := io.Copy(w, object); err != nil {
+		logger.Error.Printf("Error streaming object %s: %v", objectPath, err)
+	}
+}
 
-	w.Header().Set("Content-Type", getContentType(objectPath))
-	w.Header().Set("Content-Length", fmt.Sprintf("%d", objInfo.Size))
-	w.Header().Set("Last-Modified", objInfo.LastModified.Format(time.RFC1123))
-	w.Header().Set("ETag", objInfo.ETag)
-	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=\"%s\"", filepath.Base(objectPath)))
+// serveRanges answers a request for several ranges with a
+// multipart/byteranges body, fetching each range separately.
+func (p *S3Proxy) serveRanges(
+	w http.ResponseWriter,
+	r *http.Request,
+	objectPath string,
+	contentType string,
+	size int64,
+	ranges []byteRange,
+) {
+	partHeader := func(br byteRange) textproto.MIMEHeader {
+		return textproto.MIMEHeader{
+			"Content-Type":  {contentType},
+			"Content-Range": {br.contentRange(size)},
+		}
+	}
 
-	if isStaticFile(objectPath) {
-		w.Header().Set("Cache-Control", "public, max-age=3600")
+	// The length is counted by writing the part headers without bodies.
+	counter := &countingWriter{}
+	lengthWriter := multipart.NewWriter(counter)
+	var length int64
+	for _, br := range ranges {
+		lengthWriter.CreatePart(partHeader(br))
+		length += br.length()
 	}
+	lengthWriter.Close()
+	length += counter.n
 
-	_, err = io.Copy(w, object)
-	if err != nil {
-		logger.Error.Printf("Error streaming object: %v", err)
-		http.Error(w, "Failed to stream file", http.StatusInternalServerError)
+	w.Header().Set("Content-Type", "multipart/byteranges; boundary="+lengthWriter.Boundary())
+	w.Header().Set("Content-Length", strconv.FormatInt(length, 10))
+
+	if r.Method == http.MethodHead {
+		w.WriteHeader(http.StatusPartialContent)
 		return
 	}
 
-	logger.Info.Printf("File served successfully: %s (%d bytes)", objectPath, objInfo.Size)
+	w.WriteHeader(http.StatusPartialContent)
+
+	bodyWriter := multipart.NewWriter(w)
+	bodyWriter.SetBoundary(lengthWriter.Boundary())
+	for _, br := range ranges {
+		part, err := bodyWriter.CreatePart(partHeader(br))
+		if err != nil {
+			logger.Error.Printf("Error streaming object %s: %v", objectPath, err)
+			return
+		}
+
+		object, err := p.getObject(r.Context(), objectPath, &br)
+		if err != nil {
+			logger.Error.Printf("Error getting object: %v", err)
+			return
+		}
+		_, err = io.Copy(part, object)
+		object.Close()
+		if err != nil {
+			logger.Error.Printf("Error streaming object %s: %v", objectPath, err)
+			return
+		}
+	}
+	bodyWriter.Close()
+}
+
+func (p *S3Proxy) getObject(ctx context.Context, objectPath string, br *byteRange) (*minio.Object, error) {
+	options := minio.GetObjectOptions{}
+	if br != nil {
+		if err := options.SetRange(br.start, br.end); err != nil {
+			return nil, err
+		}
+	}
+	return p.client.GetObject(ctx, p.bucket, objectPath, options)
+}
+
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(data []byte) (int, error) {
+	w.n += int64(len(data))
+	return len(data), nil
 }
 
 // ListFiles godoc
@@ -137,6 +262,7 @@ func (p *S3Proxy) ProxyGet(w http.ResponseWriter, r *http.Request) {
 // @Description Возвращает список файлов в S3 бакете с возможностью фильтрации по префиксу
 // @Param prefix query string false "Префикс для фильтрации файлов" example("images/")
 // @Success 200 {object} domain.S3ListResponse
+// @Security BearerAuth
 // @Router /s3/list [get]
 func (p *S3Proxy) ListObjects(w http.ResponseWriter, r *http.Request) {
 	prefix := r.URL.Query().Get("prefix")
@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"aigpsservice/internal/domain"
+	"aigpsservice/internal/repository"
+	"aigpsservice/internal/service"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+type SessionHandler struct {
+	sessionService *service.SessionService
+}
+
+func NewSessionHandler(sessionService *service.SessionService) *SessionHandler {
+	return &SessionHandler{
+		sessionService: sessionService,
+	}
+}
+
+// StartSessionRequest is the body of a new listening session.
+type StartSessionRequest struct {
+	Interests []string `json:"interests,omitempty" example:"history,architecture"`
+	// Radius is the trigger radius of POIs without their own geofence.
+	Radius int `json:"radius,omitempty" example:"500"`
+	// CooldownSeconds is the pause after a story, -1 disables it.
+	CooldownSeconds int `json:"cooldown_seconds,omitempty" example:"30"`
+}
+
+// StartSession godoc
+// @Tags Sessions
+// @Summary Начало сессии прослушивания
+// @Description Создает сессию, в которой сервер запоминает прослушанные точки и аудио.
+// @Description radius - радиус срабатывания точек без своего geofence, по умолчанию 500 м (до 5000),
+// @Description cooldown_seconds - пауза после истории, по умолчанию 30 с, -1 без паузы
+// @Accept json
+// @Param request body StartSessionRequest true "Параметры сессии"
+// @Success 201 {object} domain.ListeningSession
+// @Failure 400 {object} Response
+// @Router /api/sessions [post]
+func (h *SessionHandler) StartSession(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var request StartSessionRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	session, err := h.sessionService.StartSession(r.Context(), &domain.ListeningSession{
+		Interests:       request.Interests,
+		Radius:          request.Radius,
+		CooldownSeconds: request.CooldownSeconds,
+	})
+	if err != nil {
+		writeSessionError(w, err, "Failed to start session: ")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, Response{Data: session})
+}
+
+// GetSession godoc
+// @Tags Sessions
+// @Summary Получение сессии прослушивания
+// @Description Возвращает сессию с последним местоположением и id прослушанных точек
+// @Param id path string true "Id сессии"
+// @Success 200 {object} domain.ListeningSession
+// @Failure 404 {object} Response
+// @Router /api/sessions/{id} [get]
+func (h *SessionHandler) GetSession(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	session, err := h.sessionService.GetSession(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeSessionError(w, err, "Failed to get session: ")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{Data: session})
+}
+
+// UpdateLocation godoc
+// @Tags Sessions
+// @Summary Обновление местоположения и следующая точка
+// @Description Запоминает местоположение и возвращает ближайшую непрослушанную точку, в geofence которой
+// @Description находится слушатель, с еще не проигранными аудио.
+// @Description Пока идет пауза после истории, точка не возвращается, а cooldown_seconds показывает оставшееся время.
+// @Description С направлением и скоростью точки ищутся впереди, как в /api/poi/nearby.
+// @Description Пользователю с narration_length = short полные аудио не возвращаются, если у точки есть короткое
+// @Accept json
+// @Param id path string true "Id сессии"
+// @Param location body domain.SessionLocation true "Местоположение"
+// @Success 200 {object} domain.SessionNext
+// @Failure 400 {object} Response
+// @Failure 404 {object} Response
+// @Router /api/sessions/{id}/location [post]
+func (h *SessionHandler) UpdateLocation(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var location domain.SessionLocation
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&location); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	next, err := h.sessionService.UpdateLocation(r.Context(), r.PathValue("id"), location)
+	if err != nil {
+		writeSessionError(w, err, "Failed to update location: ")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{Data: next})
+}
+
+// RecordPlay godoc
+// @Tags Sessions
+// @Summary Отметка прослушанного аудио
+// @Description Запоминает проигранные аудио точки и запускает паузу перед следующей историей.
+// @Description Точка считается прослушанной, когда проиграны все ее аудио, при completed = true или без file_ids
+// @Accept json
+// @Param id path string true "Id сессии"
+// @Param play body domain.SessionPlay true "Прослушанные аудио"
+// @Success 204
+// @Failure 400 {object} Response
+// @Failure 404 {object} Response
+// @Router /api/sessions/{id}/plays [post]
+func (h *SessionHandler) RecordPlay(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var play domain.SessionPlay
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&play); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	if err := h.sessionService.RecordPlay(r.Context(), r.PathValue("id"), &play); err != nil {
+		writeSessionError(w, err, "Failed to record play: ")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeSessionError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, repository.ErrSessionNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrInvalidSession),
+		errors.Is(err, repository.ErrSessionFileNotFound),
+		errors.Is(err, repository.ErrPOINotFound):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrUnknownInterest):
+		writeInterestsError(w, err)
+	default:
+		writeError(w, http.StatusInternalServerError, message+err.Error())
+	}
+}
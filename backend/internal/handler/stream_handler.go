@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"aigpsservice/internal/domain"
+	"aigpsservice/internal/service"
+	"aigpsservice/pkg/logger"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+const (
+	streamHeartbeat   = 20 * time.Second
+	streamIdleTimeout = 90 * time.Second
+)
+
+type StreamHandler struct {
+	streamService   *service.StreamService
+	interestService *service.InterestService
+}
+
+func NewStreamHandler(streamService *service.StreamService, interestService *service.InterestService) *StreamHandler {
+	return &StreamHandler{
+		streamService:   streamService,
+		interestService: interestService,
+	}
+}
+
+// StreamClientMessage is a message from the client. Any message keeps the
+// stream alive, only location fixes move the client.
+type StreamClientMessage struct {
+	Type      string  `json:"type" enums:"location,ping" example:"location"`
+	Latitude  float64 `json:"latitude" example:"55.7558"`
+	Longitude float64 `json:"longitude" example:"37.6173"`
+}
+
+// StreamServerMessage is a service message of the stream. POI events are
+// sent as domain.POIEvent.
+type StreamServerMessage struct {
+	Type             string    `json:"type" enums:"ready,heartbeat,error" example:"ready"`
+	ResumeToken      string    `json:"resume_token,omitempty"`
+	HeartbeatSeconds int       `json:"heartbeat_seconds,omitempty"`
+	Time             time.Time `json:"time,omitzero"`
+	Error            string    `json:"error,omitempty"`
+}
+
+// StreamPOIEvents godoc
+// @Tags Stream
+// @Summary Поток событий точек интереса (WebSocket)
+// @Description Открывает WebSocket, в который клиент отправляет {"type":"location","latitude":..,"longitude":..}.
+// @Description Сервер отвечает событиями entered при входе в geofence точки (для точек без него - ближе 50 м),
+// @Description approaching ближе 200 м к этой области и left при удалении от нее больше чем на 10 м после entered.
+// @Description Первым приходит {"type":"ready","resume_token":..}, затем heartbeat каждые 20 с. Клиент должен отправлять
+// @Description сообщения (location или ping) хотя бы раз в 90 с. После обрыва переподключение с resume_token
+// @Description в течение 5 минут продолжает поток без повторных событий. Число соединений одного пользователя или API ключа, для анонимных клиентов - одного адреса, ограничено
+// @Param interests query []string false "Интересы точки (id из /api/interests)" CollectionFormat(multi)
+// @Param resume_token query string false "Токен продолжения потока"
+// @Success 101 {object} domain.POIEvent
+// @Failure 400 {object} Response
+// @Failure 429 {object} Response
+// @Router /api/stream [get]
+func (h *StreamHandler) StreamPOIEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	query := r.URL.Query()
+	interests := query["interests"]
+	if err := h.interestService.ValidateInterests(r.Context(), interests); err != nil {
+		writeInterestsError(w, err)
+		return
+	}
+
+	release, err := h.streamService.Acquire(streamClient(r))
+	if err != nil {
+		if errors.Is(err, service.ErrTooManyStreams) {
+			writeError(w, http.StatusTooManyRequests, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "Failed to open stream: "+err.Error())
+		return
+	}
+	defer release()
+
+	// Unlike websocket.Handler, the server accepts clients of any origin, as
+	// the rest of the API does.
+	server := websocket.Server{Handler: func(ws *websocket.Conn) {
+		state := h.streamService.Resume(query.Get("resume_token"), interests)
+		defer h.streamService.Suspend(state)
+		h.serve(ws, state)
+	}}
+	server.ServeHTTP(w, r)
+}
+
+// serve pushes events and heartbeats to the client until it disconnects or
+// falls silent for streamIdleTimeout.
+func (h *StreamHandler) serve(ws *websocket.Conn, state *service.StreamState) {
+	defer ws.Close()
+	ws.MaxPayloadBytes = 1 << 16
+
+	messages := make(chan StreamClientMessage)
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		defer close(messages)
+		for {
+			var message StreamClientMessage
+			ws.SetReadDeadline(time.Now().Add(streamIdleTimeout))
+			if err := websocket.JSON.Receive(ws, &message); err != nil {
+				return
+			}
+			select {
+			case messages <- message:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	ready := StreamServerMessage{Type: "ready", ResumeToken: state.Token, HeartbeatSeconds: int(streamHeartbeat / time.Second)}
+	if err := websocket.JSON.Send(ws, ready); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case message, ok := <-messages:
+			if !ok {
+				return
+			}
+			if message.Type != "location" {
+				continue
+			}
+
+			events, err := h.streamService.Locate(ws.Request().Context(), state, message.Latitude, message.Longitude)
+			if err != nil {
+				if !errors.Is(err, service.ErrInvalidFix) {
+					logger.Error.Printf("Failed to locate stream %s: %v", state.Token, err)
+				}
+				if err := websocket.JSON.Send(ws, StreamServerMessage{Type: "error", Error: err.Error()}); err != nil {
+					return
+				}
+				continue
+			}
+			if err := sendEvents(ws, events); err != nil {
+				return
+			}
+
+		case now := <-heartbeat.C:
+			if err := websocket.JSON.Send(ws, StreamServerMessage{Type: "heartbeat", Time: now.UTC()}); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func sendEvents(ws *websocket.Conn, events []*domain.POIEvent) error {
+	for _, event := range events {
+		if err := websocket.JSON.Send(ws, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// streamClient identifies the client for the per-client stream cap: the
+// authenticated user or API key, the remote address for anonymous clients.
+// Clients behind one NAT share the cap only while they are anonymous.
+func streamClient(r *http.Request) string {
+	if principal := service.PrincipalFrom(r.Context()); principal != nil {
+		if principal.UserID != 0 {
+			return "user:" + strconv.FormatInt(principal.UserID, 10)
+		}
+		if principal.APIKeyID != 0 {
+			return "key:" + strconv.FormatInt(principal.APIKeyID, 10)
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "addr:" + host
+}
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"aigpsservice/internal/domain"
+	"aigpsservice/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStreamClient(t *testing.T) {
+	tests := []struct {
+		name       string
+		principal  *domain.Principal
+		remoteAddr string
+		want       string
+	}{
+		{name: "anonymous", remoteAddr: "203.0.113.7:51234", want: "addr:203.0.113.7"},
+		{name: "anonymous ipv6", remoteAddr: "[2001:db8::1]:443", want: "addr:2001:db8::1"},
+		{name: "address without port", remoteAddr: "203.0.113.7", want: "addr:203.0.113.7"},
+		{name: "user", principal: &domain.Principal{Role: domain.RoleListener, UserID: 42}, remoteAddr: "203.0.113.7:51234", want: "user:42"},
+		{name: "api key", principal: &domain.Principal{Role: domain.RoleEditor, APIKeyID: 5}, remoteAddr: "203.0.113.7:51234", want: "key:5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/stream", nil)
+			r.RemoteAddr = tt.remoteAddr
+			if tt.principal != nil {
+				r = r.WithContext(service.WithPrincipal(r.Context(), tt.principal))
+			}
+			if got := streamClient(r); got != tt.want {
+				t.Errorf("streamClient() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
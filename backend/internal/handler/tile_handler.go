@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"aigpsservice/internal/service"
+	"aigpsservice/pkg/logger"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type TileHandler struct {
+	tileService     *service.TileService
+	interestService *service.InterestService
+}
+
+func NewTileHandler(tileService *service.TileService, interestService *service.InterestService) *TileHandler {
+	return &TileHandler{
+		tileService:     tileService,
+		interestService: interestService,
+	}
+}
+
+// GetPOITile godoc
+// @Tags Tiles
+// @Summary Векторный тайл точек интереса
+// @Description Возвращает тайл Mapbox Vector Tile со слоем poi. Атрибуты объектов: id, name и interests
+// @Description (id интересов через запятую). ETag меняется при любом изменении точек или интересов,
+// @Description поэтому после max-age клиент перепроверяет тайл через If-None-Match
+// @Produce application/vnd.mapbox-vector-tile
+// @Param z path int true "Масштаб (от 0 до 22)" example(12)
+// @Param x path int true "Номер тайла по x" example(2737)
+// @Param y path string true "Номер тайла по y с расширением .mvt" example(1207.mvt)
+// @Param interests query []string false "Интересы точки (id из /api/interests)" CollectionFormat(multi)
+// @Success 200 {file} byte "Тайл"
+// @Success 304 "Тайл не изменился"
+// @Failure 400 {object} Response
+// @Failure 500 {object} Response
+// @Router /tiles/poi/{z}/{x}/{y} [get]
+func (h *TileHandler) GetPOITile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	yStr, ok := strings.CutSuffix(r.PathValue("yext"), ".mvt")
+	if !ok {
+		writeError(w, http.StatusNotFound, "Only .mvt tiles are served")
+		return
+	}
+
+	z, errZ := strconv.Atoi(r.PathValue("z"))
+	x, errX := strconv.Atoi(r.PathValue("x"))
+	y, errY := strconv.Atoi(yStr)
+	if errZ != nil || errX != nil || errY != nil {
+		writeError(w, http.StatusBadRequest, "Invalid tile coordinates")
+		return
+	}
+	// An unknown tile is rejected before the ETag is compared, so that it
+	// never passes as not modified.
+	if err := h.tileService.ValidateTile(z, x, y); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	interests := r.URL.Query()["interests"]
+	if err := h.interestService.ValidateInterests(r.Context(), interests); err != nil {
+		writeInterestsError(w, err)
+		return
+	}
+
+	version, err := h.tileService.CatalogVersion(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to get catalog version: "+err.Error())
+		return
+	}
+
+	// The interests are part of the URL, so the catalog version alone
+	// identifies the tile content.
+	etag := fmt.Sprintf(`"catalog-%d"`, version)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "public, max-age=60")
+	w.Header().Set("Vary", "Accept-Encoding")
+
+	if notModified(r, etag, time.Time{}) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	tile, err := h.tileService.POITile(r.Context(), z, x, y, interests)
+	if errors.Is(err, service.ErrInvalidTile) {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		logger.Error.Printf("Failed to render tile %d/%d/%d: %v", z, x, y, err)
+		writeError(w, http.StatusInternalServerError, "Failed to render tile: "+err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.mapbox-vector-tile")
+	w.Header().Set("Content-Length", strconv.Itoa(len(tile)))
+	w.WriteHeader(http.StatusOK)
+	if r.Method != http.MethodHead {
+		w.Write(tile)
+	}
+}
@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"aigpsservice/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetPOITileRejectsUnknownTiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		z, x, y string
+	}{
+		{name: "zoom too deep", z: "23", x: "0", y: "0"},
+		{name: "negative zoom", z: "-1", x: "0", y: "0"},
+		{name: "x past the zoom", z: "2", x: "4", y: "0"},
+		{name: "y past the zoom", z: "2", x: "0", y: "4"},
+		{name: "negative x", z: "2", x: "-1", y: "0"},
+	}
+
+	// The services are never reached for unknown tiles, so they have no
+	// repositories behind them.
+	h := NewTileHandler(service.NewTileService(nil, nil), nil)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/tiles/poi/"+tt.z+"/"+tt.x+"/"+tt.y+".mvt", nil)
+			r.SetPathValue("z", tt.z)
+			r.SetPathValue("x", tt.x)
+			r.SetPathValue("yext", tt.y+".mvt")
+			r.Header.Set("If-None-Match", "*")
+
+			w := httptest.NewRecorder()
+			h.GetPOITile(w, r)
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
@@ -0,0 +1,330 @@
+package handler
+
+import (
+	"aigpsservice/internal/domain"
+	"aigpsservice/internal/repository"
+	"aigpsservice/internal/service"
+	"aigpsservice/pkg/logger"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+)
+
+type TourHandler struct {
+	tourService     *service.TourService
+	exportService   *service.ExportService
+	interestService *service.InterestService
+}
+
+func NewTourHandler(tourService *service.TourService, exportService *service.ExportService, interestService *service.InterestService) *TourHandler {
+	return &TourHandler{
+		tourService:     tourService,
+		exportService:   exportService,
+		interestService: interestService,
+	}
+}
+
+// HandleTours routes requests to the tour collection by method.
+func (h *TourHandler) HandleTours(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.ListTours(w, r)
+	case http.MethodPost:
+		h.CreateTour(w, r)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// CreateTour godoc
+// @Tags Tours
+// @Summary Создание тура
+// @Description Создает тур из точек интереса в порядке poi_ids. Обложка и аудио вступления и завершения необязательны,
+// @Description файлы передаются в S3 потоком по мере чтения формы, изображение до 10 МБ, аудио до 50 МБ, вся форма до 1 ГБ и 256 полей.
+// @Description Расстояние считается по прямым между точками, длительность - по скорости пешехода 4.5 км/ч
+// @Accept multipart/form-data
+// @Param name formData string true "Название тура"
+// @Param description formData string false "Описание тура"
+// @Param poi_ids formData []int true "Id точек интереса в порядке прохождения" CollectionFormat(multi)
+// @Param cover_image formData file false "Обложка"
+// @Param intro_audio formData file false "Аудио вступления"
+// @Param outro_audio formData file false "Аудио завершения"
+// @Success 201 {object} domain.Tour
+// @Failure 400 {object} Response
+// @Failure 413 {object} Response
+// @Failure 415 {object} Response
+// @Failure 500 {object} Response
+// @Security BearerAuth
+// @Router /api/tours [post]
+func (h *TourHandler) CreateTour(w http.ResponseWriter, r *http.Request) {
+	// Files are streamed to S3 while the form is read and removed again
+	// unless the tour is saved.
+	uploads := h.tourService.NewFileUploads(r.Context())
+	defer uploads.Discard()
+
+	var coverImage, introAudio, outroAudio *domain.File
+
+	form, err := streamMultipart(w, r, func(part *multipart.Part) error {
+		switch part.FormName() {
+		case "cover_image":
+			if coverImage != nil {
+				return fmt.Errorf("%w: only one cover image is allowed", errInvalidForm)
+			}
+			coverImage = newPartFile(part, false, 0)
+			return uploads.UploadImage(part, coverImage)
+		case "intro_audio":
+			if introAudio != nil {
+				return fmt.Errorf("%w: only one intro audio is allowed", errInvalidForm)
+			}
+			introAudio = newPartFile(part, false, 0)
+			return uploads.UploadAudio(part, introAudio)
+		case "outro_audio":
+			if outroAudio != nil {
+				return fmt.Errorf("%w: only one outro audio is allowed", errInvalidForm)
+			}
+			outroAudio = newPartFile(part, false, 0)
+			return uploads.UploadAudio(part, outroAudio)
+		default:
+			return nil
+		}
+	})
+	if err != nil {
+		writeUploadError(w, err, "Failed to create tour: ")
+		return
+	}
+
+	if form.Get("name") == "" {
+		writeError(w, http.StatusBadRequest, "Missing required field: name")
+		return
+	}
+
+	poiIDs, err := parseIDs(form["poi_ids"])
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid poi_ids: "+err.Error())
+		return
+	}
+
+	tour := &domain.Tour{
+		Name:        form.Get("name"),
+		Description: form.Get("description"),
+		POIIDs:      poiIDs,
+		CoverImage:  coverImage,
+		IntroAudio:  introAudio,
+		OutroAudio:  outroAudio,
+	}
+
+	created, err := h.tourService.CreateTour(r.Context(), uploads, tour)
+	if err != nil {
+		writeTourError(w, err, "Failed to create tour: ")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, Response{Data: created})
+}
+
+// ListTours godoc
+// @Tags Tours
+// @Summary Список туров
+// @Description Возвращает туры от новых к старым без подробностей о точках, с постраничной навигацией по курсору
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Param limit query int false "Размер страницы (от 1 до 100)" default(20)
+// @Success 200 {object} domain.TourPage
+// @Failure 400 {object} Response
+// @Router /api/tours [get]
+func (h *TourHandler) ListTours(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	limit := 20
+	if limitStr := query.Get("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "The limit must be a number")
+			return
+		}
+	}
+
+	page, err := h.tourService.ListTours(r.Context(), query.Get("cursor"), limit)
+	if err != nil {
+		writeTourError(w, err, "Failed to list tours: ")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{Data: page})
+}
+
+// GetTour godoc
+// @Tags Tours
+// @Summary Получение тура по id
+// @Description Возвращает тур с точками интереса в порядке прохождения
+// @Param id path int true "Id тура" example(1)
+// @Success 200 {object} domain.Tour
+// @Failure 400 {object} Response
+// @Failure 404 {object} Response
+// @Router /api/tours/{id} [get]
+func (h *TourHandler) GetTour(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid id format")
+		return
+	}
+
+	tour, err := h.tourService.GetTour(r.Context(), id)
+	if err != nil {
+		writeTourError(w, err, "Failed to get tour: ")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{Data: tour})
+}
+
+// TourPOIsRequest is the new sequence of tour stops.
+type TourPOIsRequest struct {
+	POIIDs []int64 `json:"poi_ids" example:"195,12,48"`
+}
+
+// SetTourPOIs godoc
+// @Tags Tours
+// @Summary Изменение порядка точек тура
+// @Description Заменяет последовательность точек тура. Так можно переставить, добавить или убрать точки
+// @Accept json
+// @Param id path int true "Id тура" example(1)
+// @Param request body TourPOIsRequest true "Id точек интереса в порядке прохождения"
+// @Success 200 {object} domain.Tour
+// @Failure 400 {object} Response
+// @Failure 404 {object} Response
+// @Security BearerAuth
+// @Router /api/tours/{id}/pois [put]
+func (h *TourHandler) SetTourPOIs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid id format")
+		return
+	}
+
+	var request TourPOIsRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	tour, err := h.tourService.SetTourPOIs(r.Context(), id, request.POIIDs)
+	if err != nil {
+		writeTourError(w, err, "Failed to update tour: ")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{Data: tour})
+}
+
+// PlanTour godoc
+// @Tags Tours
+// @Summary Автоматический пеший тур
+// @Description Подбирает точки интереса по интересам вокруг стартовой точки и упорядочивает их
+// @Description (ближайший сосед и 2-opt), чтобы ходьба и прослушивание историй уложились в budget минут.
+// @Description Пешее расстояние оценивается по прямой с коэффициентом 1.3, скорость 4.5 км/ч,
+// @Description длительность аудио - по размеру файлов. format=gpx возвращает точки и маршрут в GPX
+// @Param latitude query number true "Широта старта" example(56.8389)
+// @Param longitude query number true "Долгота старта" example(60.6057)
+// @Param budget query int true "Время на тур в минутах (от 10 до 480)" example(90)
+// @Param interests query []string false "Интересы точки (id из /api/interests)" CollectionFormat(multi)
+// @Param round_trip query boolean false "Вернуться в точку старта" default(false)
+// @Param format query string false "Формат ответа" Enums(json, gpx) default(json)
+// @Success 200 {object} domain.TourPlan
+// @Failure 400 {object} Response
+// @Failure 500 {object} Response
+// @Router /api/tours/plan [get]
+func (h *TourHandler) PlanTour(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	query := r.URL.Query()
+
+	format := query.Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != service.ExportFormatGPX {
+		writeError(w, http.StatusBadRequest, "Unsupported format: "+format)
+		return
+	}
+
+	latitude, errLat := strconv.ParseFloat(query.Get("latitude"), 64)
+	longitude, errLng := strconv.ParseFloat(query.Get("longitude"), 64)
+	if errLat != nil || errLng != nil {
+		writeError(w, http.StatusBadRequest, "Invalid or missing latitude and longitude")
+		return
+	}
+
+	budget, err := strconv.Atoi(query.Get("budget"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid or missing budget, minutes expected")
+		return
+	}
+
+	roundTrip := false
+	if roundTripStr := query.Get("round_trip"); roundTripStr != "" {
+		roundTrip, err = strconv.ParseBool(roundTripStr)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid round_trip format")
+			return
+		}
+	}
+
+	interests := query["interests"]
+	if err := h.interestService.ValidateInterests(r.Context(), interests); err != nil {
+		writeInterestsError(w, err)
+		return
+	}
+
+	plan, err := h.tourService.PlanTour(r.Context(), domain.TourPlanRequest{
+		Latitude:      latitude,
+		Longitude:     longitude,
+		BudgetSeconds: budget * 60,
+		Interests:     interests,
+		RoundTrip:     roundTrip,
+	})
+	if err != nil {
+		writeTourError(w, err, "Failed to plan tour: ")
+		return
+	}
+
+	if format == "json" {
+		writeJSON(w, http.StatusOK, Response{Data: plan})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/gpx+xml")
+	w.Header().Set("Content-Disposition", `attachment; filename="tour.gpx"`)
+	if err := h.exportService.WriteTourPlanGPX(w, plan); err != nil {
+		logger.Error.Printf("Failed to write tour plan as GPX: %v", err)
+	}
+}
+
+func writeTourError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, repository.ErrTourNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrInvalidTour),
+		errors.Is(err, repository.ErrTourPOINotFound),
+		errors.Is(err, repository.ErrTourDuplicatePOI):
+		writeError(w, http.StatusBadRequest, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, message+err.Error())
+	}
+}
@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"aigpsservice/internal/domain"
+	"aigpsservice/internal/repository"
+	"aigpsservice/internal/service"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+type TranslationHandler struct {
+	poiService *service.POIService
+}
+
+func NewTranslationHandler(poiService *service.POIService) *TranslationHandler {
+	return &TranslationHandler{
+		poiService: poiService,
+	}
+}
+
+// TranslationRequest is the name and description of a POI in one language.
+type TranslationRequest struct {
+	Name        string `json:"name" example:"City Pond Dam"`
+	Description string `json:"description" example:"The dam that gave birth to the city."`
+}
+
+// ListTranslations godoc
+// @Tags POI
+// @Summary Переводы точки интереса
+// @Description Возвращает названия и описания точки на других языках, кроме русского
+// @Produce json
+// @Param id path int true "Id точки интереса" example(195)
+// @Success 200 {array} domain.POITranslation
+// @Failure 400 {object} Response
+// @Failure 404 {object} Response
+// @Router /api/poi/{id}/translations [get]
+func (h *TranslationHandler) ListTranslations(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid id format")
+		return
+	}
+
+	translations, err := h.poiService.ListTranslations(r.Context(), id)
+	if err != nil {
+		writeTranslationError(w, err, "Failed to get translations: ")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{Data: translations})
+}
+
+// HandleTranslation routes requests to one translation of a POI by method.
+func (h *TranslationHandler) HandleTranslation(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPut:
+		h.SaveTranslation(w, r)
+	case http.MethodDelete:
+		h.DeleteTranslation(w, r)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// SaveTranslation godoc
+// @Tags POI
+// @Summary Создание или замена перевода точки интереса
+// @Description Сохраняет название и описание точки на языке lang. Русские название и описание хранятся в самой точке
+// @Accept json
+// @Produce json
+// @Param id path int true "Id точки интереса" example(195)
+// @Param lang path string true "Язык (ISO 639)" example(en)
+// @Param request body TranslationRequest true "Название и описание"
+// @Success 200 {object} domain.POITranslation
+// @Failure 400 {object} Response
+// @Failure 404 {object} Response
+// @Security BearerAuth
+// @Router /api/poi/{id}/translations/{lang} [put]
+func (h *TranslationHandler) SaveTranslation(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid id format")
+		return
+	}
+
+	var request TranslationRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	translation := &domain.POITranslation{
+		Language:    r.PathValue("lang"),
+		Name:        request.Name,
+		Description: request.Description,
+	}
+	if err := h.poiService.SaveTranslation(r.Context(), id, translation); err != nil {
+		writeTranslationError(w, err, "Failed to save translation: ")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{Data: translation})
+}
+
+// DeleteTranslation godoc
+// @Tags POI
+// @Summary Удаление перевода точки интереса
+// @Description Удаляет название и описание точки на языке lang. Аудио на этом языке не удаляются
+// @Param id path int true "Id точки интереса" example(195)
+// @Param lang path string true "Язык (ISO 639)" example(en)
+// @Success 204
+// @Failure 400 {object} Response
+// @Failure 404 {object} Response
+// @Security BearerAuth
+// @Router /api/poi/{id}/translations/{lang} [delete]
+func (h *TranslationHandler) DeleteTranslation(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid id format")
+		return
+	}
+
+	if err := h.poiService.DeleteTranslation(r.Context(), id, r.PathValue("lang")); err != nil {
+		writeTranslationError(w, err, "Failed to delete translation: ")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeTranslationError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, repository.ErrPOINotFound), errors.Is(err, repository.ErrTranslationNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrInvalidTranslation):
+		writeError(w, http.StatusBadRequest, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, message+err.Error())
+	}
+}
@@ -0,0 +1,204 @@
+package handler
+
+import (
+	"aigpsservice/internal/domain"
+	"aigpsservice/internal/repository"
+	"aigpsservice/internal/service"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+type UserHandler struct {
+	userService *service.UserService
+}
+
+func NewUserHandler(userService *service.UserService) *UserHandler {
+	return &UserHandler{
+		userService: userService,
+	}
+}
+
+// RegisterRequest is the body of a new user account.
+type RegisterRequest struct {
+	Email    string             `json:"email" example:"listener@example.com"`
+	Password string             `json:"password" example:"correct horse"`
+	Profile  domain.UserProfile `json:"profile"`
+}
+
+// LoginRequest is the body of a sign-in.
+type LoginRequest struct {
+	Email    string `json:"email" example:"listener@example.com"`
+	Password string `json:"password" example:"correct horse"`
+}
+
+// Register godoc
+// @Tags Users
+// @Summary Регистрация пользователя
+// @Description Создает пользователя с профилем и возвращает токен для заголовка Authorization: Bearer <token>.
+// @Description Вес интереса от 0 до 1, по умолчанию 1. narration_length - short или full, по умолчанию full,
+// @Description language по умолчанию ru. voice только сохраняется в профиле и пока не влияет на аудио
+// @Accept json
+// @Produce json
+// @Param request body RegisterRequest true "Почта, пароль и профиль"
+// @Success 201 {object} domain.AuthToken
+// @Failure 400 {object} Response
+// @Failure 409 {object} Response
+// @Router /api/users/register [post]
+func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var request RegisterRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	token, err := h.userService.Register(r.Context(), request.Email, request.Password, request.Profile)
+	if err != nil {
+		writeUserError(w, err, "Failed to register user: ")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, Response{Data: token})
+}
+
+// Login godoc
+// @Tags Users
+// @Summary Вход пользователя
+// @Description Проверяет почту и пароль и возвращает новый токен
+// @Accept json
+// @Produce json
+// @Param request body LoginRequest true "Почта и пароль"
+// @Success 200 {object} domain.AuthToken
+// @Failure 400 {object} Response
+// @Failure 401 {object} Response
+// @Router /api/users/login [post]
+func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var request LoginRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	token, err := h.userService.Login(r.Context(), request.Email, request.Password)
+	if err != nil {
+		writeUserError(w, err, "Failed to sign in: ")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{Data: token})
+}
+
+// GetMe godoc
+// @Tags Users
+// @Summary Текущий пользователь
+// @Description Возвращает пользователя токена вместе с профилем
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} domain.User
+// @Failure 401 {object} Response
+// @Failure 403 {object} Response
+// @Router /api/users/me [get]
+func (h *UserHandler) GetMe(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	user, err := h.userService.GetUser(r.Context(), userID)
+	if err != nil {
+		writeUserError(w, err, "Failed to get user: ")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{Data: user})
+}
+
+// UpdateProfile godoc
+// @Tags Users
+// @Summary Изменение профиля
+// @Description Заменяет профиль пользователя токена целиком. Интересы профиля используются в /api/poi/nearby,
+// @Description если параметр interests не передан. narration_length = short оставляет в /api/poi/nearby
+// @Description и сессиях только короткое аудио, voice только сохраняется и пока не влияет на аудио
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param profile body domain.UserProfile true "Профиль"
+// @Success 200 {object} domain.User
+// @Failure 400 {object} Response
+// @Failure 401 {object} Response
+// @Failure 403 {object} Response
+// @Router /api/users/me/profile [put]
+func (h *UserHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var profile domain.UserProfile
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&profile); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	user, err := h.userService.UpdateProfile(r.Context(), userID, profile)
+	if err != nil {
+		writeUserError(w, err, "Failed to update profile: ")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{Data: user})
+}
+
+// currentUserID returns the signed-in user of the request. API keys have no
+// user, so they are refused.
+func currentUserID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	principal := service.PrincipalFrom(r.Context())
+	if principal == nil {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		writeError(w, http.StatusUnauthorized, "Authentication required")
+		return 0, false
+	}
+	if principal.UserID == 0 {
+		writeError(w, http.StatusForbidden, "A user token is required")
+		return 0, false
+	}
+	return principal.UserID, true
+}
+
+func writeUserError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, repository.ErrUserNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, repository.ErrUserExists):
+		writeError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrInvalidLogin):
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		writeError(w, http.StatusUnauthorized, err.Error())
+	case errors.Is(err, service.ErrInvalidUser):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrUnknownInterest):
+		writeInterestsError(w, err)
+	default:
+		writeError(w, http.StatusInternalServerError, message+err.Error())
+	}
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"aigpsservice/internal/domain"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+type AuthRepository struct {
+	db *sql.DB
+}
+
+func NewAuthRepository(db *sql.DB) *AuthRepository {
+	return &AuthRepository{db: db}
+}
+
+const apiKeyColumns = `id, name, prefix, role, created_at, revoked_at`
+
+func scanAPIKey(row interface{ Scan(...any) error }) (*domain.APIKey, error) {
+	var key domain.APIKey
+	var revokedAt sql.NullTime
+	if err := row.Scan(&key.ID, &key.Name, &key.Prefix, &key.Role, &key.CreatedAt, &revokedAt); err != nil {
+		return nil, err
+	}
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+	return &key, nil
+}
+
+// CreateAPIKey stores a key by the hash of its secret. A key with the same
+// hash is left as it is and returned instead.
+func (r *AuthRepository) CreateAPIKey(ctx context.Context, key *domain.APIKey, hash []byte) (*domain.APIKey, error) {
+	query := `
+		INSERT INTO api_keys (name, prefix, key_hash, role)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (key_hash) DO UPDATE SET key_hash = EXCLUDED.key_hash
+		RETURNING ` + apiKeyColumns
+
+	created, err := scanAPIKey(r.db.QueryRowContext(ctx, query, key.Name, key.Prefix, hash, key.Role))
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert api key: %w", err)
+	}
+	return created, nil
+}
+
+// GetActiveAPIKey returns the key with the hash unless it was revoked.
+func (r *AuthRepository) GetActiveAPIKey(ctx context.Context, hash []byte) (*domain.APIKey, error) {
+	row := r.db.QueryRowContext(ctx,
+		`SELECT `+apiKeyColumns+` FROM api_keys WHERE key_hash = $1 AND revoked_at IS NULL`, hash)
+
+	key, err := scanAPIKey(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+	return key, nil
+}
+
+func (r *AuthRepository) ListAPIKeys(ctx context.Context) ([]*domain.APIKey, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query api keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := make([]*domain.APIKey, 0)
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan api key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return keys, nil
+}
+
+// RevokeAPIKey disables a key for good. Revoking a revoked key is a no-op.
+func (r *AuthRepository) RevokeAPIKey(ctx context.Context, id int64) error {
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE api_keys SET revoked_at = COALESCE(revoked_at, CURRENT_TIMESTAMP) WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrAPIKeyNotFound
+	}
+
+	return nil
+}
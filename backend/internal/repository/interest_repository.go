@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"aigpsservice/internal/domain"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+var (
+	ErrInterestNotFound    = errors.New("interest type not found")
+	ErrInterestExists      = errors.New("interest type already exists")
+	ErrInterestHasChildren = errors.New("interest type has child interests")
+
+	ErrInterestParentNotFound = errors.New("parent interest type not found")
+)
+
+type InterestRepository struct {
+	db *sql.DB
+}
+
+func NewInterestRepository(db *sql.DB) *InterestRepository {
+	return &InterestRepository{db: db}
+}
+
+const interestColumns = `id, parent_id, names, COALESCE(icon, ''), created_at, updated_at`
+
+func (r *InterestRepository) ListInterests(ctx context.Context) ([]*domain.InterestType, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+interestColumns+` FROM type_of_interest ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query interest types: %w", err)
+	}
+	defer rows.Close()
+
+	interests := make([]*domain.InterestType, 0)
+	for rows.Next() {
+		interest, err := scanInterest(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan interest type: %w", err)
+		}
+		interests = append(interests, interest)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return interests, nil
+}
+
+func (r *InterestRepository) GetInterest(ctx context.Context, id string) (*domain.InterestType, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+interestColumns+` FROM type_of_interest WHERE id = $1`, id)
+
+	interest, err := scanInterest(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrInterestNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get interest type: %w", err)
+	}
+
+	return interest, nil
+}
+
+func (r *InterestRepository) CreateInterest(ctx context.Context, interest *domain.InterestType) (*domain.InterestType, error) {
+	names, err := json.Marshal(interest.Names)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode names: %w", err)
+	}
+
+	query := `
+		INSERT INTO type_of_interest (id, parent_id, names, icon)
+		VALUES ($1, $2, $3, NULLIF($4, ''))
+		RETURNING ` + interestColumns
+
+	created, err := scanInterest(r.db.QueryRowContext(ctx, query, interest.ID, interest.ParentID, names, interest.Icon))
+	if err != nil {
+		return nil, mapInterestError(err, "failed to insert interest type", ErrInterestParentNotFound)
+	}
+
+	return created, nil
+}
+
+func (r *InterestRepository) UpdateInterest(ctx context.Context, id string, update *domain.InterestTypeUpdate) (*domain.InterestType, error) {
+	var names sql.NullString
+	if update.Names != nil {
+		data, err := json.Marshal(update.Names)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode names: %w", err)
+		}
+		names = sql.NullString{String: string(data), Valid: true}
+	}
+
+	query := `
+		UPDATE type_of_interest
+		SET
+			parent_id = CASE WHEN $2::boolean THEN NULLIF($3, '') ELSE parent_id END,
+			names = COALESCE($4::jsonb, names),
+			icon = CASE WHEN $5::boolean THEN NULLIF($6, '') ELSE icon END,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		RETURNING ` + interestColumns
+
+	var parentID, icon string
+	if update.ParentID != nil {
+		parentID = *update.ParentID
+	}
+	if update.Icon != nil {
+		icon = *update.Icon
+	}
+
+	updated, err := scanInterest(r.db.QueryRowContext(ctx, query,
+		id, update.ParentID != nil, parentID, names, update.Icon != nil, icon))
+	if err == sql.ErrNoRows {
+		return nil, ErrInterestNotFound
+	}
+	if err != nil {
+		return nil, mapInterestError(err, "failed to update interest type", ErrInterestParentNotFound)
+	}
+
+	return updated, nil
+}
+
+// DeleteInterest removes an interest type and untags the POIs that had it.
+// Interests with children can't be deleted.
+func (r *InterestRepository) DeleteInterest(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM type_of_interest WHERE id = $1`, id)
+	if err != nil {
+		return mapInterestError(err, "failed to delete interest type", ErrInterestHasChildren)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrInterestNotFound
+	}
+
+	return nil
+}
+
+// mapInterestError turns constraint violations into the repository errors.
+// A parent reference violation means a missing parent on insert and update,
+// and remaining children on delete, so the caller passes which one it is.
+func mapInterestError(err error, message string, parentErr error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code.Name() {
+		case "unique_violation":
+			return ErrInterestExists
+		case "foreign_key_violation":
+			if pqErr.Constraint == "fk_type_of_interest_parent" {
+				return parentErr
+			}
+		}
+	}
+	return fmt.Errorf("%s: %w", message, err)
+}
+
+func scanInterest(row interface{ Scan(...any) error }) (*domain.InterestType, error) {
+	var interest domain.InterestType
+	var parentID sql.NullString
+	var names []byte
+
+	err := row.Scan(&interest.ID, &parentID, &names, &interest.Icon, &interest.CreatedAt, &interest.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if parentID.Valid {
+		interest.ParentID = &parentID.String
+	}
+	if err := json.Unmarshal(names, &interest.Names); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal names JSON: %w", err)
+	}
+
+	return &interest, nil
+}
@@ -0,0 +1,241 @@
+package repository
+
+import (
+	"aigpsservice/internal/domain"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+var (
+	ErrJobNotFound = errors.New("job not found")
+	// ErrJobLost means the job is no longer running under the attempt that
+	// tried to touch it: its lease expired and another worker claimed it, or
+	// it was finished.
+	ErrJobLost = errors.New("job is no longer held by this attempt")
+)
+
+type JobRepository struct {
+	db *sql.DB
+}
+
+func NewJobRepository(db *sql.DB) *JobRepository {
+	return &JobRepository{db: db}
+}
+
+const jobColumns = `
+	id, kind, payload, status, attempts, max_attempts, COALESCE(last_error, ''),
+	result, run_at, created_at, updated_at, finished_at
+`
+
+func (r *JobRepository) EnqueueJob(ctx context.Context, kind string, payload any, maxAttempts int) (*domain.Job, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode job payload: %w", err)
+	}
+
+	query := `
+		INSERT INTO jobs (kind, payload, max_attempts)
+		VALUES ($1, $2, $3)
+		RETURNING ` + jobColumns
+
+	job, err := scanJob(r.db.QueryRowContext(ctx, query, kind, data, maxAttempts))
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert job: %w", err)
+	}
+
+	return job, nil
+}
+
+func (r *JobRepository) GetJob(ctx context.Context, id int64) (*domain.Job, error) {
+	query := `SELECT ` + jobColumns + ` FROM jobs WHERE id = $1`
+
+	job, err := scanJob(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrJobNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+
+	return job, nil
+}
+
+// ClaimJob marks the oldest due job as running and returns it, or nil when
+// there is nothing to do. Jobs of the given kinds only are claimed. SKIP
+// LOCKED lets several workers claim concurrently without waiting on each
+// other. A running job whose lock is older than lease is considered
+// abandoned by a crashed worker and is claimed again, or failed for good
+// when that was its last attempt.
+func (r *JobRepository) ClaimJob(ctx context.Context, kinds []string, lease time.Duration) (*domain.Job, error) {
+	failQuery := `
+		UPDATE jobs
+		SET
+			status = 'failed',
+			last_error = 'abandoned by its worker on the last attempt',
+			locked_at = NULL,
+			updated_at = CURRENT_TIMESTAMP,
+			finished_at = CURRENT_TIMESTAMP
+		WHERE kind = ANY($1::text[])
+		AND status = 'running'
+		AND attempts >= max_attempts
+		AND locked_at < CURRENT_TIMESTAMP - make_interval(secs => $2::double precision)
+	`
+	if _, err := r.db.ExecContext(ctx, failQuery, pq.Array(kinds), lease.Seconds()); err != nil {
+		return nil, fmt.Errorf("failed to fail abandoned jobs: %w", err)
+	}
+
+	query := `
+		UPDATE jobs
+		SET
+			status = 'running',
+			attempts = attempts + 1,
+			locked_at = CURRENT_TIMESTAMP,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = (
+			SELECT id
+			FROM jobs
+			WHERE kind = ANY($1::text[])
+			AND (
+				(status = 'pending' AND run_at <= CURRENT_TIMESTAMP)
+				OR (
+					status = 'running'
+					AND attempts < max_attempts
+					AND locked_at < CURRENT_TIMESTAMP - make_interval(secs => $2::double precision)
+				)
+			)
+			ORDER BY run_at ASC, id ASC
+			FOR UPDATE SKIP LOCKED
+			LIMIT 1
+		)
+		RETURNING ` + jobColumns
+
+	job, err := scanJob(r.db.QueryRowContext(ctx, query, pq.Array(kinds), lease.Seconds()))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim job: %w", err)
+	}
+
+	return job, nil
+}
+
+// CompleteJob stores the result of the attempt of the job and marks it as
+// succeeded. It returns ErrJobLost when the attempt no longer holds the job.
+func (r *JobRepository) CompleteJob(ctx context.Context, id int64, attempt int, result any) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to encode job result: %w", err)
+	}
+
+	query := `
+		UPDATE jobs
+		SET
+			status = 'succeeded',
+			result = $2,
+			last_error = NULL,
+			locked_at = NULL,
+			updated_at = CURRENT_TIMESTAMP,
+			finished_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status = 'running' AND attempts = $3
+	`
+	res, err := r.db.ExecContext(ctx, query, id, data, attempt)
+	if err != nil {
+		return fmt.Errorf("failed to complete job: %w", err)
+	}
+
+	return requireHeldJob(res)
+}
+
+// FailJob records a failed attempt. The job is scheduled again at retryAt
+// while it has attempts left, otherwise it is marked as failed for good. It
+// returns ErrJobLost when the attempt no longer holds the job.
+func (r *JobRepository) FailJob(ctx context.Context, id int64, attempt int, jobErr error, retryAt time.Time) error {
+	query := `
+		UPDATE jobs
+		SET
+			status = CASE WHEN attempts < max_attempts THEN 'pending' ELSE 'failed' END,
+			run_at = CASE WHEN attempts < max_attempts THEN $3 ELSE run_at END,
+			finished_at = CASE WHEN attempts < max_attempts THEN NULL ELSE CURRENT_TIMESTAMP END,
+			last_error = $2,
+			locked_at = NULL,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status = 'running' AND attempts = $4
+	`
+	res, err := r.db.ExecContext(ctx, query, id, jobErr.Error(), retryAt, attempt)
+	if err != nil {
+		return fmt.Errorf("failed to record job failure: %w", err)
+	}
+
+	return requireHeldJob(res)
+}
+
+// ExtendJobLease renews the lock of a running job, so a job that runs
+// longer than the lease is not taken for abandoned. It returns ErrJobLost
+// when the attempt no longer holds the job.
+func (r *JobRepository) ExtendJobLease(ctx context.Context, id int64, attempt int) error {
+	query := `
+		UPDATE jobs
+		SET locked_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status = 'running' AND attempts = $2
+	`
+	res, err := r.db.ExecContext(ctx, query, id, attempt)
+	if err != nil {
+		return fmt.Errorf("failed to extend job lease: %w", err)
+	}
+
+	return requireHeldJob(res)
+}
+
+// requireHeldJob turns an update of a job that matched no row into
+// ErrJobLost.
+func requireHeldJob(res sql.Result) error {
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get updated rows: %w", err)
+	}
+	if updated == 0 {
+		return ErrJobLost
+	}
+	return nil
+}
+
+func scanJob(row *sql.Row) (*domain.Job, error) {
+	var job domain.Job
+	var payload, result []byte
+	var finishedAt sql.NullTime
+
+	err := row.Scan(
+		&job.ID,
+		&job.Kind,
+		&payload,
+		&job.Status,
+		&job.Attempts,
+		&job.MaxAttempts,
+		&job.LastError,
+		&result,
+		&job.RunAt,
+		&job.CreatedAt,
+		&job.UpdatedAt,
+		&finishedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	job.Payload = payload
+	if result != nil {
+		job.Result = result
+	}
+	if finishedAt.Valid {
+		job.FinishedAt = &finishedAt.Time
+	}
+
+	return &job, nil
+}
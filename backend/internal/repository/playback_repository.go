@@ -0,0 +1,299 @@
+package repository
+
+import (
+	"aigpsservice/internal/domain"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+var ErrPlaybackFileNotFound = errors.New("audio file of a playback event not found")
+
+type PlaybackRepository struct {
+	db *sql.DB
+}
+
+func NewPlaybackRepository(db *sql.DB) *PlaybackRepository {
+	return &PlaybackRepository{db: db}
+}
+
+// InsertEvents appends a batch of events. Either all of them are stored or,
+// if one refers to an unknown file, none.
+func (r *PlaybackRepository) InsertEvents(ctx context.Context, userID *int64, events []*domain.PlaybackEvent) error {
+	fileIDs := make([]int64, 0, len(events))
+	kinds := make([]string, 0, len(events))
+	positions := make([]float64, 0, len(events))
+	occurredAt := make([]string, 0, len(events))
+	sessionIDs := make([]string, 0, len(events))
+	for _, event := range events {
+		fileIDs = append(fileIDs, event.FileID)
+		kinds = append(kinds, event.Event)
+		positions = append(positions, event.PositionSeconds)
+		occurredAt = append(occurredAt, event.OccurredAt.Format(time.RFC3339Nano))
+		sessionIDs = append(sessionIDs, event.SessionID)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO playback_events (file_id, poi_id, user_id, session_id, event, position_seconds, occurred_at)
+		SELECT e.file_id, f.poi_id, $1, NULLIF(e.session_id, '')::uuid, e.event, e.position_seconds, e.occurred_at
+		FROM unnest($2::integer[], $3::text[], $4::double precision[], $5::timestamptz[], $6::text[])
+			WITH ORDINALITY AS e(file_id, event, position_seconds, occurred_at, session_id, n)
+		JOIN poi_files f ON f.id = e.file_id
+		ORDER BY e.n`,
+		userID, pq.Array(fileIDs), pq.Array(kinds), pq.Array(positions), pq.Array(occurredAt), pq.Array(sessionIDs),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert playback events: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected != int64(len(events)) {
+		return ErrPlaybackFileNotFound
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// ListPOIStats returns the playback counts of the POIs played most in the
+// period.
+func (r *PlaybackRepository) ListPOIStats(ctx context.Context, period domain.StatsPeriod, limit int) ([]*domain.POIPlaybackSummary, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT
+			e.poi_id,
+			COALESCE(p.name, ''),
+			count(*) FILTER (WHERE e.event = 'started'),
+			count(*) FILTER (WHERE e.event = 'completed'),
+			count(*) FILTER (WHERE e.event = 'skipped')
+		FROM playback_events e
+		LEFT JOIN points_of_interest p ON p.id = e.poi_id
+		WHERE ($1::timestamptz IS NULL OR e.occurred_at >= $1)
+		AND ($2::timestamptz IS NULL OR e.occurred_at < $2)
+		GROUP BY e.poi_id, p.name
+		ORDER BY 3 DESC, e.poi_id
+		LIMIT $3`,
+		period.From, period.To, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query poi stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats := make([]*domain.POIPlaybackSummary, 0)
+	for rows.Next() {
+		var summary domain.POIPlaybackSummary
+		err := rows.Scan(&summary.POIID, &summary.Name, &summary.Started, &summary.Completed, &summary.Skipped)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan poi stats: %w", err)
+		}
+		summary.PlaybackCounts.SetCompletionRate()
+		stats = append(stats, &summary)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read poi stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+// GetPOIStats returns the completion of every played file of the POI, the
+// skip positions grouped into buckets of bucketSeconds and the plays per day.
+func (r *PlaybackRepository) GetPOIStats(ctx context.Context, poiID int64, period domain.StatsPeriod, bucketSeconds float64) (*domain.POIPlaybackStats, error) {
+	stats := &domain.POIPlaybackStats{
+		POIID:      poiID,
+		Files:      make([]*domain.FilePlaybackStats, 0),
+		SkipPoints: make([]*domain.SkipBucket, 0),
+		DailyPlays: make([]*domain.DailyPlays, 0),
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT
+			e.file_id,
+			count(*) FILTER (WHERE e.event = 'started'),
+			count(*) FILTER (WHERE e.event = 'completed'),
+			count(*) FILTER (WHERE e.event = 'skipped')
+		FROM playback_events e
+		WHERE e.poi_id = $1
+		AND ($2::timestamptz IS NULL OR e.occurred_at >= $2)
+		AND ($3::timestamptz IS NULL OR e.occurred_at < $3)
+		GROUP BY e.file_id
+		ORDER BY e.file_id`,
+		poiID, period.From, period.To,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query file stats: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var file domain.FilePlaybackStats
+		if err := rows.Scan(&file.FileID, &file.Started, &file.Completed, &file.Skipped); err != nil {
+			return nil, fmt.Errorf("failed to scan file stats: %w", err)
+		}
+		file.PlaybackCounts.SetCompletionRate()
+		stats.Files = append(stats.Files, &file)
+
+		stats.Started += file.Started
+		stats.Completed += file.Completed
+		stats.Skipped += file.Skipped
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read file stats: %w", err)
+	}
+	stats.PlaybackCounts.SetCompletionRate()
+
+	rows, err = r.db.QueryContext(ctx, `
+		SELECT floor(e.position_seconds / $4)::bigint AS bucket, count(*)
+		FROM playback_events e
+		WHERE e.poi_id = $1 AND e.event = 'skipped'
+		AND ($2::timestamptz IS NULL OR e.occurred_at >= $2)
+		AND ($3::timestamptz IS NULL OR e.occurred_at < $3)
+		GROUP BY bucket
+		ORDER BY bucket`,
+		poiID, period.From, period.To, bucketSeconds,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query skip points: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var bucket int64
+		var skips int64
+		if err := rows.Scan(&bucket, &skips); err != nil {
+			return nil, fmt.Errorf("failed to scan skip points: %w", err)
+		}
+		stats.SkipPoints = append(stats.SkipPoints, &domain.SkipBucket{
+			FromSeconds: float64(bucket) * bucketSeconds,
+			ToSeconds:   float64(bucket+1) * bucketSeconds,
+			Skips:       skips,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read skip points: %w", err)
+	}
+
+	rows, err = r.db.QueryContext(ctx, `
+		SELECT to_char(date_trunc('day', e.occurred_at AT TIME ZONE 'UTC'), 'YYYY-MM-DD') AS day, count(*)
+		FROM playback_events e
+		WHERE e.poi_id = $1 AND e.event = 'started'
+		AND ($2::timestamptz IS NULL OR e.occurred_at >= $2)
+		AND ($3::timestamptz IS NULL OR e.occurred_at < $3)
+		GROUP BY day
+		ORDER BY day`,
+		poiID, period.From, period.To,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query daily plays: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var day domain.DailyPlays
+		if err := rows.Scan(&day.Day, &day.Plays); err != nil {
+			return nil, fmt.Errorf("failed to scan daily plays: %w", err)
+		}
+		stats.DailyPlays = append(stats.DailyPlays, &day)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read daily plays: %w", err)
+	}
+
+	return stats, nil
+}
+
+// GetPlayHistory returns the plays of the POIs by everyone since the given
+// time and when the user last started each of them. POIs never played are
+// left out; a nil userID leaves LastHeardAt unset.
+func (r *PlaybackRepository) GetPlayHistory(ctx context.Context, poiIDs []int64, since time.Time, userID *int64) (map[int64]*domain.POIPlayHistory, error) {
+	history := make(map[int64]*domain.POIPlayHistory, len(poiIDs))
+	if len(poiIDs) == 0 {
+		return history, nil
+	}
+
+	// Only the popularity window is scanned, so old events of busy POIs are
+	// not read on every nearby search.
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT e.poi_id, count(*)
+		FROM playback_events e
+		WHERE e.poi_id = ANY($1::integer[]) AND e.event = 'started' AND e.occurred_at >= $2
+		GROUP BY e.poi_id`,
+		pq.Array(poiIDs), since,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query play history: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var poiID int64
+		var plays int64
+		if err := rows.Scan(&poiID, &plays); err != nil {
+			return nil, fmt.Errorf("failed to scan play history: %w", err)
+		}
+		history[poiID] = &domain.POIPlayHistory{Plays: plays}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read play history: %w", err)
+	}
+
+	if userID == nil {
+		return history, nil
+	}
+	if err := r.getLastHeard(ctx, poiIDs, *userID, history); err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
+
+// getLastHeard sets when the user last started each of the POIs, however
+// long ago. The lookup goes through idx_playback_events_user and reads only
+// the events of the user.
+func (r *PlaybackRepository) getLastHeard(ctx context.Context, poiIDs []int64, userID int64, history map[int64]*domain.POIPlayHistory) error {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT e.poi_id, max(e.occurred_at)
+		FROM playback_events e
+		WHERE e.user_id = $1 AND e.poi_id = ANY($2::integer[]) AND e.event = 'started'
+		GROUP BY e.poi_id`,
+		userID, pq.Array(poiIDs),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to query last plays of user: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var poiID int64
+		var lastHeardAt time.Time
+		if err := rows.Scan(&poiID, &lastHeardAt); err != nil {
+			return fmt.Errorf("failed to scan last play of user: %w", err)
+		}
+
+		entry, ok := history[poiID]
+		if !ok {
+			entry = &domain.POIPlayHistory{}
+			history[poiID] = entry
+		}
+		entry.LastHeardAt = &lastHeardAt
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read last plays of user: %w", err)
+	}
+
+	return nil
+}
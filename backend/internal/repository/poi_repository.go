@@ -5,13 +5,46 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"strings"
 	"time"
 
 	"github.com/lib/pq"
 )
 
+const (
+	// lookAheadHalfAngle is the half-width in degrees of the cone in front
+	// of a traveler where POIs are searched when the heading is known.
+	lookAheadHalfAngle = 60.0
+	// passingDistanceMeters is the distance at which a POI counts as being
+	// passed right now, whatever the heading.
+	passingDistanceMeters = 30.0
+	// The short audio duration is estimated from its size, assuming
+	// shortAudioBitsPerSecond, or defaultShortAudioSeconds if there is none.
+	defaultShortAudioSeconds = 30.0
+	shortAudioBitsPerSecond  = 128000.0
+	maxShortAudioSeconds     = 180.0
+	maxLookAheadMeters       = 3000.0
+	// MaxTriggerRadius is the largest own trigger radius of a POI.
+	MaxTriggerRadius = 5000.0
+	// metersPerDegree is the length of a degree of latitude, and of
+	// longitude on the equator.
+	metersPerDegree = 111320.0
+)
+
+var (
+	ErrPOINotFound         = errors.New("point of interest not found")
+	ErrTranslationNotFound = errors.New("translation not found")
+	// ErrInvalidAudioChange is a removal or reordering of full audio that
+	// does not match the files of the POI.
+	ErrInvalidAudioChange = errors.New("invalid full audio change")
+	// ErrInvalidGeofence is a geofence the database rejects as an invalid
+	// geometry.
+	ErrInvalidGeofence = errors.New("invalid geofence geometry")
+)
+
 type POIRepository struct {
 	db *sql.DB
 }
@@ -20,112 +53,510 @@ func NewPOIRepository(db *sql.DB) *POIRepository {
 	return &POIRepository{db: db}
 }
 
-func (r *POIRepository) GetPOIById(idPOI int) (*domain.PointOfInterest, error) {
+// poiSelect reads the POIs of a preceding page CTE together with their
+// interests and files. The page has the ids of the POIs and their
+// distance_meters, bearing, along_track_meters and geofence_meters, which
+// are NULL when the query does not measure them.
+const poiSelect = `
+        SELECT
+            p.id, p.name, p.description, ST_Y(p.location), ST_X(p.location), p.created_at,
+            COALESCE(
+                (
+                    SELECT json_agg(pt.type_of_interest_id ORDER BY pt.type_of_interest_id)
+                    FROM points_of_interest_type pt
+                    WHERE pt.point_of_interest_id = p.id
+                ),
+                '[]'::json
+            ),
+            np.distance_meters, np.bearing, np.along_track_meters,
+            p.trigger_radius, ST_AsGeoJSON(p.geofence), np.geofence_meters,
+            f.id, f.s3_key, f.file_name, f.file_size, f.mime_type, f.serial_number, f.is_short, f.created_at, f.language
+        FROM page np
+        JOIN points_of_interest p ON p.id = np.id
+        LEFT JOIN poi_files f ON np.id = f.poi_id
+`
+
+// poiUnmeasured are the measures of poiSelect for pages that are not
+// searched around a point or a route.
+const poiUnmeasured = `
+                NULL::double precision as distance_meters,
+                NULL::double precision as bearing,
+                NULL::double precision as along_track_meters,
+                NULL::double precision as geofence_meters`
+
+// interestsFilter matches the POIs with any of the interests of the text
+// array parameter, or all POIs when the array is empty.
+func interestsFilter(param int) string {
+	return fmt.Sprintf(`(
+            array_length($%[1]d::text[], 1) IS NULL
+            OR array_length($%[1]d, 1) = 0
+            OR EXISTS (
+                SELECT 1
+                FROM points_of_interest_type pt2
+                WHERE pt2.point_of_interest_id = p.id
+                AND pt2.type_of_interest_id = ANY($%[1]d::text[])
+            )
+        )`, param)
+}
+
+func (r *POIRepository) GetPOIById(ctx context.Context, idPOI int) (*domain.PointOfInterest, error) {
 	query := `
-        WITH nearest_poi AS (
-            SELECT 
-				p.id,
-                p.name,
-                p.description, 
-                p.created_at,
-                ST_X(p.location) as longitude,
-                ST_Y(p.location) as latitude,
-				COALESCE(
-                    json_agg(DISTINCT t.id) FILTER (WHERE t.id IS NOT NULL),
-                    '[]'::json
-                ) as interests
+        WITH page AS (
+            SELECT p.id,` + poiUnmeasured + `
             FROM points_of_interest p
-			LEFT JOIN points_of_interest_type pt ON p.id = pt.point_of_interest_id
-            LEFT JOIN type_of_interest t ON pt.type_of_interest_id = t.id
-			WHERE p.id = $1
-			GROUP BY p.id
-            LIMIT 1
-        )
-        SELECT 
-            np.id, np.name, np.description, np.latitude, np.longitude, np.created_at, np.interests,
-            f.id, f.s3_key, f.file_name, f.file_size, f.mime_type, f.serial_number, f.is_short, f.created_at
-        FROM nearest_poi np
-        LEFT JOIN poi_files f ON np.id = f.poi_id
+            WHERE p.id = $1
+        )` + poiSelect + `
         ORDER BY f.is_short DESC, f.serial_number ASC
     `
-	rows, err := r.db.Query(query, idPOI)
+	rows, err := r.db.QueryContext(ctx, query, idPOI)
 	if err != nil {
 		return nil, fmt.Errorf("database query error: %w", err)
 	}
 	defer rows.Close()
 
-	poi, err := r.scanPOIWithFiles(rows)
+	pois, err := r.scanPOIsWithFiles(rows)
 	if err != nil {
 		return nil, err
 	}
 
-	return poi, nil
+	if len(pois) == 0 {
+		return nil, ErrPOINotFound
+	}
+
+	return pois[0], nil
+}
+
+// GetPOIsByIDs returns the POIs with the given ids in the order of ids.
+// Unknown ids are skipped.
+func (r *POIRepository) GetPOIsByIDs(ctx context.Context, ids []int64) ([]*domain.PointOfInterest, error) {
+	query := `
+        WITH page AS (
+            SELECT
+                p.id,
+                array_position($1::bigint[], p.id::bigint) as position,` + poiUnmeasured + `
+            FROM points_of_interest p
+            WHERE p.id = ANY($1::bigint[])
+        )` + poiSelect + `
+        ORDER BY np.position ASC, f.is_short DESC, f.serial_number ASC
+    `
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("database query error: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanPOIsWithFiles(rows)
 }
 
-func (r *POIRepository) FindNearestPOI(latitude, longitude float64, radius int, interests []string) (*domain.PointOfInterest, error) {
+// ListPOIs returns up to limit POIs matching the filter, newest first,
+// starting right after the after position when it is set.
+func (r *POIRepository) ListPOIs(ctx context.Context, filter domain.POIListFilter, after *domain.POICursor, limit int) ([]*domain.PointOfInterest, error) {
+	var afterCreatedAt *time.Time
+	var afterID int64
+	if after != nil {
+		afterCreatedAt = &after.CreatedAt
+		afterID = after.ID
+	}
+
 	query := `
-        WITH nearest_poi AS (
-            SELECT 
-				p.id,
-                p.name,
-                p.description, 
-                p.created_at,
-                ST_X(p.location) as longitude,
-                ST_Y(p.location) as latitude,
-				COALESCE(
-                    json_agg(DISTINCT t.id) FILTER (WHERE t.id IS NOT NULL),
-                    '[]'::json
-                ) as interests,
+        WITH page AS (
+            SELECT p.id, p.created_at,` + poiUnmeasured + `
+            FROM points_of_interest p
+            WHERE ` + interestsFilter(1) + `
+            AND ($2::timestamptz IS NULL OR p.created_at >= $2)
+            AND ($3::timestamptz IS NULL OR p.created_at <= $3)
+            AND ($4::text = '' OR p.name ILIKE '%' || $4 || '%')
+            AND ($5::timestamptz IS NULL OR (p.created_at, p.id) < ($5, $6::bigint))
+            ORDER BY p.created_at DESC, p.id DESC
+            LIMIT $7
+        )` + poiSelect + `
+        ORDER BY np.created_at DESC, np.id DESC, f.is_short DESC, f.serial_number ASC
+    `
+
+	rows, err := r.db.QueryContext(ctx, query,
+		pq.Array(filter.Interests),
+		filter.CreatedFrom,
+		filter.CreatedTo,
+		escapeLike(filter.Name),
+		afterCreatedAt,
+		afterID,
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("database query error: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanPOIsWithFiles(rows)
+}
+
+// FindPOIsInArea returns up to query.Limit POIs inside a bounding box or a
+// polygon, newest first. Both tests use the GIST index on location.
+func (r *POIRepository) FindPOIsInArea(ctx context.Context, query domain.AreaQuery) ([]*domain.PointOfInterest, error) {
+	args := []any{pq.Array(query.Interests), query.Limit}
+
+	var areaCondition string
+	switch {
+	case query.BoundingBox != nil:
+		areaCondition = "p.location && ST_MakeEnvelope($3, $4, $5, $6, 4326)"
+		args = append(args,
+			query.BoundingBox.MinLongitude,
+			query.BoundingBox.MinLatitude,
+			query.BoundingBox.MaxLongitude,
+			query.BoundingBox.MaxLatitude,
+		)
+	case query.Polygon != nil:
+		areaCondition = "ST_Intersects(p.location, ST_SetSRID(ST_GeomFromGeoJSON($3), 4326))"
+		args = append(args, string(query.Polygon))
+	default:
+		return nil, fmt.Errorf("area query needs a bounding box or a polygon")
+	}
+
+	sqlQuery := `
+        WITH page AS (
+            SELECT p.id, p.created_at,` + poiUnmeasured + `
+            FROM points_of_interest p
+            WHERE ` + areaCondition + `
+            AND ` + interestsFilter(1) + `
+            ORDER BY p.created_at DESC, p.id DESC
+            LIMIT $2
+        )` + poiSelect + `
+        ORDER BY np.created_at DESC, np.id DESC, f.is_short DESC, f.serial_number ASC
+    `
+
+	rows, err := r.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("database query error: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanPOIsWithFiles(rows)
+}
+
+// FindPOIsAlongRoute returns up to query.Limit POIs within query.Width
+// meters of a route, in the order they are passed. Every POI carries its
+// distance from the route and the distance along the route to the closest
+// point of the route.
+func (r *POIRepository) FindPOIsAlongRoute(ctx context.Context, query domain.CorridorQuery) ([]*domain.PointOfInterest, error) {
+	line, err := json.Marshal(map[string]any{
+		"type":        "LineString",
+		"coordinates": query.Line,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal route: %w", err)
+	}
+
+	// The planar distance in degrees lets the GIST index on location narrow
+	// down the candidates. A degree of longitude is shortest at the latitude
+	// farthest from the equator, so the corridor is widened for it.
+	maxLatitude := 0.0
+	for _, position := range query.Line {
+		maxLatitude = math.Max(maxLatitude, math.Abs(position[1]))
+	}
+	searchDegrees := query.Width / (metersPerDegree * math.Cos(math.Min(maxLatitude, 89)*math.Pi/180))
+
+	sqlQuery := `
+        WITH route AS (
+            SELECT ST_SetSRID(ST_GeomFromGeoJSON($1), 4326) as line
+        ),
+        page AS (
+            SELECT
+                p.id,
+                ST_Distance(p.location::geography, route.line::geography) as distance_meters,
+                NULL::double precision as bearing,
+                ST_Length(ST_LineSubstring(
+                    route.line, 0, ST_LineLocatePoint(route.line, p.location)
+                )::geography) as along_track_meters,
+                NULL::double precision as geofence_meters
+            FROM points_of_interest p
+            CROSS JOIN route
+            WHERE ST_DWithin(p.location, route.line, $2)
+            AND ST_DWithin(p.location::geography, route.line::geography, $3)
+            AND ` + interestsFilter(4) + `
+            ORDER BY along_track_meters ASC, p.id ASC
+            LIMIT $5
+        )` + poiSelect + `
+        ORDER BY np.along_track_meters ASC, np.id ASC, f.is_short DESC, f.serial_number ASC
+    `
+
+	rows, err := r.db.QueryContext(ctx, sqlQuery,
+		string(line),
+		searchDegrees,
+		query.Width,
+		pq.Array(query.Interests),
+		query.Limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("database query error: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanPOIsWithFiles(rows)
+}
+
+// ClusterPOIs groups the POIs of a bounding box by snapping them to a grid
+// of query.CellSize degrees. Each cluster gets the centroid of its POIs, the
+// extent and the number of POIs per interest. Up to query.Limit clusters are
+// returned, largest first.
+func (r *POIRepository) ClusterPOIs(ctx context.Context, query domain.ClusterQuery) ([]*domain.POICluster, error) {
+	sqlQuery := `
+        WITH filtered AS (
+            SELECT
+                p.id,
+                p.location,
+                ST_SnapToGrid(p.location, $5::double precision) as cell
+            FROM points_of_interest p
+            WHERE p.location && ST_MakeEnvelope($1, $2, $3, $4, 4326)
+            AND ` + interestsFilter(6) + `
+        ),
+        clusters AS (
+            SELECT
+                ST_X(cell) as cell_x,
+                ST_Y(cell) as cell_y,
+                count(*) as poi_count,
+                ST_Centroid(ST_Collect(location)) as centroid,
+                ST_Extent(location) as extent,
+                min(id) as first_id
+            FROM filtered
+            GROUP BY ST_X(cell), ST_Y(cell)
+            ORDER BY poi_count DESC, first_id
+            LIMIT $7
+        ),
+        breakdown AS (
+            SELECT cell_x, cell_y, json_object_agg(interest, interest_count) as interests
+            FROM (
+                SELECT
+                    ST_X(f.cell) as cell_x,
+                    ST_Y(f.cell) as cell_y,
+                    pt.type_of_interest_id as interest,
+                    count(*) as interest_count
+                FROM filtered f
+                JOIN points_of_interest_type pt ON pt.point_of_interest_id = f.id
+                GROUP BY 1, 2, 3
+            ) counted
+            GROUP BY cell_x, cell_y
+        )
+        SELECT
+            ST_Y(c.centroid),
+            ST_X(c.centroid),
+            c.poi_count,
+            ST_XMin(c.extent),
+            ST_YMin(c.extent),
+            ST_XMax(c.extent),
+            ST_YMax(c.extent),
+            c.first_id,
+            COALESCE(b.interests, '{}'::json)
+        FROM clusters c
+        LEFT JOIN breakdown b ON b.cell_x = c.cell_x AND b.cell_y = c.cell_y
+        ORDER BY c.poi_count DESC, c.first_id
+    `
+	rows, err := r.db.QueryContext(ctx, sqlQuery,
+		query.BoundingBox.MinLongitude,
+		query.BoundingBox.MinLatitude,
+		query.BoundingBox.MaxLongitude,
+		query.BoundingBox.MaxLatitude,
+		query.CellSize,
+		pq.Array(query.Interests),
+		query.Limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("database query error: %w", err)
+	}
+	defer rows.Close()
+
+	clusters := make([]*domain.POICluster, 0)
+	for rows.Next() {
+		var cluster domain.POICluster
+		var firstID int64
+		var interestsJSON []byte
+
+		err := rows.Scan(
+			&cluster.Latitude,
+			&cluster.Longitude,
+			&cluster.Count,
+			&cluster.BoundingBox.MinLongitude,
+			&cluster.BoundingBox.MinLatitude,
+			&cluster.BoundingBox.MaxLongitude,
+			&cluster.BoundingBox.MaxLatitude,
+			&firstID,
+			&interestsJSON,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan cluster: %w", err)
+		}
+
+		if err := json.Unmarshal(interestsJSON, &cluster.Interests); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal interests JSON: %w", err)
+		}
+		if cluster.Count == 1 {
+			cluster.POIID = &firstID
+		}
+
+		clusters = append(clusters, &cluster)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return clusters, nil
+}
+
+// FindNearestPOIs returns up to query.Limit POIs whose trigger area contains
+// the given point, skipping the first query.Offset ones. The trigger area is
+// the geofence of the POI, or a circle of query.Radius meters around POIs
+// without one. Every POI carries its distance, the compass bearing (degrees
+// clockwise from north) from the given point and the distance to its
+// trigger area.
+//
+// Without a heading POIs are ordered by distance. With a heading only POIs
+// inside a cone in front of the traveler are returned, ordered by distance
+// to a look-ahead point. The look-ahead point lies in the direction of
+// travel as far as the traveler moves at query.Speed while the short audio
+// of the POI is playing, so the story ends around arrival. The trigger area
+// is widened by the same lead.
+func (r *POIRepository) FindNearestPOIs(ctx context.Context, query domain.NearbyQuery) ([]*domain.PointOfInterest, error) {
+	// The search area grows by the margin and the longest possible
+	// look-ahead, the exact per-POI lead is checked afterwards.
+	extraMeters := query.Margin
+	if query.Heading != nil && query.Speed != nil {
+		extraMeters += math.Min(*query.Speed*maxShortAudioSeconds, maxLookAheadMeters)
+	}
+
+	// The planar distance in degrees lets the GIST indexes on location and
+	// geofence narrow down the candidates, as in FindPOIsAlongRoute.
+	searchMeters := math.Max(float64(query.Radius), MaxTriggerRadius) + extraMeters
+	searchLatitude := math.Min(math.Abs(query.Latitude)+searchMeters/metersPerDegree, 89)
+	searchDegrees := searchMeters / (metersPerDegree * math.Cos(searchLatitude*math.Pi/180))
+
+	sqlQuery := `
+        WITH candidates AS (
+            SELECT
+                p.id,
+                p.location,
                 ST_Distance(
                     p.location::geography, 
                     ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography
-                ) as distance_meters
+                ) as distance_meters,
+                degrees(ST_Azimuth(
+                    ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography,
+                    p.location::geography
+                )) as bearing,
+                CASE
+                    WHEN p.geofence IS NOT NULL THEN ST_Distance(
+                        p.geofence::geography,
+                        ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography
+                    )
+                    ELSE GREATEST(
+                        ST_Distance(
+                            p.location::geography,
+                            ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography
+                        ) - COALESCE(p.trigger_radius, $14::double precision),
+                        0
+                    )
+                END as geofence_meters,
+                LEAST(
+                    COALESCE($8::double precision, 0) * LEAST(
+                        COALESCE(
+                            (
+                                SELECT sf.file_size * 8 / $11::double precision
+                                FROM poi_files sf
+                                WHERE sf.poi_id = p.id AND sf.is_short
+                                LIMIT 1
+                            ),
+                            $10::double precision
+                        ),
+                        $12::double precision
+                    ),
+                    $13::double precision
+                ) as lead_meters
             FROM points_of_interest p
-			LEFT JOIN points_of_interest_type pt ON p.id = pt.point_of_interest_id
-            LEFT JOIN type_of_interest t ON pt.type_of_interest_id = t.id
-			WHERE ST_DWithin(
-				p.location::geography,
-				ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography,
-				$3
+			WHERE (
+				ST_DWithin(p.location, ST_SetSRID(ST_MakePoint($1, $2), 4326), $18)
+				OR ST_DWithin(p.geofence, ST_SetSRID(ST_MakePoint($1, $2), 4326), $18)
 			)
-			AND (
-				array_length($4::text[], 1) IS NULL
-				OR array_length($4, 1) = 0
-				OR EXISTS (
-				SELECT 1 
-				FROM points_of_interest_type pt2
-				WHERE pt2.point_of_interest_id = p.id
-				AND pt2.type_of_interest_id = ANY($4::text[])
-                )
-    		)
-			GROUP BY p.id
-			ORDER BY p.location <-> ST_SetSRID(ST_MakePoint($1, $2), 4326)
-            LIMIT 1
-        )
-        SELECT 
-            np.id, np.name, np.description, np.latitude, np.longitude, np.created_at, np.interests,
-            f.id, f.s3_key, f.file_name, f.file_size, f.mime_type, f.serial_number, f.is_short, f.created_at
-        FROM nearest_poi np
-        LEFT JOIN poi_files f ON np.id = f.poi_id
-        ORDER BY f.is_short DESC, f.serial_number ASC
+			AND CASE
+				WHEN p.geofence IS NOT NULL THEN ST_DWithin(
+					p.geofence::geography,
+					ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography,
+					$3
+				)
+				ELSE ST_DWithin(
+					p.location::geography,
+					ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography,
+					COALESCE(p.trigger_radius, $14::double precision) + $3
+				)
+			END
+			AND ` + interestsFilter(4) + `
+			AND ($16::bigint[] IS NULL OR p.id <> ALL($16::bigint[]))
+        ),
+        page AS (
+            SELECT
+                c.id,
+                c.distance_meters,
+                c.bearing,
+                NULL::double precision as along_track_meters,
+                c.geofence_meters,
+                CASE
+                    WHEN $7::double precision IS NULL THEN c.distance_meters
+                    ELSE ST_Distance(
+                        c.location::geography,
+                        ST_Project(
+                            ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography,
+                            c.lead_meters,
+                            radians($7::double precision)
+                        )
+                    )
+                END as rank_meters
+            FROM candidates c
+            WHERE c.geofence_meters <= $17::double precision + c.lead_meters
+            AND (
+                $7::double precision IS NULL
+                OR c.bearing IS NULL
+                OR c.distance_meters <= $15::double precision
+                OR c.geofence_meters = 0
+                OR degrees(acos(cos(radians(c.bearing - $7::double precision)))) <= $9::double precision
+            )
+            ORDER BY rank_meters ASC, c.id ASC
+            LIMIT $5 OFFSET $6
+        )` + poiSelect + `
+        ORDER BY np.rank_meters ASC, np.id ASC, f.is_short DESC, f.serial_number ASC
     `
 
-	rows, err := r.db.Query(query, longitude, latitude, radius, pq.Array(interests))
+	rows, err := r.db.QueryContext(ctx, sqlQuery,
+		query.Longitude,
+		query.Latitude,
+		extraMeters,
+		pq.Array(query.Interests),
+		query.Limit,
+		query.Offset,
+		query.Heading,
+		query.Speed,
+		lookAheadHalfAngle,
+		defaultShortAudioSeconds,
+		shortAudioBitsPerSecond,
+		maxShortAudioSeconds,
+		maxLookAheadMeters,
+		query.Radius,
+		passingDistanceMeters,
+		pq.Array(query.ExcludeIDs),
+		query.Margin,
+		searchDegrees,
+	)
 	if err != nil {
 		return nil, fmt.Errorf("database query error: %w", err)
 	}
 	defer rows.Close()
 
-	poi, err := r.scanPOIWithFiles(rows)
-	if err != nil {
-		return nil, err
-	}
-
-	return poi, nil
+	return r.scanPOIsWithFiles(rows)
 }
 
-func (r *POIRepository) scanPOIWithFiles(rows *sql.Rows) (*domain.PointOfInterest, error) {
-	var poi *domain.PointOfInterest
+// scanPOIsWithFiles maps rows of a POI joined with its files into POIs,
+// keeping the order in which the POIs first appear.
+func (r *POIRepository) scanPOIsWithFiles(rows *sql.Rows) ([]*domain.PointOfInterest, error) {
+	pois := make([]*domain.PointOfInterest, 0)
+	poisByID := make(map[int64]*domain.PointOfInterest)
 
 	for rows.Next() {
 		var tempID int64
@@ -133,6 +564,9 @@ func (r *POIRepository) scanPOIWithFiles(rows *sql.Rows) (*domain.PointOfInteres
 		var tempLatitude, tempLongitude float64
 		var tempCreatedAt time.Time
 		var tempInterestsJSON []byte
+		var tempDistance, tempBearing, tempAlongTrack sql.NullFloat64
+		var tempTriggerRadius, tempGeofenceMeters sql.NullFloat64
+		var tempGeofence sql.NullString
 
 		var fileID sql.NullInt64
 		var s3Key sql.NullString
@@ -142,6 +576,7 @@ func (r *POIRepository) scanPOIWithFiles(rows *sql.Rows) (*domain.PointOfInteres
 		var serialNumber sql.NullInt64
 		var isShort sql.NullBool
 		var fileCreatedAt sql.NullTime
+		var fileLanguage sql.NullString
 
 		err := rows.Scan(
 			&tempID,
@@ -151,6 +586,12 @@ func (r *POIRepository) scanPOIWithFiles(rows *sql.Rows) (*domain.PointOfInteres
 			&tempLongitude,
 			&tempCreatedAt,
 			&tempInterestsJSON,
+			&tempDistance,
+			&tempBearing,
+			&tempAlongTrack,
+			&tempTriggerRadius,
+			&tempGeofence,
+			&tempGeofenceMeters,
 			&fileID,
 			&s3Key,
 			&fileName,
@@ -159,12 +600,14 @@ func (r *POIRepository) scanPOIWithFiles(rows *sql.Rows) (*domain.PointOfInteres
 			&serialNumber,
 			&isShort,
 			&fileCreatedAt,
+			&fileLanguage,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("scan error: %w", err)
 		}
 
-		if poi == nil {
+		poi, ok := poisByID[tempID]
+		if !ok {
 			var interests []string
 			if len(tempInterestsJSON) > 0 {
 				if err := json.Unmarshal(tempInterestsJSON, &interests); err != nil {
@@ -182,6 +625,27 @@ func (r *POIRepository) scanPOIWithFiles(rows *sql.Rows) (*domain.PointOfInteres
 				Interests:      interests,
 				FullAudioFiles: []*domain.File{},
 			}
+			if tempDistance.Valid {
+				poi.DistanceMeters = &tempDistance.Float64
+			}
+			if tempBearing.Valid {
+				poi.Bearing = &tempBearing.Float64
+			}
+			if tempAlongTrack.Valid {
+				poi.AlongTrackMeters = &tempAlongTrack.Float64
+			}
+			if tempTriggerRadius.Valid {
+				poi.Geofence = &domain.Geofence{Radius: &tempTriggerRadius.Float64}
+			}
+			if tempGeofence.Valid {
+				poi.Geofence = &domain.Geofence{Polygon: json.RawMessage(tempGeofence.String)}
+			}
+			if tempGeofenceMeters.Valid {
+				poi.GeofenceMeters = &tempGeofenceMeters.Float64
+			}
+
+			poisByID[tempID] = poi
+			pois = append(pois, poi)
 		}
 
 		if fileID.Valid {
@@ -194,15 +658,10 @@ func (r *POIRepository) scanPOIWithFiles(rows *sql.Rows) (*domain.PointOfInteres
 				SerialNumber: serialNumber.Int64,
 				IsShort:      isShort.Bool,
 				CreatedAt:    fileCreatedAt.Time,
+				Language:     fileLanguage.String,
 			}
 
-			if file.SerialNumber == 0 { // If it is image
-				poi.ImageFile = file
-			} else if file.IsShort { // If it is short audio
-				poi.ShortAudioFile = file
-			} else { // If it is full audio
-				poi.FullAudioFiles = append(poi.FullAudioFiles, file)
-			}
+			attachFile(poi, file)
 		}
 	}
 
@@ -210,11 +669,128 @@ func (r *POIRepository) scanPOIWithFiles(rows *sql.Rows) (*domain.PointOfInteres
 		return nil, fmt.Errorf("rows error: %w", err)
 	}
 
-	if poi == nil {
-		return nil, fmt.Errorf("no points of interest found")
+	for _, poi := range pois {
+		poi.SelectAudio()
 	}
 
-	return poi, nil
+	return pois, nil
+}
+
+// attachFile sets the image of the POI or adds an audio track. SelectAudio
+// picks the short and full audio among the tracks afterwards.
+func attachFile(poi *domain.PointOfInterest, file *domain.File) {
+	if file.SerialNumber == 0 { // If it is image
+		poi.ImageFile = file
+	} else {
+		poi.AudioFiles = append(poi.AudioFiles, file)
+	}
+}
+
+// StreamPOIs calls fn for every POI matching the filter, ordered by id.
+// Rows are read one by one, so the whole catalog is never held in memory.
+func (r *POIRepository) StreamPOIs(ctx context.Context, filter domain.POIExportFilter, fn func(*domain.PointOfInterest) error) error {
+	var minLon, minLat, maxLon, maxLat *float64
+	if filter.BoundingBox != nil {
+		minLon = &filter.BoundingBox.MinLongitude
+		minLat = &filter.BoundingBox.MinLatitude
+		maxLon = &filter.BoundingBox.MaxLongitude
+		maxLat = &filter.BoundingBox.MaxLatitude
+	}
+
+	query := `
+		SELECT
+			p.id,
+			p.name,
+			p.description,
+			ST_Y(p.location) as latitude,
+			ST_X(p.location) as longitude,
+			p.created_at,
+			COALESCE(
+				(
+					SELECT json_agg(pt.type_of_interest_id ORDER BY pt.type_of_interest_id)
+					FROM points_of_interest_type pt
+					WHERE pt.point_of_interest_id = p.id
+				),
+				'[]'::json
+			) as interests,
+			COALESCE(
+				(
+					SELECT json_agg(json_build_object(
+						'id', f.id,
+						's3_key', f.s3_key,
+						'file_name', f.file_name,
+						'file_size', f.file_size,
+						'mime_type', f.mime_type,
+						'serial_number', f.serial_number,
+						'is_short', f.is_short,
+						'created_at', f.created_at,
+						'language', f.language
+					) ORDER BY f.is_short DESC, f.serial_number ASC)
+					FROM poi_files f
+					WHERE f.poi_id = p.id
+				),
+				'[]'::json
+			) as files
+		FROM points_of_interest p
+		WHERE ` + interestsFilter(1) + `
+		AND (
+			$2::double precision IS NULL
+			OR p.location && ST_MakeEnvelope($2, $3, $4, $5, 4326)
+		)
+		ORDER BY p.id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(filter.Interests), minLon, minLat, maxLon, maxLat)
+	if err != nil {
+		return fmt.Errorf("database query error: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var description sql.NullString
+		var createdAt sql.NullTime
+		var interestsJSON, filesJSON []byte
+
+		poi := &domain.PointOfInterest{FullAudioFiles: []*domain.File{}}
+		err := rows.Scan(
+			&poi.ID,
+			&poi.Name,
+			&description,
+			&poi.Latitude,
+			&poi.Longitude,
+			&createdAt,
+			&interestsJSON,
+			&filesJSON,
+		)
+		if err != nil {
+			return fmt.Errorf("scan error: %w", err)
+		}
+		poi.Description = description.String
+		poi.CreatedAt = createdAt.Time
+
+		if err := json.Unmarshal(interestsJSON, &poi.Interests); err != nil {
+			return fmt.Errorf("failed to unmarshal interests JSON: %w", err)
+		}
+
+		var files []*domain.File
+		if err := json.Unmarshal(filesJSON, &files); err != nil {
+			return fmt.Errorf("failed to unmarshal files JSON: %w", err)
+		}
+		for _, file := range files {
+			attachFile(poi, file)
+		}
+		poi.SelectAudio()
+
+		if err := fn(poi); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows error: %w", err)
+	}
+
+	return nil
 }
 
 func (r *POIRepository) CreatePOI(ctx context.Context, poi *domain.PointOfInterest) (*domain.PointOfInterest, error) {
@@ -226,115 +802,368 @@ func (r *POIRepository) CreatePOI(ctx context.Context, poi *domain.PointOfIntere
 
 	var poiID int64
 	poiQuery := `
-		INSERT INTO points_of_interest (name, description, location, created_at)
-		VALUES ($1, $2, ST_SetSRID(ST_MakePoint($3, $4), 4326), $5)
+		INSERT INTO points_of_interest (name, description, location, created_at, trigger_radius, geofence)
+		VALUES ($1, $2, ST_SetSRID(ST_MakePoint($3, $4), 4326), $5, $6, ST_SetSRID(ST_GeomFromGeoJSON($7::text), 4326))
 		RETURNING id
 	`
 
+	triggerRadius, geofence := geofenceArgs(poi.Geofence)
 	err = tx.QueryRowContext(ctx, poiQuery,
 		poi.Name,
 		poi.Description,
 		poi.Longitude,
 		poi.Latitude,
 		poi.CreatedAt,
+		triggerRadius,
+		geofence,
 	).Scan(&poiID)
+	if isGeofenceViolation(err) {
+		return nil, ErrInvalidGeofence
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to insert POI: %w", err)
 	}
 
-	var imageID int64
-	imageQuery := `
-		INSERT INTO poi_files (poi_id, s3_key, file_name, file_size, mime_type, serial_number, is_short, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-		RETURNING id
-	`
-
-	err = tx.QueryRowContext(ctx, imageQuery,
-		poiID,
-		poi.ImageFile.S3Key,
-		poi.ImageFile.FileName,
-		poi.ImageFile.FileSize,
-		poi.ImageFile.MimeType,
-		poi.ImageFile.SerialNumber,
-		poi.ImageFile.IsShort,
-		poi.ImageFile.CreatedAt,
-	).Scan(&imageID)
+	poi.ImageFile.ID, err = r.insertFile(ctx, tx, poiID, poi.ImageFile)
 	if err != nil {
 		return nil, fmt.Errorf("failed to insert image file: %w", err)
 	}
-	poi.ImageFile.ID = imageID
 
 	if poi.ShortAudioFile != nil {
-		var shortAudioID int64
-		err = tx.QueryRowContext(ctx, imageQuery,
-			poiID,
-			poi.ShortAudioFile.S3Key,
-			poi.ShortAudioFile.FileName,
-			poi.ShortAudioFile.FileSize,
-			poi.ShortAudioFile.MimeType,
-			poi.ShortAudioFile.SerialNumber,
-			poi.ShortAudioFile.IsShort,
-			poi.ShortAudioFile.CreatedAt,
-		).Scan(&shortAudioID)
+		poi.ShortAudioFile.ID, err = r.insertFile(ctx, tx, poiID, poi.ShortAudioFile)
 		if err != nil {
 			return nil, fmt.Errorf("failed to insert short audio file: %w", err)
 		}
-		poi.ShortAudioFile.ID = shortAudioID
 	}
 
 	for _, fullAudio := range poi.FullAudioFiles {
-		var fullAudioID int64
-		err = tx.QueryRowContext(ctx, imageQuery,
-			poiID,
-			fullAudio.S3Key,
-			fullAudio.FileName,
-			fullAudio.FileSize,
-			fullAudio.MimeType,
-			fullAudio.SerialNumber,
-			fullAudio.IsShort,
-			fullAudio.CreatedAt,
-		).Scan(&fullAudioID)
+		fullAudio.ID, err = r.insertFile(ctx, tx, poiID, fullAudio)
 		if err != nil {
 			return nil, fmt.Errorf("failed to insert full audio file: %w", err)
 		}
-		fullAudio.ID = fullAudioID
 	}
 
-	if len(poi.Interests) > 0 {
-		interestsQuery := `
-        INSERT INTO points_of_interest_type (point_of_interest_id, type_of_interest_id)
-        VALUES `
+	if err = r.insertInterests(ctx, tx, poiID, poi.Interests); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	poi.ID = poiID
+
+	return poi, nil
+}
+
+// UpdatePOI applies update to the POI inside one transaction and returns
+// the S3 keys of the files that were replaced or removed, so the caller can
+// delete them from storage once the transaction has committed.
+func (r *POIRepository) UpdatePOI(ctx context.Context, idPOI int64, update *domain.POIUpdate) ([]string, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
 
-		values := []any{}
-		placeholders := []string{}
+	var lockedID int64
+	err = tx.QueryRowContext(ctx, "SELECT id FROM points_of_interest WHERE id = $1 FOR UPDATE", idPOI).Scan(&lockedID)
+	if err == sql.ErrNoRows {
+		return nil, ErrPOINotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock POI: %w", err)
+	}
 
-		for i, interest := range poi.Interests {
-			placeholders = append(placeholders, fmt.Sprintf("($%d, $%d)", i*2+1, i*2+2))
-			values = append(values, poiID, interest)
+	poiQuery := `
+		UPDATE points_of_interest
+		SET
+			name = COALESCE($2, name),
+			description = COALESCE($3, description),
+			location = CASE
+				WHEN $4::double precision IS NULL THEN location
+				ELSE ST_SetSRID(ST_MakePoint($4, $5), 4326)
+			END,
+			trigger_radius = CASE WHEN $6::boolean THEN $7::double precision ELSE trigger_radius END,
+			geofence = CASE
+				WHEN $6::boolean THEN ST_SetSRID(ST_GeomFromGeoJSON($8::text), 4326)
+				ELSE geofence
+			END
+		WHERE id = $1
+	`
+	triggerRadius, geofence := geofenceArgs(update.Geofence)
+	_, err = tx.ExecContext(ctx, poiQuery,
+		idPOI,
+		update.Name,
+		update.Description,
+		update.Longitude,
+		update.Latitude,
+		update.Geofence != nil,
+		triggerRadius,
+		geofence,
+	)
+	if isGeofenceViolation(err) {
+		return nil, ErrInvalidGeofence
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update POI: %w", err)
+	}
+
+	if update.Interests != nil {
+		_, err = tx.ExecContext(ctx, "DELETE FROM points_of_interest_type WHERE point_of_interest_id = $1", idPOI)
+		if err != nil {
+			return nil, fmt.Errorf("failed to delete interests: %w", err)
 		}
+		if err = r.insertInterests(ctx, tx, idPOI, update.Interests); err != nil {
+			return nil, err
+		}
+	}
 
-		interestsQuery += strings.Join(placeholders, ", ")
-		interestsQuery += `
-        ON CONFLICT (point_of_interest_id, type_of_interest_id) 
-        DO NOTHING`
+	removedKeys := make([]string, 0)
+
+	if update.ImageFile != nil {
+		keys, err := r.deleteFiles(ctx, tx,
+			"DELETE FROM poi_files WHERE poi_id = $1 AND serial_number = 0 RETURNING s3_key", idPOI)
+		if err != nil {
+			return nil, fmt.Errorf("failed to delete old image file: %w", err)
+		}
+		removedKeys = append(removedKeys, keys...)
 
-		_, err = tx.ExecContext(ctx, interestsQuery, values...)
+		update.ImageFile.ID, err = r.insertFile(ctx, tx, idPOI, update.ImageFile)
 		if err != nil {
-			return nil, fmt.Errorf("failed to insert interests: %w", err)
+			return nil, fmt.Errorf("failed to insert image file: %w", err)
 		}
 	}
 
+	// Audio changes only touch the tracks of one language.
+	language := update.AudioLanguage
+	if language == "" {
+		language = domain.DefaultLanguage
+	}
+
+	if update.ShortAudioFile != nil {
+		keys, err := r.deleteFiles(ctx, tx, `
+			DELETE FROM poi_files
+			WHERE poi_id = $1 AND is_short AND serial_number <> 0 AND language = $2
+			RETURNING s3_key`,
+			idPOI, language)
+		if err != nil {
+			return nil, fmt.Errorf("failed to delete old short audio file: %w", err)
+		}
+		removedKeys = append(removedKeys, keys...)
+
+		update.ShortAudioFile.Language = language
+		update.ShortAudioFile.ID, err = r.insertFile(ctx, tx, idPOI, update.ShortAudioFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to insert short audio file: %w", err)
+		}
+	}
+
+	if len(update.RemoveFullAudioIDs) == 0 && len(update.FullAudioOrder) == 0 && len(update.NewFullAudioFiles) == 0 {
+		if err = tx.Commit(); err != nil {
+			return nil, fmt.Errorf("failed to commit transaction: %w", err)
+		}
+		return removedKeys, nil
+	}
+
+	if len(update.RemoveFullAudioIDs) > 0 {
+		keys, err := r.deleteFiles(ctx, tx, `
+			DELETE FROM poi_files
+			WHERE poi_id = $1 AND id = ANY($2) AND NOT is_short AND serial_number > 0 AND language = $3
+			RETURNING s3_key`,
+			idPOI, pq.Array(update.RemoveFullAudioIDs), language)
+		if err != nil {
+			return nil, fmt.Errorf("failed to delete full audio files: %w", err)
+		}
+		if len(keys) != len(update.RemoveFullAudioIDs) {
+			return nil, fmt.Errorf("%w: some full audio files to remove do not belong to POI %d in %s", ErrInvalidAudioChange, idPOI, language)
+		}
+		removedKeys = append(removedKeys, keys...)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id FROM poi_files
+		WHERE poi_id = $1 AND NOT is_short AND serial_number > 0 AND language = $2
+		ORDER BY serial_number ASC, id ASC`, idPOI, language)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get full audio files: %w", err)
+	}
+	currentOrder := make([]int64, 0)
+	for rows.Next() {
+		var fileID int64
+		if err := rows.Scan(&fileID); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		currentOrder = append(currentOrder, fileID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	order := currentOrder
+	if len(update.FullAudioOrder) > 0 {
+		if !samePermutation(update.FullAudioOrder, currentOrder) {
+			return nil, fmt.Errorf("%w: full audio order must list every remaining full audio file of POI %d in %s exactly once", ErrInvalidAudioChange, idPOI, language)
+		}
+		order = update.FullAudioOrder
+	}
+
+	for i, fullAudio := range update.NewFullAudioFiles {
+		fullAudio.IsShort = false
+		fullAudio.SerialNumber = int64(len(order) + i + 1)
+		fullAudio.Language = language
+		fullAudio.ID, err = r.insertFile(ctx, tx, idPOI, fullAudio)
+		if err != nil {
+			return nil, fmt.Errorf("failed to insert full audio file: %w", err)
+		}
+		order = append(order, fullAudio.ID)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE poi_files f
+		SET serial_number = o.ord
+		FROM unnest($1::bigint[]) WITH ORDINALITY AS o(id, ord)
+		WHERE f.id = o.id`, pq.Array(order))
+	if err != nil {
+		return nil, fmt.Errorf("failed to reorder full audio files: %w", err)
+	}
+
 	if err = tx.Commit(); err != nil {
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	poi.ID = poiID
+	return removedKeys, nil
+}
 
-	return poi, nil
+// isGeofenceViolation reports whether err is a geofence failing
+// chk_poi_geofence_valid.
+func isGeofenceViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Constraint == "chk_poi_geofence_valid"
 }
 
-func (r *POIRepository) DeletePOI(idPOI int, filesIds []int64) (bool, error) {
-	tx, err := r.db.Begin()
+// geofenceArgs splits a geofence into the trigger_radius and the GeoJSON of
+// the geofence column, nil for the unset one.
+func geofenceArgs(geofence *domain.Geofence) (*float64, *string) {
+	if geofence == nil {
+		return nil, nil
+	}
+	if len(geofence.Polygon) > 0 {
+		polygon := string(geofence.Polygon)
+		return nil, &polygon
+	}
+	return geofence.Radius, nil
+}
+
+// insertFile stores a file of the POI. Audio without a language is taken as
+// audio in the default language.
+func (r *POIRepository) insertFile(ctx context.Context, tx *sql.Tx, poiID int64, file *domain.File) (int64, error) {
+	query := `
+		INSERT INTO poi_files (poi_id, s3_key, file_name, file_size, mime_type, serial_number, is_short, created_at, language)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id
+	`
+
+	var language *string
+	if file.SerialNumber != 0 {
+		if file.Language == "" {
+			file.Language = domain.DefaultLanguage
+		}
+		language = &file.Language
+	}
+
+	var fileID int64
+	err := tx.QueryRowContext(ctx, query,
+		poiID,
+		file.S3Key,
+		file.FileName,
+		file.FileSize,
+		file.MimeType,
+		file.SerialNumber,
+		file.IsShort,
+		file.CreatedAt,
+		language,
+	).Scan(&fileID)
+	if err != nil {
+		return 0, err
+	}
+
+	return fileID, nil
+}
+
+func (r *POIRepository) insertInterests(ctx context.Context, tx *sql.Tx, poiID int64, interests []string) error {
+	if len(interests) == 0 {
+		return nil
+	}
+
+	interestsQuery := `
+        INSERT INTO points_of_interest_type (point_of_interest_id, type_of_interest_id)
+        VALUES `
+
+	values := []any{}
+	placeholders := []string{}
+
+	for i, interest := range interests {
+		placeholders = append(placeholders, fmt.Sprintf("($%d, $%d)", i*2+1, i*2+2))
+		values = append(values, poiID, interest)
+	}
+
+	interestsQuery += strings.Join(placeholders, ", ")
+	interestsQuery += `
+        ON CONFLICT (point_of_interest_id, type_of_interest_id) 
+        DO NOTHING`
+
+	_, err := tx.ExecContext(ctx, interestsQuery, values...)
+	if err != nil {
+		return fmt.Errorf("failed to insert interests: %w", err)
+	}
+
+	return nil
+}
+
+// deleteFiles runs a DELETE ... RETURNING s3_key query and collects the keys.
+func (r *POIRepository) deleteFiles(ctx context.Context, tx *sql.Tx, query string, args ...any) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make([]string, 0)
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+func samePermutation(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	counts := make(map[int64]int, len(a))
+	for _, id := range b {
+		counts[id]++
+	}
+	for _, id := range a {
+		if counts[id] == 0 {
+			return false
+		}
+		counts[id]--
+	}
+
+	return true
+}
+
+func (r *POIRepository) DeletePOI(ctx context.Context, idPOI int, filesIds []int64) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return false, fmt.Errorf("failed to begin transaction: %w", err)
 	}
@@ -342,13 +1171,13 @@ func (r *POIRepository) DeletePOI(idPOI int, filesIds []int64) (bool, error) {
 	defer tx.Rollback()
 
 	if len(filesIds) > 0 {
-		_, err = tx.Exec("DELETE FROM poi_files WHERE id = ANY($1)", pq.Array(filesIds))
+		_, err = tx.ExecContext(ctx, "DELETE FROM poi_files WHERE id = ANY($1)", pq.Array(filesIds))
 		if err != nil {
 			return false, fmt.Errorf("failed to delete poi files: %w", err)
 		}
 	}
 
-	result, err := tx.Exec("DELETE FROM points_of_interest WHERE id = $1", idPOI)
+	result, err := tx.ExecContext(ctx, "DELETE FROM points_of_interest WHERE id = $1", idPOI)
 	if err != nil {
 		return false, fmt.Errorf("failed to delete poi: %w", err)
 	}
@@ -365,3 +1194,122 @@ func (r *POIRepository) DeletePOI(idPOI int, filesIds []int64) (bool, error) {
 
 	return rowsAffected > 0, nil
 }
+
+// GetTranslations returns the translations of the POIs into any of the
+// languages, keyed by POI id and language.
+func (r *POIRepository) GetTranslations(ctx context.Context, poiIDs []int64, languages []string) (map[int64]map[string]*domain.POITranslation, error) {
+	translations := make(map[int64]map[string]*domain.POITranslation, len(poiIDs))
+	if len(poiIDs) == 0 || len(languages) == 0 {
+		return translations, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT poi_id, language, name, description
+		FROM poi_translations
+		WHERE poi_id = ANY($1::integer[]) AND language = ANY($2::text[])`,
+		pq.Array(poiIDs), pq.Array(languages),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query translations: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var poiID int64
+		var translation domain.POITranslation
+		if err := rows.Scan(&poiID, &translation.Language, &translation.Name, &translation.Description); err != nil {
+			return nil, fmt.Errorf("failed to scan translation: %w", err)
+		}
+		if translations[poiID] == nil {
+			translations[poiID] = make(map[string]*domain.POITranslation)
+		}
+		translations[poiID][translation.Language] = &translation
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read translations: %w", err)
+	}
+
+	return translations, nil
+}
+
+// ListTranslations returns every translation of the POI ordered by language.
+func (r *POIRepository) ListTranslations(ctx context.Context, poiID int64) ([]*domain.POITranslation, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM points_of_interest WHERE id = $1)", poiID).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check poi: %w", err)
+	}
+	if !exists {
+		return nil, ErrPOINotFound
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT language, name, description
+		FROM poi_translations
+		WHERE poi_id = $1
+		ORDER BY language`, poiID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query translations: %w", err)
+	}
+	defer rows.Close()
+
+	translations := make([]*domain.POITranslation, 0)
+	for rows.Next() {
+		var translation domain.POITranslation
+		if err := rows.Scan(&translation.Language, &translation.Name, &translation.Description); err != nil {
+			return nil, fmt.Errorf("failed to scan translation: %w", err)
+		}
+		translations = append(translations, &translation)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read translations: %w", err)
+	}
+
+	return translations, nil
+}
+
+// UpsertTranslation creates or replaces the translation of the POI into
+// translation.Language.
+func (r *POIRepository) UpsertTranslation(ctx context.Context, poiID int64, translation *domain.POITranslation) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO poi_translations (poi_id, language, name, description)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (poi_id, language) DO UPDATE
+		SET name = EXCLUDED.name, description = EXCLUDED.description, updated_at = CURRENT_TIMESTAMP`,
+		poiID, translation.Language, translation.Name, translation.Description,
+	)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Constraint == "fk_poi_translations_poi" {
+			return ErrPOINotFound
+		}
+		return fmt.Errorf("failed to save translation: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteTranslation removes the translation of the POI into language.
+func (r *POIRepository) DeleteTranslation(ctx context.Context, poiID int64, language string) error {
+	result, err := r.db.ExecContext(ctx,
+		"DELETE FROM poi_translations WHERE poi_id = $1 AND language = $2", poiID, language)
+	if err != nil {
+		return fmt.Errorf("failed to delete translation: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrTranslationNotFound
+	}
+
+	return nil
+}
+
+// escapeLike escapes the LIKE wildcards so value is matched literally.
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
@@ -0,0 +1,255 @@
+package repository
+
+import (
+	"aigpsservice/internal/domain"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+var (
+	ErrSessionNotFound     = errors.New("listening session not found")
+	ErrSessionFileNotFound = errors.New("played file does not belong to the point of interest")
+)
+
+type SessionRepository struct {
+	db *sql.DB
+}
+
+func NewSessionRepository(db *sql.DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+// heardPOIsQuery selects the POIs heard in session $1: the ones marked as
+// completed, the ones without audio and the ones with no audio left to play
+// in one of their languages.
+const heardPOIsQuery = `
+    SELECT sp.poi_id
+    FROM session_plays sp
+    WHERE sp.session_id = $1
+    GROUP BY sp.poi_id
+    HAVING bool_or(sp.file_id IS NULL)
+        OR NOT EXISTS (
+            SELECT 1
+            FROM poi_files f
+            WHERE f.poi_id = sp.poi_id
+            AND (f.is_short OR f.serial_number > 0)
+        )
+        OR EXISTS (
+            SELECT 1
+            FROM poi_files track
+            WHERE track.poi_id = sp.poi_id
+            AND (track.is_short OR track.serial_number > 0)
+            AND NOT EXISTS (
+                SELECT 1
+                FROM poi_files f
+                WHERE f.poi_id = sp.poi_id
+                AND f.language = track.language
+                AND (f.is_short OR f.serial_number > 0)
+                AND NOT EXISTS (
+                    SELECT 1
+                    FROM session_plays played
+                    WHERE played.session_id = $1 AND played.file_id = f.id
+                )
+            )
+        )
+`
+
+func (r *SessionRepository) CreateSession(ctx context.Context, session *domain.ListeningSession) (*domain.ListeningSession, error) {
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO listening_sessions (interests, radius, cooldown_seconds)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at, updated_at`,
+		pq.Array(session.Interests), session.Radius, session.CooldownSeconds,
+	).Scan(&session.ID, &session.CreatedAt, &session.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert session: %w", err)
+	}
+
+	session.HeardPOIIDs = []int64{}
+	return session, nil
+}
+
+// GetSession returns the session with the POIs heard in it.
+func (r *SessionRepository) GetSession(ctx context.Context, id string) (*domain.ListeningSession, error) {
+	var session domain.ListeningSession
+	var interests pq.StringArray
+	var latitude, longitude sql.NullFloat64
+	var lastPlayedAt sql.NullTime
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT
+			id, interests, radius, cooldown_seconds,
+			ST_Y(last_location), ST_X(last_location),
+			last_played_at, created_at, updated_at
+		FROM listening_sessions
+		WHERE id = $1`, id,
+	).Scan(
+		&session.ID,
+		&interests,
+		&session.Radius,
+		&session.CooldownSeconds,
+		&latitude,
+		&longitude,
+		&lastPlayedAt,
+		&session.CreatedAt,
+		&session.UpdatedAt,
+	)
+	if err != nil {
+		return nil, mapSessionError(err, "failed to get session")
+	}
+
+	session.Interests = interests
+	if latitude.Valid && longitude.Valid {
+		session.Latitude = &latitude.Float64
+		session.Longitude = &longitude.Float64
+	}
+	if lastPlayedAt.Valid {
+		session.LastPlayedAt = &lastPlayedAt.Time
+	}
+
+	session.HeardPOIIDs, err = r.queryIDs(ctx, heardPOIsQuery, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get heard POIs: %w", err)
+	}
+
+	return &session, nil
+}
+
+// UpdateLocation remembers the last position of the listener.
+func (r *SessionRepository) UpdateLocation(ctx context.Context, id string, latitude, longitude float64) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE listening_sessions
+		SET last_location = ST_SetSRID(ST_MakePoint($2, $3), 4326), updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1`,
+		id, longitude, latitude,
+	)
+	if err != nil {
+		return mapSessionError(err, "failed to update session location")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrSessionNotFound
+	}
+
+	return nil
+}
+
+// RecordPlay stores the played audio of a POI and starts the cooldown of
+// the session.
+func (r *SessionRepository) RecordPlay(ctx context.Context, id string, play *domain.SessionPlay) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE listening_sessions
+		SET last_played_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1`, id)
+	if err != nil {
+		return mapSessionError(err, "failed to update session")
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrSessionNotFound
+	}
+
+	if len(play.FileIDs) > 0 {
+		result, err = tx.ExecContext(ctx, `
+			INSERT INTO session_plays (session_id, poi_id, file_id)
+			SELECT $1, f.poi_id, f.id
+			FROM poi_files f
+			WHERE f.poi_id = $2 AND f.id = ANY($3::bigint[]) AND (f.is_short OR f.serial_number > 0)`,
+			id, play.POIID, pq.Array(play.FileIDs),
+		)
+		if err != nil {
+			return mapSessionError(err, "failed to insert played files")
+		}
+		rowsAffected, err = result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if rowsAffected != int64(len(play.FileIDs)) {
+			return ErrSessionFileNotFound
+		}
+	}
+
+	if play.Completed || len(play.FileIDs) == 0 {
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO session_plays (session_id, poi_id) VALUES ($1, $2)", id, play.POIID)
+		if err != nil {
+			return mapSessionError(err, "failed to insert played POI")
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// GetPlayedFileIDs returns the files of a POI played in the session.
+func (r *SessionRepository) GetPlayedFileIDs(ctx context.Context, id string, poiID int64) ([]int64, error) {
+	ids, err := r.queryIDs(ctx, `
+		SELECT DISTINCT file_id
+		FROM session_plays
+		WHERE session_id = $1 AND poi_id = $2 AND file_id IS NOT NULL`,
+		id, poiID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get played files: %w", err)
+	}
+	return ids, nil
+}
+
+func (r *SessionRepository) queryIDs(ctx context.Context, query string, args ...any) ([]int64, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make([]int64, 0)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// mapSessionError turns a missing session, a malformed session id and an
+// unknown POI into the repository errors.
+func mapSessionError(err error, message string) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrSessionNotFound
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch {
+		case pqErr.Code.Name() == "invalid_text_representation":
+			return ErrSessionNotFound
+		case pqErr.Constraint == "fk_session_plays_poi":
+			return ErrPOINotFound
+		}
+	}
+
+	return fmt.Errorf("%s: %w", message, err)
+}
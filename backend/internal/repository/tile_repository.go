@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+type TileRepository struct {
+	db *sql.DB
+}
+
+func NewTileRepository(db *sql.DB) *TileRepository {
+	return &TileRepository{db: db}
+}
+
+// GetCatalogVersion returns the counter bumped by triggers on every change
+// of POIs, their interests and the interest taxonomy.
+func (r *TileRepository) GetCatalogVersion(ctx context.Context) (int64, error) {
+	var version int64
+	err := r.db.QueryRowContext(ctx, "SELECT version FROM catalog_version").Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get catalog version: %w", err)
+	}
+	return version, nil
+}
+
+// GetPOITile renders the POIs of web mercator tile z/x/y as a Mapbox Vector
+// Tile with a single "poi" layer. Features carry the POI id as feature id
+// and the id, name and comma separated interests as attributes. POIs within
+// the tile buffer are included so markers on tile edges are not cut.
+func (r *TileRepository) GetPOITile(ctx context.Context, z, x, y int, interests []string) ([]byte, error) {
+	query := `
+        WITH bounds AS (
+            SELECT
+                ST_TileEnvelope($1, $2, $3) as tile,
+                ST_Transform(ST_TileEnvelope($1, $2, $3, margin => 64.0 / 4096), 4326) as area
+        ),
+        features AS (
+            SELECT
+                p.id as fid,
+                p.id,
+                p.name,
+                COALESCE((
+                    SELECT string_agg(pt.type_of_interest_id, ',' ORDER BY pt.type_of_interest_id)
+                    FROM points_of_interest_type pt
+                    WHERE pt.point_of_interest_id = p.id
+                ), '') as interests,
+                ST_AsMVTGeom(ST_Transform(p.location, 3857), bounds.tile, 4096, 64, true) as geom
+            FROM points_of_interest p, bounds
+            WHERE p.location && bounds.area
+              AND (
+                  COALESCE(cardinality($4::text[]), 0) = 0
+                  OR EXISTS (
+                      SELECT 1
+                      FROM points_of_interest_type pt2
+                      WHERE pt2.point_of_interest_id = p.id
+                        AND pt2.type_of_interest_id = ANY($4::text[])
+                  )
+              )
+        )
+        SELECT ST_AsMVT(features, 'poi', 4096, 'geom', 'fid')
+        FROM features
+    `
+
+	var tile []byte
+	err := r.db.QueryRowContext(ctx, query, z, x, y, pq.Array(interests)).Scan(&tile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render tile %d/%d/%d: %w", z, x, y, err)
+	}
+
+	return tile, nil
+}
@@ -0,0 +1,265 @@
+package repository
+
+import (
+	"aigpsservice/internal/domain"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+var (
+	ErrTourNotFound     = errors.New("tour not found")
+	ErrTourPOINotFound  = errors.New("tour stop refers to an unknown point of interest")
+	ErrTourDuplicatePOI = errors.New("point of interest occurs in the tour more than once")
+)
+
+type TourRepository struct {
+	db *sql.DB
+}
+
+func NewTourRepository(db *sql.DB) *TourRepository {
+	return &TourRepository{db: db}
+}
+
+// tourSelect reads the tours of a preceding page CTE together with their
+// stops, the length of the line through the stops and their files.
+const tourSelect = `
+        SELECT
+            t.id,
+            t.name,
+            COALESCE(t.description, ''),
+            t.created_at,
+            t.updated_at,
+            COALESCE(stops.poi_ids, '{}'),
+            COALESCE(stops.distance_meters, 0),
+            COALESCE(
+                (
+                    SELECT json_object_agg(f.kind, json_build_object(
+                        'id', f.id,
+                        's3_key', f.s3_key,
+                        'file_name', f.file_name,
+                        'file_size', f.file_size,
+                        'mime_type', f.mime_type,
+                        'created_at', f.created_at
+                    ))
+                    FROM tour_files f
+                    WHERE f.tour_id = t.id
+                ),
+                '{}'::json
+            ) as files
+        FROM page t
+        LEFT JOIN LATERAL (
+            SELECT
+                array_agg(tp.poi_id ORDER BY tp.position) as poi_ids,
+                ST_Length(ST_MakeLine(p.location ORDER BY tp.position)::geography) as distance_meters
+            FROM tour_points tp
+            JOIN points_of_interest p ON p.id = tp.poi_id
+            WHERE tp.tour_id = t.id
+        ) stops ON true
+`
+
+func (r *TourRepository) GetTour(ctx context.Context, id int64) (*domain.Tour, error) {
+	query := `
+        WITH page AS (
+            SELECT * FROM tours WHERE id = $1
+        )` + tourSelect
+
+	rows, err := r.db.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("database query error: %w", err)
+	}
+	defer rows.Close()
+
+	tours, err := scanTours(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(tours) == 0 {
+		return nil, ErrTourNotFound
+	}
+
+	return tours[0], nil
+}
+
+// ListTours returns up to limit tours, newest first, starting right after
+// the after position when it is set.
+func (r *TourRepository) ListTours(ctx context.Context, after *domain.POICursor, limit int) ([]*domain.Tour, error) {
+	var afterCreatedAt *time.Time
+	var afterID int64
+	if after != nil {
+		afterCreatedAt = &after.CreatedAt
+		afterID = after.ID
+	}
+
+	query := `
+        WITH page AS (
+            SELECT * FROM tours
+            WHERE ($1::timestamptz IS NULL OR (created_at, id) < ($1, $2::bigint))
+            ORDER BY created_at DESC, id DESC
+            LIMIT $3
+        )` + tourSelect + `
+        ORDER BY t.created_at DESC, t.id DESC
+    `
+
+	rows, err := r.db.QueryContext(ctx, query, afterCreatedAt, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("database query error: %w", err)
+	}
+	defer rows.Close()
+
+	return scanTours(rows)
+}
+
+func scanTours(rows *sql.Rows) ([]*domain.Tour, error) {
+	tours := make([]*domain.Tour, 0)
+	for rows.Next() {
+		var tour domain.Tour
+		var poiIDs pq.Int64Array
+		var filesJSON []byte
+
+		err := rows.Scan(
+			&tour.ID,
+			&tour.Name,
+			&tour.Description,
+			&tour.CreatedAt,
+			&tour.UpdatedAt,
+			&poiIDs,
+			&tour.DistanceMeters,
+			&filesJSON,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		tour.POIIDs = poiIDs
+
+		var files map[string]*domain.File
+		if err := json.Unmarshal(filesJSON, &files); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal files JSON: %w", err)
+		}
+		tour.CoverImage = files[domain.TourFileCover]
+		tour.IntroAudio = files[domain.TourFileIntro]
+		tour.OutroAudio = files[domain.TourFileOutro]
+
+		tours = append(tours, &tour)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return tours, nil
+}
+
+// CreateTour saves the tour with its stops and files in one transaction.
+func (r *TourRepository) CreateTour(ctx context.Context, tour *domain.Tour) (*domain.Tour, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO tours (name, description)
+		VALUES ($1, $2)
+		RETURNING id, created_at, updated_at`,
+		tour.Name, tour.Description,
+	).Scan(&tour.ID, &tour.CreatedAt, &tour.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert tour: %w", err)
+	}
+
+	files := map[string]*domain.File{
+		domain.TourFileCover: tour.CoverImage,
+		domain.TourFileIntro: tour.IntroAudio,
+		domain.TourFileOutro: tour.OutroAudio,
+	}
+	for kind, file := range files {
+		if file == nil {
+			continue
+		}
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO tour_files (tour_id, kind, s3_key, file_name, file_size, mime_type, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING id`,
+			tour.ID, kind, file.S3Key, file.FileName, file.FileSize, file.MimeType, file.CreatedAt,
+		).Scan(&file.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to insert %s file: %w", kind, err)
+		}
+	}
+
+	if err = r.insertTourPoints(ctx, tx, tour.ID, tour.POIIDs); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return tour, nil
+}
+
+// SetTourPOIs replaces the stops of a tour with poiIDs in the given order.
+func (r *TourRepository) SetTourPOIs(ctx context.Context, id int64, poiIDs []int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Locking the tour serializes concurrent reorders.
+	result, err := tx.ExecContext(ctx,
+		"UPDATE tours SET updated_at = CURRENT_TIMESTAMP WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to update tour: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrTourNotFound
+	}
+
+	if _, err = tx.ExecContext(ctx, "DELETE FROM tour_points WHERE tour_id = $1", id); err != nil {
+		return fmt.Errorf("failed to delete tour points: %w", err)
+	}
+
+	if err = r.insertTourPoints(ctx, tx, id, poiIDs); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *TourRepository) insertTourPoints(ctx context.Context, tx *sql.Tx, tourID int64, poiIDs []int64) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO tour_points (tour_id, poi_id, position)
+		SELECT $1, poi.id, poi.position
+		FROM unnest($2::bigint[]) WITH ORDINALITY as poi(id, position)`,
+		tourID, pq.Array(poiIDs),
+	)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) {
+			switch pqErr.Constraint {
+			case "fk_tour_points_poi":
+				return ErrTourPOINotFound
+			case "uq_tour_points_poi":
+				return ErrTourDuplicatePOI
+			}
+		}
+		return fmt.Errorf("failed to insert tour points: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"aigpsservice/internal/domain"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+var (
+	ErrUserNotFound = errors.New("user not found")
+	ErrUserExists   = errors.New("user with this email already exists")
+)
+
+type UserRepository struct {
+	db *sql.DB
+}
+
+func NewUserRepository(db *sql.DB) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+const userSelect = `
+	SELECT
+		u.id, u.email, u.password_hash, u.voice, u.narration_length, u.language,
+		u.created_at, u.updated_at,
+		COALESCE(
+			(
+				SELECT json_agg(json_build_object('interest', ui.interest_id, 'weight', ui.weight)
+					ORDER BY ui.weight DESC, ui.interest_id)
+				FROM user_interests ui
+				WHERE ui.user_id = u.id
+			),
+			'[]'::json
+		) as interests
+	FROM users u
+`
+
+// CreateUser stores a user with the bcrypt hash of their password.
+func (r *UserRepository) CreateUser(ctx context.Context, user *domain.User, passwordHash []byte) (*domain.User, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO users (email, password_hash, voice, narration_length, language)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id`,
+		user.Email, string(passwordHash), user.Profile.Voice, user.Profile.NarrationLength, user.Profile.Language,
+	).Scan(&user.ID)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Constraint == "uq_users_email" {
+			return nil, ErrUserExists
+		}
+		return nil, fmt.Errorf("failed to insert user: %w", err)
+	}
+
+	if err = r.insertInterests(ctx, tx, user.ID, user.Profile.Interests); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return r.GetUser(ctx, user.ID)
+}
+
+func (r *UserRepository) GetUser(ctx context.Context, id int64) (*domain.User, error) {
+	user, _, err := r.scanUser(r.db.QueryRowContext(ctx, userSelect+` WHERE u.id = $1`, id))
+	return user, err
+}
+
+// GetUserByEmail returns the user with the email and their password hash.
+func (r *UserRepository) GetUserByEmail(ctx context.Context, email string) (*domain.User, []byte, error) {
+	return r.scanUser(r.db.QueryRowContext(ctx, userSelect+` WHERE lower(u.email) = lower($1)`, email))
+}
+
+// UpdateProfile replaces the profile of a user.
+func (r *UserRepository) UpdateProfile(ctx context.Context, id int64, profile *domain.UserProfile) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE users
+		SET voice = $2, narration_length = $3, language = $4, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1`,
+		id, profile.Voice, profile.NarrationLength, profile.Language,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrUserNotFound
+	}
+
+	if _, err = tx.ExecContext(ctx, "DELETE FROM user_interests WHERE user_id = $1", id); err != nil {
+		return fmt.Errorf("failed to delete user interests: %w", err)
+	}
+	if err = r.insertInterests(ctx, tx, id, profile.Interests); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *UserRepository) insertInterests(ctx context.Context, tx *sql.Tx, userID int64, interests []domain.InterestWeight) error {
+	if len(interests) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(interests))
+	weights := make([]float64, 0, len(interests))
+	for _, interest := range interests {
+		ids = append(ids, interest.Interest)
+		weights = append(weights, interest.Weight)
+	}
+
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO user_interests (user_id, interest_id, weight)
+		SELECT $1, i.interest_id, i.weight
+		FROM unnest($2::text[], $3::double precision[]) AS i(interest_id, weight)`,
+		userID, pq.Array(ids), pq.Array(weights),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert user interests: %w", err)
+	}
+	return nil
+}
+
+func (r *UserRepository) scanUser(row *sql.Row) (*domain.User, []byte, error) {
+	var user domain.User
+	var passwordHash string
+	var interests []byte
+
+	err := row.Scan(
+		&user.ID,
+		&user.Email,
+		&passwordHash,
+		&user.Profile.Voice,
+		&user.Profile.NarrationLength,
+		&user.Profile.Language,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+		&interests,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if err := json.Unmarshal(interests, &user.Profile.Interests); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal user interests: %w", err)
+	}
+
+	return &user, []byte(passwordHash), nil
+}
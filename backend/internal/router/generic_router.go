@@ -2,14 +2,16 @@ package router
 
 import (
 	"aigpsservice/internal/config"
+	"aigpsservice/internal/domain"
 	"aigpsservice/internal/handler"
 	"aigpsservice/internal/repository"
 	"aigpsservice/internal/service"
 	"aigpsservice/pkg/logger"
+	"bufio"
 	"database/sql"
 	"log"
+	"net"
 	"net/http"
-	"net/http/httptest"
 	"time"
 
 	_ "aigpsservice/docs"
@@ -22,7 +24,11 @@ import (
 // @description API для сервиса геолокации и точек интереса
 // @host 45.150.8.131:8080
 // @BasePath /
-func SetupRouter(cfg *config.Config, db *sql.DB) http.Handler {
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description API ключ или токен пользователя в виде "Bearer <secret>"
+func SetupRouter(cfg *config.Config, db *sql.DB, jobService *service.JobService, authService *service.AuthService) http.Handler {
 	mux := http.NewServeMux()
 
 	poiRepo := repository.NewPOIRepository(db)
@@ -31,8 +37,37 @@ func SetupRouter(cfg *config.Config, db *sql.DB) http.Handler {
 		logger.Error.Fatalln("Error init s3 file Storage")
 	}
 
-	poiService := service.NewPOIService(poiRepo, fileStorage)
-	poiHandler := handler.NewPOIHandler(poiService)
+	interestService := service.NewInterestService(repository.NewInterestRepository(db))
+	interestHandler := handler.NewInterestHandler(interestService)
+	playbackRepo := repository.NewPlaybackRepository(db)
+	poiService := service.NewPOIService(poiRepo, fileStorage, interestService, playbackRepo, domain.RankingWeights{
+		Distance:   cfg.RankDistanceWeight,
+		Interest:   cfg.RankInterestWeight,
+		Popularity: cfg.RankPopularityWeight,
+		Recency:    cfg.RankRecencyWeight,
+		Novelty:    cfg.RankNoveltyWeight,
+	})
+	userService := service.NewUserService(repository.NewUserRepository(db), authService, interestService)
+	userHandler := handler.NewUserHandler(userService)
+	poiHandler := handler.NewPOIHandler(poiService, interestService, userService)
+	translationHandler := handler.NewTranslationHandler(poiService)
+	exportService := service.NewExportService(poiRepo, interestService, cfg.PublicBaseURL)
+	exportHandler := handler.NewExportHandler(exportService, interestService)
+	mlClient := service.NewMLClient(cfg.MLBaseURL, 5*time.Minute)
+	narrationService := service.NewNarrationService(poiRepo, fileStorage, mlClient, jobService)
+	narrationHandler := handler.NewNarrationHandler(narrationService)
+	jobHandler := handler.NewJobHandler(jobService)
+	tileService := service.NewTileService(repository.NewTileRepository(db), interestService)
+	tileHandler := handler.NewTileHandler(tileService, interestService)
+	tourService := service.NewTourService(repository.NewTourRepository(db), poiService)
+	tourHandler := handler.NewTourHandler(tourService, exportService, interestService)
+	sessionService := service.NewSessionService(repository.NewSessionRepository(db), poiService, interestService, userService)
+	sessionHandler := handler.NewSessionHandler(sessionService)
+	streamService := service.NewStreamService(poiService, cfg.StreamMaxConnsPerClient)
+	streamHandler := handler.NewStreamHandler(streamService, interestService)
+	authHandler := handler.NewAuthHandler(authService)
+	playbackService := service.NewPlaybackService(playbackRepo)
+	playbackHandler := handler.NewPlaybackHandler(playbackService)
 	s3Proxy, err := handler.NewS3Proxy(cfg)
 	if err != nil {
 		logger.Error.Fatalf("Failed to create S3 proxy: %v", err)
@@ -48,36 +83,125 @@ func SetupRouter(cfg *config.Config, db *sql.DB) http.Handler {
 	mux.HandleFunc("/api/poi/nearby", poiHandler.FindNearestPOI)
 	mux.HandleFunc("/api/poi/create", poiHandler.CreatePOI)
 	mux.HandleFunc("/api/poi/delete", poiHandler.DeletePOI)
+	mux.HandleFunc("/api/poi/import", poiHandler.ImportPOIs)
+	mux.HandleFunc("/api/poi/export", exportHandler.ExportPOIs)
+	mux.HandleFunc("/api/poi/bbox", poiHandler.FindPOIsInBoundingBox)
+	mux.HandleFunc("/api/poi/polygon", poiHandler.FindPOIsInPolygon)
+	mux.HandleFunc("/api/poi/route", poiHandler.FindPOIsAlongRoute)
+	mux.HandleFunc("/api/poi/clusters", poiHandler.ClusterPOIs)
+	mux.HandleFunc("/api/poi", poiHandler.ListPOIs)
+	mux.HandleFunc("/api/poi/{id}", poiHandler.HandlePOI)
+	mux.HandleFunc("/api/poi/{id}/narrate", narrationHandler.NarratePOI)
+	mux.HandleFunc("/api/poi/{id}/translations", translationHandler.ListTranslations)
+	mux.HandleFunc("/api/poi/{id}/translations/{lang}", translationHandler.HandleTranslation)
+
+	// Interest endpoints
+	mux.HandleFunc("/api/interests", interestHandler.HandleInterests)
+	mux.HandleFunc("/api/interests/{id}", interestHandler.HandleInterest)
+
+	// Tour endpoints
+	mux.HandleFunc("/api/tours", tourHandler.HandleTours)
+	mux.HandleFunc("/api/tours/plan", tourHandler.PlanTour)
+	mux.HandleFunc("/api/tours/{id}", tourHandler.GetTour)
+	mux.HandleFunc("/api/tours/{id}/pois", tourHandler.SetTourPOIs)
+
+	// Listening session endpoints
+	mux.HandleFunc("/api/sessions", sessionHandler.StartSession)
+	mux.HandleFunc("/api/sessions/{id}", sessionHandler.GetSession)
+	mux.HandleFunc("/api/sessions/{id}/location", sessionHandler.UpdateLocation)
+	mux.HandleFunc("/api/sessions/{id}/plays", sessionHandler.RecordPlay)
+
+	// Playback events of the app, the statistics are admin only
+	mux.HandleFunc("/api/events", playbackHandler.RecordEvents)
+	mux.HandleFunc("/api/admin/stats/pois", playbackHandler.ListPOIStats)
+	mux.HandleFunc("/api/admin/stats/pois/{id}", playbackHandler.GetPOIStats)
+
+	// Proximity event stream over WebSocket
+	mux.HandleFunc("/api/stream", streamHandler.StreamPOIEvents)
+
+	// Vector tiles, the y segment carries the .mvt extension
+	mux.HandleFunc("/tiles/poi/{z}/{x}/{yext}", tileHandler.GetPOITile)
+
+	// API key management, admin only
+	mux.HandleFunc("/api/auth/keys", authHandler.HandleAPIKeys)
+	mux.HandleFunc("/api/auth/keys/{id}", authHandler.RevokeAPIKey)
+
+	// User accounts and profiles
+	mux.HandleFunc("/api/users/register", userHandler.Register)
+	mux.HandleFunc("/api/users/login", userHandler.Login)
+	mux.HandleFunc("/api/users/me", userHandler.GetMe)
+	mux.HandleFunc("/api/users/me/profile", userHandler.UpdateProfile)
+
+	// Job endpoints
+	mux.HandleFunc("/api/jobs/{id}", jobHandler.GetJob)
 
 	// S3 proxy
 	mux.HandleFunc("/s3/files/", s3Proxy.ProxyGet)
 	mux.HandleFunc("/s3/list", s3Proxy.ListObjects)
 	mux.HandleFunc("/s3/health", s3Proxy.HealthCheck)
 
-	handler := applyMiddleware(mux)
+	handler := applyMiddleware(mux, authHandler.Middleware)
 
 	return handler
 }
 
-func applyMiddleware(handler http.Handler) http.Handler {
+func applyMiddleware(handler http.Handler, authMiddleware func(http.Handler) http.Handler) http.Handler {
+	handler = authMiddleware(handler)
 	handler = loggingMiddleware(handler)
 	return handler
 }
 
+// statusRecorder remembers the response status while passing the body
+// straight through, so streamed responses are not held in memory.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(data []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack hands the connection over to WebSocket streams, which answer
+// with 101 Switching Protocols on their own.
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(r.ResponseWriter).Hijack()
+	if err == nil && r.status == 0 {
+		r.status = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
 func loggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 
-		recorder := httptest.NewRecorder()
+		recorder := &statusRecorder{ResponseWriter: w}
 		next.ServeHTTP(recorder, r)
 
-		for key, values := range recorder.Header() {
-			for _, value := range values {
-				w.Header().Add(key, value)
-			}
+		if recorder.status == 0 {
+			recorder.status = http.StatusOK
 		}
-		w.WriteHeader(recorder.Code)
-		w.Write(recorder.Body.Bytes())
 
 		duration := time.Since(start)
 		log.Printf(
@@ -85,7 +209,7 @@ func loggingMiddleware(next http.Handler) http.Handler {
 			r.Method,
 			r.URL.Path,
 			r.RemoteAddr,
-			recorder.Code,
+			recorder.status,
 			duration,
 		)
 	})
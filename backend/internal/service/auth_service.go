@@ -0,0 +1,209 @@
+package service
+
+import (
+	"aigpsservice/internal/domain"
+	"aigpsservice/internal/repository"
+	"aigpsservice/pkg/logger"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrUnauthenticated = errors.New("invalid credentials")
+	ErrInvalidAPIKey   = errors.New("invalid api key")
+)
+
+// apiKeyPrefix starts every API key secret, so leaked keys are easy to
+// recognize.
+const apiKeyPrefix = "aigps_"
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the authenticated caller.
+func WithPrincipal(ctx context.Context, principal *domain.Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFrom returns the authenticated caller of ctx, nil for anonymous
+// requests.
+func PrincipalFrom(ctx context.Context) *domain.Principal {
+	principal, _ := ctx.Value(principalKey{}).(*domain.Principal)
+	return principal
+}
+
+// jwtHeader is the encoded header of every token, only HS256 is accepted.
+var jwtHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+type tokenClaims struct {
+	Subject   string `json:"sub"`
+	Role      string `json:"role"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// AuthService issues and checks API keys and user tokens. Keys are random
+// and long, so a plain SHA-256 hash is enough to keep the stored ones
+// useless if leaked. User tokens are JWTs signed with HS256.
+type AuthService struct {
+	repo *repository.AuthRepository
+
+	secretBytes int
+	jwtSecret   []byte
+	tokenTTL    time.Duration
+}
+
+// NewAuthService creates the service. Without a JWT secret a random one is
+// used, so user tokens stop working on restart.
+func NewAuthService(repo *repository.AuthRepository, jwtSecret string) *AuthService {
+	secret := []byte(jwtSecret)
+	if len(secret) == 0 {
+		logger.Info.Println("No JWT secret configured, user tokens will not survive a restart")
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			logger.Error.Fatalf("Failed to generate JWT secret: %v", err)
+		}
+	}
+
+	return &AuthService{
+		repo:        repo,
+		secretBytes: 32,
+		jwtSecret:   secret,
+		tokenTTL:    30 * 24 * time.Hour,
+	}
+}
+
+// CreateAPIKey issues a new key. The secret is returned only here.
+func (s *AuthService) CreateAPIKey(ctx context.Context, name, role string) (*domain.NewAPIKey, error) {
+	random := make([]byte, s.secretBytes)
+	if _, err := rand.Read(random); err != nil {
+		return nil, fmt.Errorf("failed to generate api key: %w", err)
+	}
+	secret := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(random)
+
+	key, err := s.saveAPIKey(ctx, name, secret, role)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.NewAPIKey{Key: key, Secret: secret}, nil
+}
+
+// EnsureAPIKey stores a key with a secret chosen by the operator, such as
+// the bootstrap admin key from the configuration. An existing key with the
+// same secret is kept.
+func (s *AuthService) EnsureAPIKey(ctx context.Context, name, secret, role string) (*domain.APIKey, error) {
+	if len(secret) < 16 {
+		return nil, fmt.Errorf("%w: secret must be at least 16 characters", ErrInvalidAPIKey)
+	}
+	return s.saveAPIKey(ctx, name, secret, role)
+}
+
+func (s *AuthService) saveAPIKey(ctx context.Context, name, secret, role string) (*domain.APIKey, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidAPIKey)
+	}
+	if !domain.IsRole(role) {
+		return nil, fmt.Errorf("%w: role must be listener, editor or admin", ErrInvalidAPIKey)
+	}
+
+	prefix := secret[:min(len(secret), len(apiKeyPrefix)+4)]
+	return s.repo.CreateAPIKey(ctx, &domain.APIKey{Name: name, Prefix: prefix, Role: role}, hashSecret(secret))
+}
+
+func (s *AuthService) ListAPIKeys(ctx context.Context) ([]*domain.APIKey, error) {
+	return s.repo.ListAPIKeys(ctx)
+}
+
+func (s *AuthService) RevokeAPIKey(ctx context.Context, id int64) error {
+	return s.repo.RevokeAPIKey(ctx, id)
+}
+
+// Authenticate returns the caller presenting the credential, a user token or
+// an API key.
+func (s *AuthService) Authenticate(ctx context.Context, credential string) (*domain.Principal, error) {
+	if strings.Count(credential, ".") == 2 {
+		return s.parseToken(credential)
+	}
+
+	key, err := s.repo.GetActiveAPIKey(ctx, hashSecret(credential))
+	if errors.Is(err, repository.ErrAPIKeyNotFound) {
+		return nil, ErrUnauthenticated
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.Principal{Role: key.Role, APIKeyID: key.ID}, nil
+}
+
+// IssueToken signs a listener token for the user.
+func (s *AuthService) IssueToken(user *domain.User) (*domain.AuthToken, error) {
+	now := time.Now()
+	expiresAt := now.Add(s.tokenTTL)
+
+	claims, err := json.Marshal(tokenClaims{
+		Subject:   strconv.FormatInt(user.ID, 10),
+		Role:      domain.RoleListener,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: expiresAt.Unix(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode token claims: %w", err)
+	}
+
+	payload := jwtHeader + "." + base64.RawURLEncoding.EncodeToString(claims)
+	token := payload + "." + base64.RawURLEncoding.EncodeToString(s.sign(payload))
+
+	return &domain.AuthToken{Token: token, ExpiresAt: time.Unix(expiresAt.Unix(), 0).UTC(), User: user}, nil
+}
+
+func (s *AuthService) parseToken(token string) (*domain.Principal, error) {
+	parts := strings.Split(token, ".")
+	if parts[0] != jwtHeader {
+		return nil, ErrUnauthenticated
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(signature, s.sign(parts[0]+"."+parts[1])) {
+		return nil, ErrUnauthenticated
+	}
+
+	encoded, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrUnauthenticated
+	}
+	var claims tokenClaims
+	if err := json.Unmarshal(encoded, &claims); err != nil {
+		return nil, ErrUnauthenticated
+	}
+	if time.Now().Unix() >= claims.ExpiresAt || !domain.IsRole(claims.Role) {
+		return nil, ErrUnauthenticated
+	}
+
+	userID, err := strconv.ParseInt(claims.Subject, 10, 64)
+	if err != nil {
+		return nil, ErrUnauthenticated
+	}
+
+	return &domain.Principal{Role: claims.Role, UserID: userID}, nil
+}
+
+func (s *AuthService) sign(payload string) []byte {
+	mac := hmac.New(sha256.New, s.jwtSecret)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+func hashSecret(secret string) []byte {
+	sum := sha256.Sum256([]byte(secret))
+	return sum[:]
+}
@@ -0,0 +1,353 @@
+package service
+
+import (
+	"aigpsservice/internal/domain"
+	"aigpsservice/internal/repository"
+	"bufio"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	ExportFormatGeoJSON = "geojson"
+	ExportFormatGPX     = "gpx"
+	ExportFormatKML     = "kml"
+)
+
+type ExportService struct {
+	repo          *repository.POIRepository
+	interests     *InterestService
+	publicBaseURL string
+}
+
+func NewExportService(repo *repository.POIRepository, interests *InterestService, publicBaseURL string) *ExportService {
+	return &ExportService{
+		repo:          repo,
+		interests:     interests,
+		publicBaseURL: strings.TrimRight(publicBaseURL, "/"),
+	}
+}
+
+// poiEncoder writes POIs one by one in some export format.
+type poiEncoder interface {
+	Begin() error
+	Encode(poi *domain.PointOfInterest) error
+	End() error
+}
+
+// ContentType returns the MIME type and file extension of an export format.
+func (s *ExportService) ContentType(format string) (string, string, error) {
+	switch format {
+	case ExportFormatGeoJSON:
+		return "application/geo+json", ".geojson", nil
+	case ExportFormatGPX:
+		return "application/gpx+xml", ".gpx", nil
+	case ExportFormatKML:
+		return "application/vnd.google-earth.kml+xml", ".kml", nil
+	default:
+		return "", "", fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+// Export streams every POI matching the filter to w in the given format.
+func (s *ExportService) Export(ctx context.Context, w io.Writer, format string, filter domain.POIExportFilter) error {
+	interests, err := s.interests.ExpandInterests(ctx, filter.Interests)
+	if err != nil {
+		return err
+	}
+	filter.Interests = interests
+
+	buffered := bufio.NewWriter(w)
+
+	var encoder poiEncoder
+	switch format {
+	case ExportFormatGeoJSON:
+		encoder = &geoJSONEncoder{w: buffered, fileURL: s.fileURL}
+	case ExportFormatGPX:
+		encoder = &gpxEncoder{w: buffered, xml: xml.NewEncoder(buffered), fileURL: s.fileURL}
+	case ExportFormatKML:
+		encoder = &kmlEncoder{w: buffered, xml: xml.NewEncoder(buffered), fileURL: s.fileURL}
+	default:
+		return fmt.Errorf("unsupported export format: %s", format)
+	}
+
+	if err := encoder.Begin(); err != nil {
+		return fmt.Errorf("failed to write export header: %w", err)
+	}
+
+	err = s.repo.StreamPOIs(ctx, filter, func(poi *domain.PointOfInterest) error {
+		if err := encoder.Encode(poi); err != nil {
+			return fmt.Errorf("failed to write POI %d: %w", poi.ID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := encoder.End(); err != nil {
+		return fmt.Errorf("failed to write export footer: %w", err)
+	}
+
+	return buffered.Flush()
+}
+
+func (s *ExportService) fileURL(file *domain.File) string {
+	if file == nil {
+		return ""
+	}
+	return s.publicBaseURL + "/s3/files/" + (&url.URL{Path: file.S3Key}).EscapedPath()
+}
+
+type geoJSONEncoder struct {
+	w       *bufio.Writer
+	fileURL func(*domain.File) string
+	count   int
+}
+
+type geoJSONFeature struct {
+	Type     string `json:"type"`
+	ID       int64  `json:"id"`
+	Geometry struct {
+		Type        string     `json:"type"`
+		Coordinates [2]float64 `json:"coordinates"`
+	} `json:"geometry"`
+	Properties geoJSONProperties `json:"properties"`
+}
+
+type geoJSONProperties struct {
+	Name          string    `json:"name"`
+	Description   string    `json:"description,omitempty"`
+	Interests     []string  `json:"interests"`
+	CreatedAt     time.Time `json:"created_at"`
+	ImageURL      string    `json:"image_url,omitempty"`
+	ShortAudioURL string    `json:"short_audio_url,omitempty"`
+	FullAudioURLs []string  `json:"full_audio_urls"`
+}
+
+func (e *geoJSONEncoder) Begin() error {
+	_, err := e.w.WriteString(`{"type":"FeatureCollection","features":[`)
+	return err
+}
+
+func (e *geoJSONEncoder) Encode(poi *domain.PointOfInterest) error {
+	f := geoJSONFeature{Type: "Feature", ID: poi.ID}
+	f.Geometry.Type = "Point"
+	f.Geometry.Coordinates = [2]float64{poi.Longitude, poi.Latitude}
+	f.Properties = geoJSONProperties{
+		Name:          poi.Name,
+		Description:   poi.Description,
+		Interests:     poi.Interests,
+		CreatedAt:     poi.CreatedAt,
+		ImageURL:      e.fileURL(poi.ImageFile),
+		ShortAudioURL: e.fileURL(poi.ShortAudioFile),
+		FullAudioURLs: make([]string, 0, len(poi.FullAudioFiles)),
+	}
+	for _, file := range poi.FullAudioFiles {
+		f.Properties.FullAudioURLs = append(f.Properties.FullAudioURLs, e.fileURL(file))
+	}
+
+	data, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+
+	if e.count > 0 {
+		if err := e.w.WriteByte(','); err != nil {
+			return err
+		}
+	}
+	e.count++
+
+	_, err = e.w.Write(data)
+	return err
+}
+
+func (e *geoJSONEncoder) End() error {
+	_, err := e.w.WriteString("]}\n")
+	return err
+}
+
+type gpxEncoder struct {
+	w       *bufio.Writer
+	xml     *xml.Encoder
+	fileURL func(*domain.File) string
+}
+
+type gpxLink struct {
+	Href string `xml:"href,attr"`
+	Text string `xml:"text,omitempty"`
+	Type string `xml:"type,omitempty"`
+}
+
+type gpxWaypoint struct {
+	XMLName     xml.Name  `xml:"wpt"`
+	Latitude    float64   `xml:"lat,attr"`
+	Longitude   float64   `xml:"lon,attr"`
+	Time        time.Time `xml:"time"`
+	Name        string    `xml:"name"`
+	Description string    `xml:"desc,omitempty"`
+	Links       []gpxLink `xml:"link"`
+	Type        string    `xml:"type,omitempty"`
+}
+
+func (e *gpxEncoder) Begin() error {
+	_, err := e.w.WriteString(xml.Header +
+		`<gpx version="1.1" creator="AIGPS Service" xmlns="http://www.topografix.com/GPX/1/1">` + "\n")
+	return err
+}
+
+func (e *gpxEncoder) Encode(poi *domain.PointOfInterest) error {
+	return e.xml.Encode(newGPXWaypoint(poi, e.fileURL))
+}
+
+func newGPXWaypoint(poi *domain.PointOfInterest, fileURL func(*domain.File) string) gpxWaypoint {
+	wpt := gpxWaypoint{
+		Latitude:    poi.Latitude,
+		Longitude:   poi.Longitude,
+		Time:        poi.CreatedAt.UTC(),
+		Name:        poi.Name,
+		Description: poi.Description,
+		Type:        strings.Join(poi.Interests, ","),
+	}
+	if poi.ImageFile != nil {
+		wpt.Links = append(wpt.Links, gpxLink{Href: fileURL(poi.ImageFile), Text: "image", Type: poi.ImageFile.MimeType})
+	}
+	if poi.ShortAudioFile != nil {
+		wpt.Links = append(wpt.Links, gpxLink{Href: fileURL(poi.ShortAudioFile), Text: "short audio", Type: poi.ShortAudioFile.MimeType})
+	}
+	for _, file := range poi.FullAudioFiles {
+		wpt.Links = append(wpt.Links, gpxLink{
+			Href: fileURL(file),
+			Text: fmt.Sprintf("full audio %d", file.SerialNumber),
+			Type: file.MimeType,
+		})
+	}
+	return wpt
+}
+
+func (e *gpxEncoder) End() error {
+	if err := e.xml.Flush(); err != nil {
+		return err
+	}
+	_, err := e.w.WriteString("\n</gpx>\n")
+	return err
+}
+
+type gpxRoutePoint struct {
+	XMLName   xml.Name `xml:"rtept"`
+	Latitude  float64  `xml:"lat,attr"`
+	Longitude float64  `xml:"lon,attr"`
+	Name      string   `xml:"name,omitempty"`
+}
+
+type gpxRoute struct {
+	XMLName xml.Name        `xml:"rte"`
+	Name    string          `xml:"name"`
+	Points  []gpxRoutePoint `xml:"rtept"`
+}
+
+// WriteTourPlanGPX writes a planned walk as GPX: the stops as waypoints and
+// the walk itself as a route from the start through the stops.
+func (s *ExportService) WriteTourPlanGPX(w io.Writer, plan *domain.TourPlan) error {
+	buffered := bufio.NewWriter(w)
+	encoder := &gpxEncoder{w: buffered, xml: xml.NewEncoder(buffered), fileURL: s.fileURL}
+
+	if err := encoder.Begin(); err != nil {
+		return err
+	}
+
+	route := gpxRoute{Name: "Tour"}
+	start := gpxRoutePoint{Latitude: plan.Latitude, Longitude: plan.Longitude, Name: "Start"}
+	route.Points = append(route.Points, start)
+	for _, stop := range plan.Stops {
+		if err := encoder.Encode(stop.POI); err != nil {
+			return fmt.Errorf("failed to write POI %d: %w", stop.POI.ID, err)
+		}
+		route.Points = append(route.Points, gpxRoutePoint{
+			Latitude:  stop.POI.Latitude,
+			Longitude: stop.POI.Longitude,
+			Name:      stop.POI.Name,
+		})
+	}
+	if plan.RoundTrip {
+		route.Points = append(route.Points, start)
+	}
+
+	if err := encoder.xml.Encode(route); err != nil {
+		return fmt.Errorf("failed to write route: %w", err)
+	}
+
+	if err := encoder.End(); err != nil {
+		return err
+	}
+
+	return buffered.Flush()
+}
+
+type kmlEncoder struct {
+	w       *bufio.Writer
+	xml     *xml.Encoder
+	fileURL func(*domain.File) string
+}
+
+type kmlData struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value"`
+}
+
+type kmlPlacemark struct {
+	XMLName     xml.Name  `xml:"Placemark"`
+	ID          string    `xml:"id,attr"`
+	Name        string    `xml:"name"`
+	Description string    `xml:"description,omitempty"`
+	Data        []kmlData `xml:"ExtendedData>Data"`
+	Coordinates string    `xml:"Point>coordinates"`
+}
+
+func (e *kmlEncoder) Begin() error {
+	_, err := e.w.WriteString(xml.Header +
+		`<kml xmlns="http://www.opengis.net/kml/2.2"><Document><name>AIGPS points of interest</name>` + "\n")
+	return err
+}
+
+func (e *kmlEncoder) Encode(poi *domain.PointOfInterest) error {
+	placemark := kmlPlacemark{
+		ID:          fmt.Sprintf("poi-%d", poi.ID),
+		Name:        poi.Name,
+		Description: poi.Description,
+		Coordinates: fmt.Sprintf("%f,%f", poi.Longitude, poi.Latitude),
+		Data: []kmlData{
+			{Name: "interests", Value: strings.Join(poi.Interests, ",")},
+			{Name: "created_at", Value: poi.CreatedAt.UTC().Format(time.RFC3339)},
+		},
+	}
+	if poi.ImageFile != nil {
+		placemark.Data = append(placemark.Data, kmlData{Name: "image_url", Value: e.fileURL(poi.ImageFile)})
+	}
+	if poi.ShortAudioFile != nil {
+		placemark.Data = append(placemark.Data, kmlData{Name: "short_audio_url", Value: e.fileURL(poi.ShortAudioFile)})
+	}
+	for _, file := range poi.FullAudioFiles {
+		placemark.Data = append(placemark.Data, kmlData{
+			Name:  fmt.Sprintf("full_audio_url_%d", file.SerialNumber),
+			Value: e.fileURL(file),
+		})
+	}
+
+	return e.xml.Encode(placemark)
+}
+
+func (e *kmlEncoder) End() error {
+	if err := e.xml.Flush(); err != nil {
+		return err
+	}
+	_, err := e.w.WriteString("\n</Document></kml>\n")
+	return err
+}
@@ -0,0 +1,298 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+const (
+	// maxGeometryPositions limits the size of geometries accepted from clients.
+	maxGeometryPositions = 10000
+	// maxGeofencePositions limits geofence polygons, whose rings are checked
+	// for self-intersections edge against edge.
+	maxGeofencePositions = 1000
+)
+
+type geoJSONGeometry struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+}
+
+// validatePolygon checks that raw is a GeoJSON Polygon or MultiPolygon
+// geometry with closed rings of valid WGS 84 positions, so it can be handed
+// to ST_GeomFromGeoJSON.
+func validatePolygon(raw json.RawMessage) error {
+	_, err := decodePolygons(raw)
+	return err
+}
+
+// decodePolygons is validatePolygon returning the polygons of the geometry.
+func decodePolygons(raw json.RawMessage) ([][][][]float64, error) {
+	var geometry geoJSONGeometry
+	if err := json.Unmarshal(raw, &geometry); err != nil {
+		return nil, fmt.Errorf("invalid GeoJSON geometry: %w", err)
+	}
+
+	var polygons [][][][]float64
+	switch geometry.Type {
+	case "Polygon":
+		var polygon [][][]float64
+		if err := json.Unmarshal(geometry.Coordinates, &polygon); err != nil {
+			return nil, fmt.Errorf("invalid Polygon coordinates: %w", err)
+		}
+		polygons = [][][][]float64{polygon}
+	case "MultiPolygon":
+		if err := json.Unmarshal(geometry.Coordinates, &polygons); err != nil {
+			return nil, fmt.Errorf("invalid MultiPolygon coordinates: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("geometry must be a Polygon or MultiPolygon, got %q", geometry.Type)
+	}
+
+	if len(polygons) == 0 {
+		return nil, fmt.Errorf("geometry has no polygons")
+	}
+
+	positions := 0
+	for _, polygon := range polygons {
+		if len(polygon) == 0 {
+			return nil, fmt.Errorf("polygon has no rings")
+		}
+		for _, ring := range polygon {
+			if len(ring) < 4 {
+				return nil, fmt.Errorf("polygon ring must have at least 4 positions")
+			}
+			for _, position := range ring {
+				if err := validatePosition(position); err != nil {
+					return nil, err
+				}
+			}
+			first, last := ring[0], ring[len(ring)-1]
+			if first[0] != last[0] || first[1] != last[1] {
+				return nil, fmt.Errorf("polygon ring must be closed")
+			}
+			positions += len(ring)
+		}
+	}
+
+	if positions > maxGeometryPositions {
+		return nil, fmt.Errorf("geometry has %d positions, at most %d allowed", positions, maxGeometryPositions)
+	}
+
+	return polygons, nil
+}
+
+// validateGeofencePolygon checks that raw is a valid polygon, as
+// validatePolygon does, whose rings neither cross nor touch themselves, so
+// PostGIS takes it for a valid geometry.
+func validateGeofencePolygon(raw json.RawMessage) error {
+	polygons, err := decodePolygons(raw)
+	if err != nil {
+		return err
+	}
+
+	positions := 0
+	for _, polygon := range polygons {
+		for _, ring := range polygon {
+			positions += len(ring)
+		}
+	}
+	if positions > maxGeofencePositions {
+		return fmt.Errorf("geofence has %d positions, at most %d allowed", positions, maxGeofencePositions)
+	}
+
+	for _, polygon := range polygons {
+		for _, ring := range polygon {
+			if err := validateSimpleRing(ring); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// polygonReach is the distance in meters from the point to the farthest
+// position of a polygon accepted by validatePolygon.
+func polygonReach(raw json.RawMessage, latitude, longitude float64) (float64, error) {
+	polygons, err := decodePolygons(raw)
+	if err != nil {
+		return 0, err
+	}
+
+	reach := 0.0
+	for _, polygon := range polygons {
+		for _, ring := range polygon {
+			for _, position := range ring {
+				reach = math.Max(reach, greatCircleMeters(latitude, longitude, position[1], position[0]))
+			}
+		}
+	}
+	return reach, nil
+}
+
+// validateSimpleRing checks that a closed ring does not cross or touch
+// itself. Repeated consecutive positions are allowed, as in PostGIS. The
+// ring is treated as planar in degrees, which holds for areas the size of
+// a geofence.
+func validateSimpleRing(ring [][]float64) error {
+	points := make([][]float64, 0, len(ring))
+	for _, position := range ring {
+		if len(points) == 0 || !samePosition(points[len(points)-1], position) {
+			points = append(points, position)
+		}
+	}
+
+	// The ring is closed, so edge i runs from points[i] to points[i+1].
+	edges := len(points) - 1
+	if edges < 3 {
+		return fmt.Errorf("polygon ring must have at least 3 distinct positions")
+	}
+
+	for i := 0; i < edges; i++ {
+		for j := i + 1; j < edges; j++ {
+			a, b, c, d := points[i], points[i+1], points[j], points[j+1]
+
+			// Neighboring edges share an end and only must not fold back
+			// onto each other.
+			var shared, before, after []float64
+			switch {
+			case j == i+1:
+				shared, before, after = b, a, d
+			case i == 0 && j == edges-1:
+				shared, before, after = a, c, b
+			default:
+				if segmentsIntersect(a, b, c, d) {
+					return fmt.Errorf("polygon ring crosses itself between %v and %v", a, b)
+				}
+				continue
+			}
+			if orientation(before, shared, after) == 0 &&
+				(onSegment(shared, before, after) || onSegment(shared, after, before)) {
+				return fmt.Errorf("polygon ring folds back on itself at %v", shared)
+			}
+		}
+	}
+	return nil
+}
+
+func samePosition(a, b []float64) bool {
+	return a[0] == b[0] && a[1] == b[1]
+}
+
+// orientation is positive when a, b, c turn counterclockwise, negative when
+// they turn clockwise and zero when they are collinear.
+func orientation(a, b, c []float64) float64 {
+	return (b[0]-a[0])*(c[1]-a[1]) - (b[1]-a[1])*(c[0]-a[0])
+}
+
+// onSegment reports whether p, collinear with a and b, lies between them.
+func onSegment(a, b, p []float64) bool {
+	return math.Min(a[0], b[0]) <= p[0] && p[0] <= math.Max(a[0], b[0]) &&
+		math.Min(a[1], b[1]) <= p[1] && p[1] <= math.Max(a[1], b[1])
+}
+
+// segmentsIntersect reports whether the segments ab and cd have a point in
+// common, touching included.
+func segmentsIntersect(a, b, c, d []float64) bool {
+	d1, d2 := orientation(c, d, a), orientation(c, d, b)
+	d3, d4 := orientation(a, b, c), orientation(a, b, d)
+	if (d1 > 0 && d2 < 0 || d1 < 0 && d2 > 0) && (d3 > 0 && d4 < 0 || d3 < 0 && d4 > 0) {
+		return true
+	}
+	return d1 == 0 && onSegment(c, d, a) ||
+		d2 == 0 && onSegment(c, d, b) ||
+		d3 == 0 && onSegment(a, b, c) ||
+		d4 == 0 && onSegment(a, b, d)
+}
+
+func validatePosition(position []float64) error {
+	if len(position) < 2 {
+		return fmt.Errorf("position must have longitude and latitude")
+	}
+	if position[0] < -180 || position[0] > 180 || position[1] < -90 || position[1] > 90 {
+		return fmt.Errorf("position %v is out of range", position)
+	}
+	return nil
+}
+
+// ParseLineString reads the positions of a GeoJSON LineString geometry.
+func ParseLineString(raw json.RawMessage) ([][]float64, error) {
+	var geometry geoJSONGeometry
+	if err := json.Unmarshal(raw, &geometry); err != nil {
+		return nil, fmt.Errorf("invalid GeoJSON geometry: %w", err)
+	}
+	if geometry.Type != "LineString" {
+		return nil, fmt.Errorf("geometry must be a LineString, got %q", geometry.Type)
+	}
+
+	var line [][]float64
+	if err := json.Unmarshal(geometry.Coordinates, &line); err != nil {
+		return nil, fmt.Errorf("invalid LineString coordinates: %w", err)
+	}
+	return line, nil
+}
+
+// DecodePolyline decodes a route in the encoded polyline format with five
+// decimal places, as produced by Google Maps, OSRM and Valhalla, into
+// [longitude, latitude] positions.
+func DecodePolyline(encoded string) ([][]float64, error) {
+	line := make([][]float64, 0)
+	var latitude, longitude int64
+	for i := 0; i < len(encoded); {
+		var deltas [2]int64
+		for j := range deltas {
+			var result int64
+			var shift uint
+			for {
+				if i >= len(encoded) {
+					return nil, fmt.Errorf("invalid polyline: unexpected end")
+				}
+				b := int64(encoded[i]) - 63
+				i++
+				if b < 0 || b > 63 || shift > 30 {
+					return nil, fmt.Errorf("invalid polyline: bad character at %d", i-1)
+				}
+				result |= (b & 0x1f) << shift
+				shift += 5
+				if b < 0x20 {
+					break
+				}
+			}
+			if result&1 != 0 {
+				deltas[j] = ^(result >> 1)
+			} else {
+				deltas[j] = result >> 1
+			}
+		}
+		latitude += deltas[0]
+		longitude += deltas[1]
+		line = append(line, []float64{float64(longitude) / 1e5, float64(latitude) / 1e5})
+	}
+	return line, nil
+}
+
+// validateLine checks that a route has at least two distinct valid WGS 84
+// positions.
+func validateLine(line [][]float64) error {
+	if len(line) < 2 {
+		return fmt.Errorf("route must have at least 2 positions")
+	}
+	if len(line) > maxGeometryPositions {
+		return fmt.Errorf("route has %d positions, at most %d allowed", len(line), maxGeometryPositions)
+	}
+
+	distinct := false
+	for _, position := range line {
+		if err := validatePosition(position); err != nil {
+			return err
+		}
+		if position[0] != line[0][0] || position[1] != line[0][1] {
+			distinct = true
+		}
+	}
+	if !distinct {
+		return fmt.Errorf("route must have at least 2 distinct positions")
+	}
+	return nil
+}
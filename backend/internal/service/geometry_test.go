@@ -0,0 +1,156 @@
+package service
+
+import (
+	"aigpsservice/internal/domain"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestValidateGeofencePolygon(t *testing.T) {
+	tests := []struct {
+		name    string
+		polygon string
+		wantErr string
+	}{
+		{
+			name:    "square",
+			polygon: `{"type":"Polygon","coordinates":[[[60.60,56.83],[60.61,56.83],[60.61,56.84],[60.60,56.84],[60.60,56.83]]]}`,
+		},
+		{
+			name:    "square with a hole",
+			polygon: `{"type":"Polygon","coordinates":[[[0,0],[4,0],[4,4],[0,4],[0,0]],[[1,1],[1,2],[2,2],[2,1],[1,1]]]}`,
+		},
+		{
+			name:    "repeated position",
+			polygon: `{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,0],[1,1],[0,1],[0,0]]]}`,
+		},
+		{
+			name:    "concave",
+			polygon: `{"type":"Polygon","coordinates":[[[0,0],[4,0],[4,4],[2,1],[0,4],[0,0]]]}`,
+		},
+		{
+			name:    "multipolygon",
+			polygon: `{"type":"MultiPolygon","coordinates":[[[[0,0],[1,0],[1,1],[0,0]]],[[[2,2],[3,2],[3,3],[2,2]]]]}`,
+		},
+		{
+			name:    "bow tie",
+			polygon: `{"type":"Polygon","coordinates":[[[0,0],[1,1],[1,0],[0,1],[0,0]]]}`,
+			wantErr: "crosses itself",
+		},
+		{
+			name:    "ring touching itself",
+			polygon: `{"type":"Polygon","coordinates":[[[0,0],[4,0],[2,2],[4,4],[0,4],[2,2],[0,0]]]}`,
+			wantErr: "crosses itself",
+		},
+		{
+			name:    "vertex on an edge",
+			polygon: `{"type":"Polygon","coordinates":[[[0,0],[4,0],[4,4],[2,0],[0,4],[0,0]]]}`,
+			wantErr: "crosses itself",
+		},
+		{
+			name:    "spike",
+			polygon: `{"type":"Polygon","coordinates":[[[0,0],[2,0],[1,0],[1,1],[0,0]]]}`,
+			wantErr: "folds back",
+		},
+		{
+			name:    "spike at the start",
+			polygon: `{"type":"Polygon","coordinates":[[[2,0],[1,0],[1,1],[0,0],[2,0]]]}`,
+			wantErr: "folds back",
+		},
+		{
+			name:    "flat",
+			polygon: `{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,0],[0,0]]]}`,
+			wantErr: "at least 3 distinct positions",
+		},
+		{
+			name:    "open ring",
+			polygon: `{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,1]]]}`,
+			wantErr: "must be closed",
+		},
+		{
+			name:    "point",
+			polygon: `{"type":"Point","coordinates":[0,0]}`,
+			wantErr: "must be a Polygon or MultiPolygon",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateGeofencePolygon(json.RawMessage(tt.polygon))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateGeofencePolygon() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validateGeofencePolygon() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateGeofencePolygonSize(t *testing.T) {
+	var ring strings.Builder
+	ring.WriteString(`{"type":"Polygon","coordinates":[[`)
+	for i := range maxGeofencePositions {
+		fmt.Fprintf(&ring, "[%g,0],", float64(i)/100)
+	}
+	ring.WriteString(`[0,1],[0,0]]]}`)
+
+	err := validateGeofencePolygon(json.RawMessage(ring.String()))
+	if err == nil || !strings.Contains(err.Error(), "at most") {
+		t.Errorf("validateGeofencePolygon() error = %v, want the size limit", err)
+	}
+}
+
+func TestValidateGeofenceReach(t *testing.T) {
+	// The near polygon stays within 1.3 km of the POI, the far one reaches
+	// 11 km north.
+	near := `{"type":"Polygon","coordinates":[[[60.60,56.83],[60.61,56.83],[60.61,56.84],[60.60,56.83]]]}`
+	far := `{"type":"Polygon","coordinates":[[[60.60,56.83],[60.61,56.83],[60.61,56.93],[60.60,56.83]]]}`
+	radius := 100.0
+
+	tests := []struct {
+		name     string
+		geofence *domain.Geofence
+		wantErr  bool
+	}{
+		{name: "none", geofence: nil},
+		{name: "radius", geofence: &domain.Geofence{Radius: &radius}},
+		{name: "near polygon", geofence: &domain.Geofence{Polygon: json.RawMessage(near)}},
+		{name: "far polygon", geofence: &domain.Geofence{Polygon: json.RawMessage(far)}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateGeofenceReach(tt.geofence, 56.83, 60.60)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("validateGeofenceReach() error = %v, want error %t", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidGeofence) {
+				t.Errorf("validateGeofenceReach() error = %v, want %v", err, ErrInvalidGeofence)
+			}
+		})
+	}
+}
+
+func TestValidatePOIGeofence(t *testing.T) {
+	poi := &domain.PointOfInterest{
+		Name:        "Dam",
+		Description: "The city pond dam.",
+		Latitude:    56.83,
+		Longitude:   60.60,
+		Geofence: &domain.Geofence{
+			Polygon: json.RawMessage(`{"type":"Polygon","coordinates":[[[60.60,56.83],[60.61,56.84],[60.61,56.83],[60.60,56.84],[60.60,56.83]]]}`),
+		},
+	}
+
+	err := (&POIService{}).validatePOI(poi)
+	if !errors.Is(err, ErrInvalidGeofence) {
+		t.Errorf("validatePOI() of a self-intersecting geofence error = %v, want %v", err, ErrInvalidGeofence)
+	}
+}
@@ -0,0 +1,256 @@
+package service
+
+import (
+	"aigpsservice/internal/domain"
+	"aigpsservice/internal/repository"
+	"aigpsservice/pkg/logger"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+)
+
+var (
+	ErrInvalidInterestType = errors.New("invalid interest type")
+	ErrUnknownInterest     = errors.New("unknown interest")
+)
+
+var (
+	interestIDPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,31}$`)
+	languagePattern   = regexp.MustCompile(`^[a-z]{2,3}(-[A-Za-z0-9]{2,8})?$`)
+)
+
+// InterestService manages the interest taxonomy. Lookups are served from a
+// cache that is reloaded after local changes and at least every ttl, so
+// changes made by other instances are picked up too.
+type InterestService struct {
+	repo *repository.InterestRepository
+	ttl  time.Duration
+
+	mu     sync.RWMutex
+	cached *interestTaxonomy
+}
+
+// interestTaxonomy is an immutable snapshot of the type_of_interest table.
+type interestTaxonomy struct {
+	list     []*domain.InterestType
+	byID     map[string]*domain.InterestType
+	children map[string][]string
+	loadedAt time.Time
+}
+
+func NewInterestService(repo *repository.InterestRepository) *InterestService {
+	return &InterestService{
+		repo: repo,
+		ttl:  time.Minute,
+	}
+}
+
+func newInterestTaxonomy(list []*domain.InterestType) *interestTaxonomy {
+	t := &interestTaxonomy{
+		list:     list,
+		byID:     make(map[string]*domain.InterestType, len(list)),
+		children: make(map[string][]string),
+		loadedAt: time.Now(),
+	}
+	for _, interest := range list {
+		t.byID[interest.ID] = interest
+		if interest.ParentID != nil {
+			t.children[*interest.ParentID] = append(t.children[*interest.ParentID], interest.ID)
+		}
+	}
+	return t
+}
+
+// descendants returns the children of id, their children and so on.
+func (t *interestTaxonomy) descendants(id string) []string {
+	result := make([]string, 0)
+	queue := append([]string(nil), t.children[id]...)
+	seen := map[string]bool{id: true}
+	for len(queue) > 0 {
+		child := queue[0]
+		queue = queue[1:]
+		if seen[child] {
+			continue
+		}
+		seen[child] = true
+		result = append(result, child)
+		queue = append(queue, t.children[child]...)
+	}
+	return result
+}
+
+func (s *InterestService) taxonomy(ctx context.Context) (*interestTaxonomy, error) {
+	s.mu.RLock()
+	cached := s.cached
+	s.mu.RUnlock()
+	if cached != nil && time.Since(cached.loadedAt) < s.ttl {
+		return cached, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cached != nil && time.Since(s.cached.loadedAt) < s.ttl {
+		return s.cached, nil
+	}
+
+	list, err := s.repo.ListInterests(ctx)
+	if err != nil {
+		// A stale taxonomy is better than failing every search.
+		if s.cached != nil {
+			logger.Error.Printf("Failed to reload interest types, using cached ones: %v", err)
+			return s.cached, nil
+		}
+		return nil, err
+	}
+
+	s.cached = newInterestTaxonomy(list)
+	return s.cached, nil
+}
+
+func (s *InterestService) invalidate() {
+	s.mu.Lock()
+	s.cached = nil
+	s.mu.Unlock()
+}
+
+func (s *InterestService) ListInterests(ctx context.Context) ([]*domain.InterestType, error) {
+	t, err := s.taxonomy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return t.list, nil
+}
+
+func (s *InterestService) GetInterest(ctx context.Context, id string) (*domain.InterestType, error) {
+	t, err := s.taxonomy(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	interest, exists := t.byID[id]
+	if !exists {
+		return nil, repository.ErrInterestNotFound
+	}
+	return interest, nil
+}
+
+// ValidateInterests checks that every id is a known interest.
+func (s *InterestService) ValidateInterests(ctx context.Context, ids []string) error {
+	t, err := s.taxonomy(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if _, exists := t.byID[id]; !exists {
+			return fmt.Errorf("%w: %s", ErrUnknownInterest, id)
+		}
+	}
+	return nil
+}
+
+// ExpandInterests adds the descendants of every interest, so searching by a
+// parent matches POIs tagged with its children.
+func (s *InterestService) ExpandInterests(ctx context.Context, ids []string) ([]string, error) {
+	if len(ids) == 0 {
+		return ids, nil
+	}
+
+	t, err := s.taxonomy(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	expanded := make([]string, 0, len(ids))
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		for _, interest := range append([]string{id}, t.descendants(id)...) {
+			if !seen[interest] {
+				seen[interest] = true
+				expanded = append(expanded, interest)
+			}
+		}
+	}
+	return expanded, nil
+}
+
+func (s *InterestService) CreateInterest(ctx context.Context, interest *domain.InterestType) (*domain.InterestType, error) {
+	if !interestIDPattern.MatchString(interest.ID) {
+		return nil, fmt.Errorf("%w: id must be 1-32 lowercase latin letters, digits or underscores", ErrInvalidInterestType)
+	}
+	if len(interest.Names) == 0 {
+		return nil, fmt.Errorf("%w: at least one name is required", ErrInvalidInterestType)
+	}
+	if err := validateInterestNames(interest.Names); err != nil {
+		return nil, err
+	}
+	if interest.ParentID != nil && *interest.ParentID == "" {
+		interest.ParentID = nil
+	}
+
+	created, err := s.repo.CreateInterest(ctx, interest)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidate()
+
+	return created, nil
+}
+
+func (s *InterestService) UpdateInterest(ctx context.Context, id string, update *domain.InterestTypeUpdate) (*domain.InterestType, error) {
+	if update.Names != nil {
+		if len(update.Names) == 0 {
+			return nil, fmt.Errorf("%w: at least one name is required", ErrInvalidInterestType)
+		}
+		if err := validateInterestNames(update.Names); err != nil {
+			return nil, err
+		}
+	}
+
+	if update.ParentID != nil && *update.ParentID != "" {
+		t, err := s.taxonomy(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if *update.ParentID == id {
+			return nil, fmt.Errorf("%w: interest can't be its own parent", ErrInvalidInterestType)
+		}
+		for _, descendant := range t.descendants(id) {
+			if descendant == *update.ParentID {
+				return nil, fmt.Errorf("%w: parent %s is a descendant of %s", ErrInvalidInterestType, descendant, id)
+			}
+		}
+	}
+
+	updated, err := s.repo.UpdateInterest(ctx, id, update)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidate()
+
+	return updated, nil
+}
+
+func (s *InterestService) DeleteInterest(ctx context.Context, id string) error {
+	if err := s.repo.DeleteInterest(ctx, id); err != nil {
+		return err
+	}
+	s.invalidate()
+
+	return nil
+}
+
+func validateInterestNames(names map[string]string) error {
+	for language, name := range names {
+		if !languagePattern.MatchString(language) {
+			return fmt.Errorf("%w: invalid language code %q", ErrInvalidInterestType, language)
+		}
+		if name == "" {
+			return fmt.Errorf("%w: name in %s must not be empty", ErrInvalidInterestType, language)
+		}
+	}
+	return nil
+}
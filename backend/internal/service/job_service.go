@@ -0,0 +1,230 @@
+package service
+
+import (
+	"aigpsservice/internal/domain"
+	"aigpsservice/internal/repository"
+	"aigpsservice/pkg/logger"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// JobHandler runs one job of some kind. The returned result is stored with
+// the job as JSON; an error schedules a retry.
+type JobHandler func(ctx context.Context, payload json.RawMessage) (any, error)
+
+// JobService runs background jobs stored in Postgres with a pool of workers.
+// Any number of service instances may run workers against the same table.
+type JobService struct {
+	repo     *repository.JobRepository
+	handlers map[string]JobHandler
+	kinds    []string
+
+	workers      int
+	pollInterval time.Duration
+	// lease is how long a running job may go without a heartbeat before it
+	// is considered abandoned and handed to another worker.
+	lease       time.Duration
+	maxAttempts int
+	baseBackoff time.Duration
+	maxBackoff  time.Duration
+
+	stop    chan struct{}
+	cancel  context.CancelFunc
+	running sync.WaitGroup
+}
+
+func NewJobService(repo *repository.JobRepository, workers int) *JobService {
+	if workers < 1 {
+		workers = 1
+	}
+	return &JobService{
+		repo:         repo,
+		handlers:     make(map[string]JobHandler),
+		workers:      workers,
+		pollInterval: 2 * time.Second,
+		lease:        30 * time.Minute,
+		maxAttempts:  5,
+		baseBackoff:  10 * time.Second,
+		maxBackoff:   time.Hour,
+	}
+}
+
+// RegisterHandler sets the handler of a job kind. Handlers must be
+// registered before Start.
+func (s *JobService) RegisterHandler(kind string, handler JobHandler) {
+	if _, exists := s.handlers[kind]; !exists {
+		s.kinds = append(s.kinds, kind)
+	}
+	s.handlers[kind] = handler
+}
+
+func (s *JobService) Enqueue(ctx context.Context, kind string, payload any) (*domain.Job, error) {
+	if _, exists := s.handlers[kind]; !exists {
+		return nil, fmt.Errorf("unknown job kind: %s", kind)
+	}
+	return s.repo.EnqueueJob(ctx, kind, payload, s.maxAttempts)
+}
+
+func (s *JobService) GetJob(ctx context.Context, id int64) (*domain.Job, error) {
+	return s.repo.GetJob(ctx, id)
+}
+
+// Start launches the workers. They run until Shutdown is called.
+func (s *JobService) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.stop = make(chan struct{})
+	s.cancel = cancel
+
+	for i := 0; i < s.workers; i++ {
+		s.running.Add(1)
+		go s.work(ctx)
+	}
+
+	logger.Info.Printf("Started %d job workers", s.workers)
+}
+
+// Shutdown stops claiming new jobs and waits for the running ones to finish.
+// When ctx expires first the running jobs are cancelled; they are retried
+// after their lease expires.
+func (s *JobService) Shutdown(ctx context.Context) error {
+	if s.stop == nil {
+		return nil
+	}
+	close(s.stop)
+
+	done := make(chan struct{})
+	go func() {
+		s.running.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		s.cancel()
+		return nil
+	case <-ctx.Done():
+		s.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+func (s *JobService) work(ctx context.Context) {
+	defer s.running.Done()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		default:
+		}
+
+		job, err := s.repo.ClaimJob(ctx, s.kinds, s.lease)
+		if err != nil {
+			logger.Error.Printf("Failed to claim job: %v", err)
+		}
+
+		if job != nil {
+			s.run(ctx, job)
+			continue
+		}
+
+		select {
+		case <-s.stop:
+			return
+		case <-time.After(s.pollInterval):
+		}
+	}
+}
+
+func (s *JobService) run(ctx context.Context, job *domain.Job) {
+	logger.Info.Printf("Running job %d (%s), attempt %d of %d", job.ID, job.Kind, job.Attempts, job.MaxAttempts)
+
+	// The lease is renewed while the handler runs, so a job that takes longer
+	// than the lease is not handed to another worker.
+	handleCtx, cancelHandle := context.WithCancel(ctx)
+	heartbeatDone := make(chan struct{})
+	go func() {
+		defer close(heartbeatDone)
+		s.heartbeat(handleCtx, cancelHandle, job)
+	}()
+
+	result, err := s.handle(handleCtx, job)
+	cancelHandle()
+	<-heartbeatDone
+
+	// The job outcome is recorded even when the workers are being cancelled.
+	saveCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+
+	if err != nil {
+		logger.Error.Printf("Job %d (%s) failed: %v", job.ID, job.Kind, err)
+		if err := s.repo.FailJob(saveCtx, job.ID, job.Attempts, err, time.Now().Add(s.backoff(job.Attempts))); err != nil {
+			logger.Error.Printf("Failed to save failure of job %d: %v", job.ID, err)
+		}
+		return
+	}
+
+	if err := s.repo.CompleteJob(saveCtx, job.ID, job.Attempts, result); err != nil {
+		logger.Error.Printf("Failed to save result of job %d: %v", job.ID, err)
+		return
+	}
+
+	logger.Info.Printf("Job %d (%s) succeeded", job.ID, job.Kind)
+}
+
+// heartbeat extends the lease of the job until ctx is done. When the job
+// has been lost anyway, to another worker after a long stall, cancel stops
+// the handler so the job does not run twice at once.
+func (s *JobService) heartbeat(ctx context.Context, cancel context.CancelFunc, job *domain.Job) {
+	ticker := time.NewTicker(s.lease / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		err := s.repo.ExtendJobLease(ctx, job.ID, job.Attempts)
+		if errors.Is(err, repository.ErrJobLost) {
+			logger.Error.Printf("Job %d (%s) lost its lease, cancelling attempt %d", job.ID, job.Kind, job.Attempts)
+			cancel()
+			return
+		}
+		if err != nil && ctx.Err() == nil {
+			logger.Error.Printf("Failed to extend lease of job %d: %v", job.ID, err)
+		}
+	}
+}
+
+func (s *JobService) handle(ctx context.Context, job *domain.Job) (result any, err error) {
+	handler, exists := s.handlers[job.Kind]
+	if !exists {
+		return nil, fmt.Errorf("unknown job kind: %s", job.Kind)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+
+	return handler(ctx, job.Payload)
+}
+
+// backoff doubles the delay with every attempt, with up to 20% jitter so
+// jobs that failed together do not retry together.
+func (s *JobService) backoff(attempts int) time.Duration {
+	delay := s.maxBackoff
+	if attempts < 20 {
+		delay = min(s.baseBackoff<<max(attempts-1, 0), s.maxBackoff)
+	}
+	return delay + time.Duration(rand.Int64N(int64(delay)/5+1))
+}
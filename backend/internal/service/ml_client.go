@@ -0,0 +1,129 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// MLClient calls the text and speech generation endpoints of the ml service.
+type MLClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+func NewMLClient(baseURL string, timeout time.Duration) *MLClient {
+	return &MLClient{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+type llmGenerateRequest struct {
+	POIName        string   `json:"poi_name"`
+	POIDescription string   `json:"poi_description"`
+	POIFacts       []string `json:"poi_facts"`
+	Language       string   `json:"language"`
+}
+
+type llmGenerateResponse struct {
+	POIGeneratedText string `json:"poi_generated_text"`
+}
+
+type ttsGenerateRequest struct {
+	Text       string `json:"text"`
+	Speaker    string `json:"speaker,omitempty"`
+	SampleRate int    `json:"sample_rate,omitempty"`
+	Language   string `json:"language"`
+}
+
+// GenerateText asks the LLM to turn a POI description into a narration in
+// the given language.
+func (c *MLClient) GenerateText(ctx context.Context, name, description string, facts []string, language string) (string, error) {
+	if facts == nil {
+		facts = []string{}
+	}
+
+	resp, err := c.post(ctx, "/api/v1/llm/generate", llmGenerateRequest{
+		POIName:        name,
+		POIDescription: description,
+		POIFacts:       facts,
+		Language:       language,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to generate text: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var response *llmGenerateResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return "", fmt.Errorf("failed to decode LLM response: %w", err)
+	}
+	if response == nil || strings.TrimSpace(response.POIGeneratedText) == "" {
+		return "", fmt.Errorf("LLM returned empty text")
+	}
+
+	return response.POIGeneratedText, nil
+}
+
+// SynthesizeSpeech turns text in the given language into WAV audio. An
+// empty speaker or zero sample rate leaves the ml service defaults of the
+// language.
+func (c *MLClient) SynthesizeSpeech(ctx context.Context, text, language, speaker string, sampleRate int) ([]byte, error) {
+	resp, err := c.post(ctx, "/api/v1/tts/generate", ttsGenerateRequest{
+		Text:       text,
+		Speaker:    speaker,
+		SampleRate: sampleRate,
+		Language:   language,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to synthesize speech: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// The ml service answers with JSON null when the TTS model fails to load.
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		return nil, fmt.Errorf("TTS returned no audio")
+	}
+
+	audio, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read synthesized audio: %w", err)
+	}
+	if len(audio) == 0 {
+		return nil, fmt.Errorf("TTS returned empty audio")
+	}
+
+	return audio, nil
+}
+
+func (c *MLClient) post(ctx context.Context, path string, payload any) (*http.Response, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request to ml service failed: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+		return nil, fmt.Errorf("ml service returned %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestMLClient returns a client of an ml service answering every request
+// with handler.
+func newTestMLClient(t *testing.T, handler http.HandlerFunc) *MLClient {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return NewMLClient(server.URL+"/", 5*time.Second)
+}
+
+func TestMLClientGenerateText(t *testing.T) {
+	var request llmGenerateRequest
+	client := newTestMLClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/llm/generate" {
+			t.Errorf("path = %s, want /api/v1/llm/generate", r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"poi_generated_text": "Once upon a time."}`))
+	})
+
+	text, err := client.GenerateText(context.Background(), "Dam", "The city dam.", nil, "en")
+	if err != nil {
+		t.Fatalf("GenerateText() error = %v", err)
+	}
+	if text != "Once upon a time." {
+		t.Errorf("GenerateText() = %q, want %q", text, "Once upon a time.")
+	}
+	if request.POIName != "Dam" || request.POIDescription != "The city dam." || request.Language != "en" {
+		t.Errorf("request = %+v", request)
+	}
+	if request.POIFacts == nil {
+		t.Error("facts are sent as null, want an empty list")
+	}
+}
+
+func TestMLClientGenerateTextErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{name: "server error", status: http.StatusInternalServerError, body: "model crashed", wantErr: "ml service returned 500: model crashed"},
+		{name: "bad request", status: http.StatusUnprocessableEntity, body: `{"detail": "language"}`, wantErr: "ml service returned 422"},
+		{name: "null", status: http.StatusOK, body: "null", wantErr: "LLM returned empty text"},
+		{name: "empty text", status: http.StatusOK, body: `{"poi_generated_text": "  "}`, wantErr: "LLM returned empty text"},
+		{name: "invalid json", status: http.StatusOK, body: `{"poi_generated_text":`, wantErr: "failed to decode LLM response"},
+		{name: "wrong type", status: http.StatusOK, body: `["text"]`, wantErr: "failed to decode LLM response"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestMLClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+
+			text, err := client.GenerateText(context.Background(), "Dam", "The city dam.", nil, "ru")
+			if err == nil {
+				t.Fatalf("GenerateText() = %q, want error", text)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("GenerateText() error = %q, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMLClientSynthesizeSpeech(t *testing.T) {
+	var request ttsGenerateRequest
+	client := newTestMLClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/tts/generate" {
+			t.Errorf("path = %s, want /api/v1/tts/generate", r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "audio/wav")
+		w.Write([]byte("RIFF....WAVE"))
+	})
+
+	audio, err := client.SynthesizeSpeech(context.Background(), "Hello.", "en", "en_0", 24000)
+	if err != nil {
+		t.Fatalf("SynthesizeSpeech() error = %v", err)
+	}
+	if string(audio) != "RIFF....WAVE" {
+		t.Errorf("SynthesizeSpeech() = %q, want the response body", audio)
+	}
+	want := ttsGenerateRequest{Text: "Hello.", Speaker: "en_0", SampleRate: 24000, Language: "en"}
+	if request != want {
+		t.Errorf("request = %+v, want %+v", request, want)
+	}
+}
+
+func TestMLClientSynthesizeSpeechErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		contentType string
+		body        string
+		wantErr     string
+	}{
+		{name: "server error", status: http.StatusInternalServerError, contentType: "text/plain", body: "out of memory", wantErr: "ml service returned 500: out of memory"},
+		{name: "bad gateway", status: http.StatusBadGateway, contentType: "text/html", body: "", wantErr: "ml service returned 502"},
+		{name: "null", status: http.StatusOK, contentType: "application/json", body: "null", wantErr: "TTS returned no audio"},
+		{name: "json error", status: http.StatusOK, contentType: "application/json; charset=utf-8", body: `{"error": "x"}`, wantErr: "TTS returned no audio"},
+		{name: "empty audio", status: http.StatusOK, contentType: "audio/wav", body: "", wantErr: "TTS returned empty audio"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestMLClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+
+			audio, err := client.SynthesizeSpeech(context.Background(), "Hello.", "en", "", 0)
+			if err == nil {
+				t.Fatalf("SynthesizeSpeech() = %d bytes, want error", len(audio))
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("SynthesizeSpeech() error = %q, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMLClientCancelled(t *testing.T) {
+	client := newTestMLClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request sent with a cancelled context")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := client.GenerateText(ctx, "Dam", "The city dam.", nil, "ru"); err == nil {
+		t.Error("GenerateText() with a cancelled context succeeded")
+	}
+}
@@ -0,0 +1,267 @@
+package service
+
+import (
+	"aigpsservice/internal/domain"
+	"aigpsservice/internal/repository"
+	"aigpsservice/pkg/logger"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+var ErrInvalidNarrationOptions = errors.New("invalid narration options")
+
+// narrationRepository is the part of the POI repository NarrationService
+// reads and writes POIs through.
+type narrationRepository interface {
+	GetPOIById(ctx context.Context, idPOI int) (*domain.PointOfInterest, error)
+	GetTranslations(ctx context.Context, poiIDs []int64, languages []string) (map[int64]map[string]*domain.POITranslation, error)
+	UpdatePOI(ctx context.Context, idPOI int64, update *domain.POIUpdate) ([]string, error)
+}
+
+// NarrationService produces the audio of a POI with the ml service: the LLM
+// writes a story from the description, TTS reads it aloud.
+type NarrationService struct {
+	repo        narrationRepository
+	fileStorage FileStorage
+	ml          *MLClient
+	jobs        *JobService
+
+	// maxShortChars limits the teaser read as the short audio.
+	maxShortChars int
+	// maxSegmentChars limits the text synthesized as one full audio segment.
+	maxSegmentChars int
+}
+
+// JobKindNarratePOI is the job kind running NarratePOI in the background.
+const JobKindNarratePOI = "narrate_poi"
+
+// NarratePOIPayload is the payload of a JobKindNarratePOI job.
+type NarratePOIPayload struct {
+	POIID   int                     `json:"poi_id"`
+	Options domain.NarrationOptions `json:"options"`
+}
+
+func NewNarrationService(repo *repository.POIRepository, fileStorage FileStorage, ml *MLClient, jobs *JobService) *NarrationService {
+	s := &NarrationService{
+		repo:            repo,
+		fileStorage:     fileStorage,
+		ml:              ml,
+		jobs:            jobs,
+		maxShortChars:   300,
+		maxSegmentChars: 900,
+	}
+	jobs.RegisterHandler(JobKindNarratePOI, s.runNarratePOIJob)
+	return s
+}
+
+// EnqueueNarratePOI schedules NarratePOI as a background job, since the
+// generation takes minutes.
+func (s *NarrationService) EnqueueNarratePOI(ctx context.Context, idPOI int, options domain.NarrationOptions) (*domain.Job, error) {
+	if options.Language != "" {
+		options.Language = NormalizeLanguage(options.Language)
+		if options.Language == "" {
+			return nil, fmt.Errorf("%w: language must be an ISO 639 code such as en", ErrInvalidNarrationOptions)
+		}
+	}
+	if _, err := s.repo.GetPOIById(ctx, idPOI); err != nil {
+		return nil, err
+	}
+	return s.jobs.Enqueue(ctx, JobKindNarratePOI, NarratePOIPayload{POIID: idPOI, Options: options})
+}
+
+func (s *NarrationService) runNarratePOIJob(ctx context.Context, payload json.RawMessage) (any, error) {
+	var p NarratePOIPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return nil, fmt.Errorf("invalid payload: %w", err)
+	}
+
+	poi, err := s.NarratePOI(ctx, p.POIID, p.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]any{"poi_id": poi.ID}, nil
+}
+
+// NarratePOI generates the narration of a POI in the language of options
+// and stores it as its short audio and full audio segments of that
+// language, replacing the ones it had before. Audio in other languages is
+// kept.
+func (s *NarrationService) NarratePOI(ctx context.Context, idPOI int, options domain.NarrationOptions) (*domain.PointOfInterest, error) {
+	poi, err := s.repo.GetPOIById(ctx, idPOI)
+	if err != nil {
+		return nil, err
+	}
+
+	language := options.Language
+	if language == "" {
+		language = domain.DefaultLanguage
+	}
+
+	// The story is written from the translation when there is one, the LLM
+	// translates the Russian description otherwise.
+	name, description := poi.Name, poi.Description
+	if language != domain.DefaultLanguage {
+		translations, err := s.repo.GetTranslations(ctx, []int64{poi.ID}, []string{language})
+		if err != nil {
+			return nil, err
+		}
+		if translation, ok := translations[poi.ID][language]; ok {
+			name, description = translation.Name, translation.Description
+		}
+	}
+
+	text, err := s.ml.GenerateText(ctx, name, description, options.Facts, language)
+	if err != nil {
+		return nil, err
+	}
+
+	sentences := splitSentences(text)
+	shortText, _ := takeSentences(sentences, s.maxShortChars)
+	segments := splitSegments(sentences, s.maxSegmentChars)
+
+	update := &domain.POIUpdate{AudioLanguage: language}
+	uploadedKeys := make([]string, 0, len(segments)+1)
+	cleanupUploaded := func() {
+		if err := s.fileStorage.DeleteFiles(uploadedKeys); err != nil {
+			logger.Error.Printf("Failed to clean up narration files of POI %d: %v", idPOI, err)
+		}
+	}
+
+	update.ShortAudioFile, err = s.synthesize(ctx, shortText, language, options, "narration_short.wav", 1, true)
+	if err != nil {
+		return nil, err
+	}
+	uploadedKeys = append(uploadedKeys, update.ShortAudioFile.S3Key)
+
+	for i, segment := range segments {
+		fileName := fmt.Sprintf("narration_full_%d.wav", i+1)
+		file, err := s.synthesize(ctx, segment, language, options, fileName, int64(i+1), false)
+		if err != nil {
+			cleanupUploaded()
+			return nil, err
+		}
+		uploadedKeys = append(uploadedKeys, file.S3Key)
+		update.NewFullAudioFiles = append(update.NewFullAudioFiles, file)
+	}
+
+	for _, file := range poi.AudioFiles {
+		if !file.IsShort && file.Language == language {
+			update.RemoveFullAudioIDs = append(update.RemoveFullAudioIDs, file.ID)
+		}
+	}
+
+	removedKeys, err := s.repo.UpdatePOI(ctx, poi.ID, update)
+	if err != nil {
+		cleanupUploaded()
+		return nil, fmt.Errorf("failed to attach narration to POI: %w", err)
+	}
+
+	if err := s.fileStorage.DeleteFiles(removedKeys); err != nil {
+		logger.Error.Printf("Failed to delete replaced files of POI %d from s3: %v", idPOI, err)
+	}
+
+	poi, err = s.repo.GetPOIById(ctx, idPOI)
+	if err != nil {
+		return nil, err
+	}
+	poi.SelectAudio(language)
+	return poi, nil
+}
+
+func (s *NarrationService) synthesize(
+	ctx context.Context,
+	text string,
+	language string,
+	options domain.NarrationOptions,
+	fileName string,
+	serialNumber int64,
+	isShort bool,
+) (*domain.File, error) {
+	audio, err := s.ml.SynthesizeSpeech(ctx, text, language, options.Speaker, options.SampleRate)
+	if err != nil {
+		return nil, err
+	}
+
+	file := &domain.File{
+		FileName:     fileName,
+		FileSize:     int64(len(audio)),
+		MimeType:     "audio/wav",
+		SerialNumber: serialNumber,
+		IsShort:      isShort,
+		CreatedAt:    time.Now(),
+		Language:     language,
+	}
+
+	file.S3Key, err = s.fileStorage.UploadFile(ctx, bytes.NewReader(audio), file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload %s: %w", fileName, err)
+	}
+
+	return file, nil
+}
+
+// splitSentences splits text after sentence-ending punctuation.
+func splitSentences(text string) []string {
+	sentences := make([]string, 0)
+	start := 0
+	runes := []rune(text)
+	for i, r := range runes {
+		if r != '.' && r != '!' && r != '?' && r != '…' {
+			continue
+		}
+		if i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) {
+			continue
+		}
+		if sentence := strings.TrimSpace(string(runes[start : i+1])); sentence != "" {
+			sentences = append(sentences, sentence)
+		}
+		start = i + 1
+	}
+	if sentence := strings.TrimSpace(string(runes[start:])); sentence != "" {
+		sentences = append(sentences, sentence)
+	}
+	return sentences
+}
+
+// takeSentences joins leading sentences while they fit into maxChars and
+// returns the text with the number of sentences taken. The first sentence
+// is always taken, even if it is longer.
+func takeSentences(sentences []string, maxChars int) (string, int) {
+	var builder strings.Builder
+	length := 0
+	taken := 0
+	for _, sentence := range sentences {
+		sentenceLength := utf8.RuneCountInString(sentence)
+		if taken > 0 && length+1+sentenceLength > maxChars {
+			break
+		}
+		if taken > 0 {
+			builder.WriteByte(' ')
+			length++
+		}
+		builder.WriteString(sentence)
+		length += sentenceLength
+		taken++
+	}
+	return builder.String(), taken
+}
+
+// splitSegments groups sentences into segments of at most maxChars, so each
+// one stays within what the TTS model reads in a single call.
+func splitSegments(sentences []string, maxChars int) []string {
+	segments := make([]string, 0)
+	for len(sentences) > 0 {
+		segment, taken := takeSentences(sentences, maxChars)
+		segments = append(segments, segment)
+		sentences = sentences[taken:]
+	}
+	return segments
+}
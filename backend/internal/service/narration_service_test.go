@@ -0,0 +1,285 @@
+package service
+
+import (
+	"aigpsservice/internal/domain"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"testing"
+)
+
+// fakeNarrationRepository keeps one POI in memory and applies audio updates
+// to it the way POIRepository.UpdatePOI does.
+type fakeNarrationRepository struct {
+	poi          *domain.PointOfInterest
+	translations map[string]*domain.POITranslation
+	nextFileID   int64
+	updates      int
+}
+
+func (r *fakeNarrationRepository) GetPOIById(ctx context.Context, idPOI int) (*domain.PointOfInterest, error) {
+	if int64(idPOI) != r.poi.ID {
+		return nil, fmt.Errorf("POI %d not found", idPOI)
+	}
+	poi := *r.poi
+	poi.AudioFiles = make([]*domain.File, 0, len(r.poi.AudioFiles))
+	for _, file := range r.poi.AudioFiles {
+		copied := *file
+		poi.AudioFiles = append(poi.AudioFiles, &copied)
+	}
+	return &poi, nil
+}
+
+func (r *fakeNarrationRepository) GetTranslations(ctx context.Context, poiIDs []int64, languages []string) (map[int64]map[string]*domain.POITranslation, error) {
+	result := make(map[int64]map[string]*domain.POITranslation)
+	for _, language := range languages {
+		if translation, ok := r.translations[language]; ok {
+			if result[r.poi.ID] == nil {
+				result[r.poi.ID] = make(map[string]*domain.POITranslation)
+			}
+			result[r.poi.ID][language] = translation
+		}
+	}
+	return result, nil
+}
+
+func (r *fakeNarrationRepository) UpdatePOI(ctx context.Context, idPOI int64, update *domain.POIUpdate) ([]string, error) {
+	r.updates++
+	language := update.AudioLanguage
+	if language == "" {
+		language = domain.DefaultLanguage
+	}
+
+	removedKeys := make([]string, 0)
+	kept := make([]*domain.File, 0, len(r.poi.AudioFiles))
+	fullCount := 0
+	for _, file := range r.poi.AudioFiles {
+		removed := file.Language == language &&
+			(file.IsShort && update.ShortAudioFile != nil ||
+				!file.IsShort && slices.Contains(update.RemoveFullAudioIDs, file.ID))
+		if removed {
+			removedKeys = append(removedKeys, file.S3Key)
+			continue
+		}
+		if file.Language == language && !file.IsShort {
+			fullCount++
+			file.SerialNumber = int64(fullCount)
+		}
+		kept = append(kept, file)
+	}
+
+	if update.ShortAudioFile != nil {
+		r.nextFileID++
+		update.ShortAudioFile.ID = r.nextFileID
+		update.ShortAudioFile.Language = language
+		kept = append(kept, update.ShortAudioFile)
+	}
+	for i, file := range update.NewFullAudioFiles {
+		r.nextFileID++
+		file.ID = r.nextFileID
+		file.IsShort = false
+		file.SerialNumber = int64(fullCount + i + 1)
+		file.Language = language
+		kept = append(kept, file)
+	}
+
+	r.poi.AudioFiles = kept
+	return removedKeys, nil
+}
+
+// fakeFileStorage keeps uploaded files in memory.
+type fakeFileStorage struct {
+	files   map[string][]byte
+	deleted []string
+}
+
+func newFakeFileStorage() *fakeFileStorage {
+	return &fakeFileStorage{files: make(map[string][]byte)}
+}
+
+func (s *fakeFileStorage) UploadFile(ctx context.Context, file io.Reader, fileData *domain.File) (string, error) {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return "", err
+	}
+	key := fmt.Sprintf("uploads/%d/%s", len(s.files)+len(s.deleted)+1, fileData.FileName)
+	s.files[key] = data
+	fileData.FileSize = int64(len(data))
+	return key, nil
+}
+
+func (s *fakeFileStorage) DeleteFile(s3Key string) error {
+	return s.DeleteFiles([]string{s3Key})
+}
+
+func (s *fakeFileStorage) DeleteFiles(s3Keys []string) error {
+	for _, key := range s3Keys {
+		delete(s.files, key)
+		s.deleted = append(s.deleted, key)
+	}
+	return nil
+}
+
+// narrationTestPOI has short and full audio in Russian and in English.
+func narrationTestPOI() *domain.PointOfInterest {
+	return &domain.PointOfInterest{
+		ID:          7,
+		Name:        "Плотина",
+		Description: "Плотина городского пруда.",
+		AudioFiles: []*domain.File{
+			{ID: 1, S3Key: "ru-short", SerialNumber: 1, IsShort: true, Language: "ru"},
+			{ID: 2, S3Key: "ru-full-1", SerialNumber: 1, Language: "ru"},
+			{ID: 3, S3Key: "en-short", SerialNumber: 1, IsShort: true, Language: "en"},
+			{ID: 4, S3Key: "en-full-1", SerialNumber: 1, Language: "en"},
+			{ID: 5, S3Key: "en-full-2", SerialNumber: 2, Language: "en"},
+		},
+	}
+}
+
+// newTestNarrationService returns a service whose LLM answers with story
+// and whose TTS reads the text back as the audio, failing on the call
+// numbered failTTSCall if it is positive.
+func newTestNarrationService(t *testing.T, repo narrationRepository, storage FileStorage, story string, failTTSCall int) (*NarrationService, *llmGenerateRequest) {
+	t.Helper()
+	var llmRequest llmGenerateRequest
+	ttsCalls := 0
+	ml := newTestMLClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/llm/generate":
+			json.NewDecoder(r.Body).Decode(&llmRequest)
+			json.NewEncoder(w).Encode(llmGenerateResponse{POIGeneratedText: story})
+		case "/api/v1/tts/generate":
+			ttsCalls++
+			if ttsCalls == failTTSCall {
+				http.Error(w, "model crashed", http.StatusInternalServerError)
+				return
+			}
+			var request ttsGenerateRequest
+			json.NewDecoder(r.Body).Decode(&request)
+			w.Header().Set("Content-Type", "audio/wav")
+			w.Write([]byte(request.Language + ":" + request.Text))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	return &NarrationService{
+		repo:            repo,
+		fileStorage:     storage,
+		ml:              ml,
+		maxShortChars:   30,
+		maxSegmentChars: 50,
+	}, &llmRequest
+}
+
+const narrationTestStory = "First sentence here. Second sentence is longer. Third one. Fourth sentence ends it."
+
+func TestNarratePOI(t *testing.T) {
+	repo := &fakeNarrationRepository{
+		poi:          narrationTestPOI(),
+		translations: map[string]*domain.POITranslation{"en": {Language: "en", Name: "Dam", Description: "The city pond dam."}},
+		nextFileID:   100,
+	}
+	storage := newFakeFileStorage()
+	service, llmRequest := newTestNarrationService(t, repo, storage, narrationTestStory, 0)
+
+	poi, err := service.NarratePOI(context.Background(), 7, domain.NarrationOptions{Language: "en"})
+	if err != nil {
+		t.Fatalf("NarratePOI() error = %v", err)
+	}
+
+	if llmRequest.POIName != "Dam" || llmRequest.POIDescription != "The city pond dam." || llmRequest.Language != "en" {
+		t.Errorf("LLM request = %+v, want the English translation", llmRequest)
+	}
+
+	type audio struct {
+		serialNumber int64
+		isShort      bool
+		language     string
+		content      string
+	}
+	got := make([]audio, 0)
+	for _, file := range repo.poi.AudioFiles {
+		content := file.S3Key
+		if data, ok := storage.files[file.S3Key]; ok {
+			content = string(data)
+		}
+		got = append(got, audio{file.SerialNumber, file.IsShort, file.Language, content})
+	}
+	want := []audio{
+		{1, true, "ru", "ru-short"},
+		{1, false, "ru", "ru-full-1"},
+		{1, true, "en", "en:First sentence here."},
+		{1, false, "en", "en:First sentence here. Second sentence is longer."},
+		{2, false, "en", "en:Third one. Fourth sentence ends it."},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("poi_files =\n%v\nwant\n%v", got, want)
+	}
+
+	deleted := slices.Sorted(slices.Values(storage.deleted))
+	if want := []string{"en-full-1", "en-full-2", "en-short"}; !slices.Equal(deleted, want) {
+		t.Errorf("deleted keys = %v, want %v", deleted, want)
+	}
+
+	if poi.ShortAudioFile == nil || poi.ShortAudioFile.Language != "en" {
+		t.Errorf("returned short audio = %+v, want the English one", poi.ShortAudioFile)
+	}
+	if len(poi.FullAudioFiles) != 2 {
+		t.Errorf("returned %d full audio files, want 2", len(poi.FullAudioFiles))
+	}
+}
+
+func TestNarratePOIDefaultLanguage(t *testing.T) {
+	repo := &fakeNarrationRepository{poi: narrationTestPOI(), nextFileID: 100}
+	storage := newFakeFileStorage()
+	service, llmRequest := newTestNarrationService(t, repo, storage, "Short story.", 0)
+
+	if _, err := service.NarratePOI(context.Background(), 7, domain.NarrationOptions{}); err != nil {
+		t.Fatalf("NarratePOI() error = %v", err)
+	}
+
+	if llmRequest.POIName != "Плотина" || llmRequest.Language != domain.DefaultLanguage {
+		t.Errorf("LLM request = %+v, want the POI itself in %s", llmRequest, domain.DefaultLanguage)
+	}
+
+	languages := make(map[string]int)
+	for _, file := range repo.poi.AudioFiles {
+		languages[file.Language]++
+	}
+	if languages["ru"] != 2 || languages["en"] != 3 {
+		t.Errorf("audio files per language = %v, want 2 in ru and the 3 in en untouched", languages)
+	}
+
+	deleted := slices.Sorted(slices.Values(storage.deleted))
+	if want := []string{"ru-full-1", "ru-short"}; !slices.Equal(deleted, want) {
+		t.Errorf("deleted keys = %v, want %v", deleted, want)
+	}
+}
+
+func TestNarratePOICleansUpOnFailure(t *testing.T) {
+	repo := &fakeNarrationRepository{poi: narrationTestPOI(), nextFileID: 100}
+	storage := newFakeFileStorage()
+	// The short audio and the first segment are uploaded, the second fails.
+	service, _ := newTestNarrationService(t, repo, storage, narrationTestStory, 3)
+
+	if _, err := service.NarratePOI(context.Background(), 7, domain.NarrationOptions{Language: "en"}); err == nil {
+		t.Fatal("NarratePOI() succeeded, want the TTS error")
+	}
+
+	if repo.updates != 0 {
+		t.Errorf("POI updated %d times, want 0", repo.updates)
+	}
+	if len(storage.files) != 0 {
+		t.Errorf("files left in storage: %v", storage.files)
+	}
+	if len(storage.deleted) != 2 {
+		t.Errorf("deleted keys = %v, want the 2 uploaded files", storage.deleted)
+	}
+	if len(repo.poi.AudioFiles) != 5 {
+		t.Errorf("POI has %d audio files, want the 5 it had", len(repo.poi.AudioFiles))
+	}
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"aigpsservice/internal/domain"
+	"aigpsservice/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"time"
+)
+
+var (
+	ErrInvalidPlaybackEvent = errors.New("invalid playback event")
+	ErrInvalidStatsQuery    = errors.New("invalid stats query")
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// PlaybackService collects the playback events of the apps and aggregates
+// them for the admin statistics.
+type PlaybackService struct {
+	repo *repository.PlaybackRepository
+
+	maxBatch          int
+	maxClockSkew      time.Duration
+	defaultStatsLimit int
+	maxStatsLimit     int
+	defaultSkipBucket float64
+	maxSkipBucket     float64
+}
+
+func NewPlaybackService(repo *repository.PlaybackRepository) *PlaybackService {
+	return &PlaybackService{
+		repo: repo,
+
+		maxBatch:          500,
+		maxClockSkew:      5 * time.Minute,
+		defaultStatsLimit: 50,
+		maxStatsLimit:     500,
+		defaultSkipBucket: 10,
+		maxSkipBucket:     3600,
+	}
+}
+
+// RecordEvents stores a batch of events, attributed to the signed-in user
+// of ctx if there is one. Events without a time get the time of receipt.
+func (s *PlaybackService) RecordEvents(ctx context.Context, events []*domain.PlaybackEvent) error {
+	if len(events) == 0 {
+		return fmt.Errorf("%w: at least one event is required", ErrInvalidPlaybackEvent)
+	}
+	if len(events) > s.maxBatch {
+		return fmt.Errorf("%w: at most %d events are allowed per batch", ErrInvalidPlaybackEvent, s.maxBatch)
+	}
+
+	now := time.Now()
+	for i, event := range events {
+		if event == nil {
+			return fmt.Errorf("%w: event %d is empty", ErrInvalidPlaybackEvent, i)
+		}
+		switch event.Event {
+		case domain.PlaybackStarted, domain.PlaybackCompleted, domain.PlaybackSkipped:
+		default:
+			return fmt.Errorf("%w: event %d must be started, completed or skipped", ErrInvalidPlaybackEvent, i)
+		}
+		if event.FileID <= 0 {
+			return fmt.Errorf("%w: event %d has no file_id", ErrInvalidPlaybackEvent, i)
+		}
+		if event.PositionSeconds < 0 || math.IsNaN(event.PositionSeconds) || math.IsInf(event.PositionSeconds, 0) {
+			return fmt.Errorf("%w: event %d must have a non-negative position", ErrInvalidPlaybackEvent, i)
+		}
+		if event.SessionID != "" && !uuidPattern.MatchString(event.SessionID) {
+			return fmt.Errorf("%w: event %d has an invalid session_id", ErrInvalidPlaybackEvent, i)
+		}
+		if event.OccurredAt == nil {
+			event.OccurredAt = &now
+		} else if event.OccurredAt.After(now.Add(s.maxClockSkew)) {
+			return fmt.Errorf("%w: event %d happened in the future", ErrInvalidPlaybackEvent, i)
+		}
+	}
+
+	var userID *int64
+	if principal := PrincipalFrom(ctx); principal != nil && principal.UserID != 0 {
+		userID = &principal.UserID
+	}
+
+	return s.repo.InsertEvents(ctx, userID, events)
+}
+
+// ListPOIStats returns the POIs played most in the period, zero limit means
+// the default one.
+func (s *PlaybackService) ListPOIStats(ctx context.Context, period domain.StatsPeriod, limit int) ([]*domain.POIPlaybackSummary, error) {
+	if err := validatePeriod(period); err != nil {
+		return nil, err
+	}
+	if limit == 0 {
+		limit = s.defaultStatsLimit
+	}
+	if limit < 1 || limit > s.maxStatsLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidStatsQuery, s.maxStatsLimit)
+	}
+
+	return s.repo.ListPOIStats(ctx, period, limit)
+}
+
+// GetPOIStats returns the statistics of a POI, zero bucketSeconds means the
+// default width of the skip histogram buckets.
+func (s *PlaybackService) GetPOIStats(ctx context.Context, poiID int64, period domain.StatsPeriod, bucketSeconds float64) (*domain.POIPlaybackStats, error) {
+	if err := validatePeriod(period); err != nil {
+		return nil, err
+	}
+	if bucketSeconds == 0 {
+		bucketSeconds = s.defaultSkipBucket
+	}
+	if bucketSeconds < 1 || bucketSeconds > s.maxSkipBucket {
+		return nil, fmt.Errorf("%w: bucket_seconds must be between 1 and %g", ErrInvalidStatsQuery, s.maxSkipBucket)
+	}
+
+	return s.repo.GetPOIStats(ctx, poiID, period, bucketSeconds)
+}
+
+func validatePeriod(period domain.StatsPeriod) error {
+	if period.From != nil && period.To != nil && !period.From.Before(*period.To) {
+		return fmt.Errorf("%w: from must be before to", ErrInvalidStatsQuery)
+	}
+	return nil
+}
@@ -0,0 +1,290 @@
+package service
+
+import (
+	"aigpsservice/internal/domain"
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var ErrInvalidImport = errors.New("invalid import")
+
+// MediaSource gives access to the media files referenced by name from an
+// imported GeoJSON file.
+type MediaSource interface {
+	Open(name string) (io.ReadCloser, int64, error)
+}
+
+type dirMediaSource struct {
+	fsys fs.FS
+}
+
+// NewDirMediaSource returns a MediaSource reading files from dir.
+func NewDirMediaSource(dir string) MediaSource {
+	return &dirMediaSource{fsys: os.DirFS(dir)}
+}
+
+func (d *dirMediaSource) Open(name string) (io.ReadCloser, int64, error) {
+	name = path.Clean(strings.TrimPrefix(filepath.ToSlash(name), "/"))
+	if !fs.ValidPath(name) {
+		return nil, 0, fmt.Errorf("invalid media file name: %s", name)
+	}
+
+	file, err := d.fsys.Open(name)
+	if err != nil {
+		return nil, 0, fmt.Errorf("media file %s not found", name)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, 0, fmt.Errorf("failed to stat media file %s: %w", name, err)
+	}
+	if info.IsDir() {
+		file.Close()
+		return nil, 0, fmt.Errorf("media file %s is a directory", name)
+	}
+
+	return file, info.Size(), nil
+}
+
+type zipMediaSource struct {
+	files map[string]*zip.File
+}
+
+// NewZipMediaSource returns a MediaSource reading files from a zip archive.
+// Files are looked up by their path inside the archive.
+func NewZipMediaSource(r io.ReaderAt, size int64) (MediaSource, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read zip archive: %w", err)
+	}
+
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		files[path.Clean(file.Name)] = file
+	}
+
+	return &zipMediaSource{files: files}, nil
+}
+
+func (z *zipMediaSource) Open(name string) (io.ReadCloser, int64, error) {
+	file, ok := z.files[path.Clean(strings.TrimPrefix(filepath.ToSlash(name), "/"))]
+	if !ok {
+		return nil, 0, fmt.Errorf("media file %s not found", name)
+	}
+
+	reader, err := file.Open()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open media file %s: %w", name, err)
+	}
+
+	return reader, int64(file.UncompressedSize64), nil
+}
+
+type featureCollection struct {
+	Type     string    `json:"type"`
+	Features []feature `json:"features"`
+}
+
+type feature struct {
+	Type     string `json:"type"`
+	Geometry *struct {
+		Type        string    `json:"type"`
+		Coordinates []float64 `json:"coordinates"`
+	} `json:"geometry"`
+	Properties featureProperties `json:"properties"`
+}
+
+// featureProperties maps GeoJSON feature properties to POI fields. Media
+// properties hold file names inside the MediaSource.
+type featureProperties struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Interests   []string `json:"interests"`
+	Image       string   `json:"image"`
+	ShortAudio  string   `json:"short_audio"`
+	FullAudio   []string `json:"full_audio"`
+}
+
+// ImportPOIs creates a POI for every Point feature of a GeoJSON
+// FeatureCollection. Every POI is validated and saved on its own, so a bad
+// feature is reported and skipped without aborting the rest of the batch.
+// With dryRun nothing is uploaded or saved, only the validation is reported.
+// media may be nil when no feature references files. When ctx is done the
+// import stops before the next feature.
+func (s *POIService) ImportPOIs(ctx context.Context, geoJSON io.Reader, media MediaSource, dryRun bool) (*domain.ImportReport, error) {
+	var collection featureCollection
+	if err := json.NewDecoder(geoJSON).Decode(&collection); err != nil {
+		return nil, fmt.Errorf("%w: failed to decode GeoJSON: %v", ErrInvalidImport, err)
+	}
+	if collection.Type != "FeatureCollection" {
+		return nil, fmt.Errorf("%w: GeoJSON must be a FeatureCollection, got %q", ErrInvalidImport, collection.Type)
+	}
+
+	report := &domain.ImportReport{
+		DryRun:  dryRun,
+		Total:   len(collection.Features),
+		Results: make([]domain.ImportResult, 0, len(collection.Features)),
+	}
+
+	for i, f := range collection.Features {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("import stopped after %d of %d features: %w", i, len(collection.Features), err)
+		}
+
+		result := domain.ImportResult{Index: i, Name: f.Properties.Name}
+
+		poiID, err := s.importFeature(ctx, f, media, dryRun)
+		switch {
+		case err != nil:
+			result.Status = domain.ImportStatusFailed
+			result.Error = err.Error()
+			report.Failed++
+		case dryRun:
+			result.Status = domain.ImportStatusValid
+		default:
+			result.Status = domain.ImportStatusCreated
+			result.POIID = poiID
+			report.Created++
+		}
+
+		report.Results = append(report.Results, result)
+	}
+
+	return report, nil
+}
+
+func (s *POIService) importFeature(ctx context.Context, f feature, media MediaSource, dryRun bool) (int64, error) {
+	if f.Geometry == nil || f.Geometry.Type != "Point" || len(f.Geometry.Coordinates) < 2 {
+		return 0, fmt.Errorf("feature geometry must be a Point")
+	}
+
+	props := f.Properties
+	poi := &domain.PointOfInterest{
+		Name:        props.Name,
+		Description: props.Description,
+		Longitude:   f.Geometry.Coordinates[0],
+		Latitude:    f.Geometry.Coordinates[1],
+		Interests:   props.Interests,
+		CreatedAt:   time.Now(),
+	}
+
+	if err := s.validatePOI(poi); err != nil {
+		return 0, fmt.Errorf("validation failed: %w", err)
+	}
+	if err := s.interests.ValidateInterests(ctx, poi.Interests); err != nil {
+		return 0, err
+	}
+	if props.Image == "" {
+		return 0, fmt.Errorf("image is required")
+	}
+
+	readers := make([]io.ReadCloser, 0, len(props.FullAudio)+2)
+	defer func() {
+		for _, reader := range readers {
+			reader.Close()
+		}
+	}()
+
+	openMedia := func(name string, serialNumber int64, isShort bool) (*domain.File, io.Reader, error) {
+		if media == nil {
+			return nil, nil, fmt.Errorf("media file %s not found: no media provided", name)
+		}
+		reader, size, err := media.Open(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		readers = append(readers, reader)
+
+		fileData := &domain.File{
+			FileName:     path.Base(filepath.ToSlash(name)),
+			FileSize:     size,
+			MimeType:     mimeTypeByName(name),
+			SerialNumber: serialNumber,
+			IsShort:      isShort,
+			CreatedAt:    time.Now(),
+		}
+		return fileData, reader, nil
+	}
+
+	imageFileData, imageFile, err := openMedia(props.Image, 0, false)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.checkImage(imageFileData); err != nil {
+		return 0, fmt.Errorf("image %s: %w", props.Image, err)
+	}
+
+	var shortAudioFile io.Reader
+	if props.ShortAudio != "" {
+		poi.ShortAudioFile, shortAudioFile, err = openMedia(props.ShortAudio, 1, true)
+		if err != nil {
+			return 0, err
+		}
+		if err := s.checkAudio(poi.ShortAudioFile); err != nil {
+			return 0, fmt.Errorf("short audio %s: %w", props.ShortAudio, err)
+		}
+	}
+
+	fullAudioFiles := make([]io.Reader, 0, len(props.FullAudio))
+	for i, name := range props.FullAudio {
+		fileData, reader, err := openMedia(name, int64(i+1), false)
+		if err != nil {
+			return 0, err
+		}
+		if err := s.checkAudio(fileData); err != nil {
+			return 0, fmt.Errorf("full audio %s: %w", name, err)
+		}
+		poi.FullAudioFiles = append(poi.FullAudioFiles, fileData)
+		fullAudioFiles = append(fullAudioFiles, reader)
+	}
+
+	if dryRun {
+		return 0, nil
+	}
+
+	createdPOI, err := s.CreatePOI(ctx, poi, imageFileData, imageFile, shortAudioFile, fullAudioFiles)
+	if err != nil {
+		return 0, err
+	}
+
+	return createdPOI.ID, nil
+}
+
+func mimeTypeByName(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".jpg", ".jpeg":
+		return "image/jpeg"
+	case ".png":
+		return "image/png"
+	case ".webp":
+		return "image/webp"
+	case ".gif":
+		return "image/gif"
+	case ".mp3":
+		return "audio/mpeg"
+	case ".wav":
+		return "audio/wav"
+	case ".ogg":
+		return "audio/ogg"
+	case ".aac":
+		return "audio/aac"
+	case ".m4a":
+		return "audio/x-m4a"
+	default:
+		return "application/octet-stream"
+	}
+}
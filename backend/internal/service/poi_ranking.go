@@ -0,0 +1,154 @@
+package service
+
+import (
+	"aigpsservice/internal/domain"
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// RankNearestPOIs returns POIs around the traveler ordered by score instead
+// of distance alone. The score adds up closeness, the overlap with the
+// weighted interests of the listener, popularity among all listeners, a
+// penalty for stories the signed-in listener heard lately and a bonus for
+// the ones they never heard. Every POI carries the explanation of its score
+// and comes in the first of languages it is available in.
+func (s *POIService) RankNearestPOIs(ctx context.Context, query domain.NearbyQuery, interests []domain.InterestWeight, languages []string) ([]*domain.PointOfInterest, error) {
+	if err := s.validateNearbyQuery(query); err != nil {
+		return nil, err
+	}
+	if query.Radius == 0 {
+		query.Radius = s.defaultTriggerRadius
+	}
+	if query.Limit < 1 || query.Limit > s.maxNearbyLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidPOIQuery, s.maxNearbyLimit)
+	}
+	if query.Offset < 0 {
+		return nil, fmt.Errorf("%w: offset must not be negative", ErrInvalidPOIQuery)
+	}
+
+	var err error
+	query.Interests, err = s.interests.ExpandInterests(ctx, query.Interests)
+	if err != nil {
+		return nil, err
+	}
+
+	// The closest candidates are scored together, so a far POI can only
+	// overtake the ones within the pool.
+	limit, offset := query.Limit, query.Offset
+	query.Limit = max(s.rankingPool, offset+limit)
+	query.Offset = 0
+
+	candidates, err := s.repo.FindNearestPOIs(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.scorePOIs(ctx, candidates, float64(query.Radius), interests); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score.Total > candidates[j].Score.Total
+	})
+
+	if offset >= len(candidates) {
+		return []*domain.PointOfInterest{}, nil
+	}
+
+	pois := candidates[offset:min(offset+limit, len(candidates))]
+	if err := s.localizePOIs(ctx, pois, languages); err != nil {
+		return nil, err
+	}
+	return pois, nil
+}
+
+func (s *POIService) scorePOIs(ctx context.Context, pois []*domain.PointOfInterest, radius float64, interests []domain.InterestWeight) error {
+	if len(pois) == 0 {
+		return nil
+	}
+
+	var userID *int64
+	if principal := PrincipalFrom(ctx); principal != nil && principal.UserID != 0 {
+		userID = &principal.UserID
+	}
+
+	ids := make([]int64, 0, len(pois))
+	for _, poi := range pois {
+		ids = append(ids, poi.ID)
+	}
+
+	now := time.Now()
+	history, err := s.plays.GetPlayHistory(ctx, ids, now.Add(-s.popularityWindow), userID)
+	if err != nil {
+		return err
+	}
+
+	var maxPlays int64
+	for _, entry := range history {
+		maxPlays = max(maxPlays, entry.Plays)
+	}
+
+	// Each interest of the listener matches a POI of the interest or of one
+	// of its subtypes.
+	var totalWeight float64
+	expanded := make([]map[string]bool, len(interests))
+	for i, interest := range interests {
+		totalWeight += interest.Weight
+
+		subtypes, err := s.interests.ExpandInterests(ctx, []string{interest.Interest})
+		if err != nil {
+			return err
+		}
+		expanded[i] = make(map[string]bool, len(subtypes))
+		for _, id := range subtypes {
+			expanded[i][id] = true
+		}
+	}
+
+	weights := s.rankingWeights
+	for _, poi := range pois {
+		score := &domain.POIScore{}
+
+		if poi.DistanceMeters != nil {
+			score.Distance = weights.Distance * radius / (radius + *poi.DistanceMeters)
+		}
+
+		if totalWeight > 0 {
+			var matched float64
+			for i, interest := range interests {
+				for _, id := range poi.Interests {
+					if expanded[i][id] {
+						matched += interest.Weight
+						break
+					}
+				}
+			}
+			score.Interest = weights.Interest * matched / totalWeight
+		}
+
+		if entry, ok := history[poi.ID]; ok {
+			score.Plays = entry.Plays
+			score.LastHeardAt = entry.LastHeardAt
+		}
+		if maxPlays > 0 {
+			score.Popularity = weights.Popularity * math.Log1p(float64(score.Plays)) / math.Log1p(float64(maxPlays))
+		}
+
+		if userID != nil {
+			if score.LastHeardAt != nil {
+				age := now.Sub(*score.LastHeardAt)
+				score.Recency = -weights.Recency * math.Exp2(-max(age, 0).Hours()/s.recencyHalfLife.Hours())
+			} else {
+				score.Novelty = weights.Novelty
+			}
+		}
+
+		score.Total = score.Distance + score.Interest + score.Popularity + score.Recency + score.Novelty
+		poi.Score = score
+	}
+
+	return nil
+}
@@ -3,146 +3,310 @@ package service
 import (
 	"aigpsservice/internal/domain"
 	"aigpsservice/internal/repository"
+	"aigpsservice/pkg/logger"
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"mime/multipart"
+	"io"
+	"math"
 	"time"
 )
 
+var (
+	ErrInvalidGeofence = errors.New("invalid geofence")
+	ErrInvalidPOI      = errors.New("invalid point of interest")
+	ErrInvalidPOIQuery = errors.New("invalid POI query")
+)
+
 type POIService struct {
 	repo         *repository.POIRepository
 	fileStorage  FileStorage
+	interests    *InterestService
+	plays        *repository.PlaybackRepository
 	maxImageSize int64
 	maxAudioSize int64
+
+	maxNearbyLimit   int
+	maxListLimit     int
+	defaultAreaLimit int
+	maxAreaLimit     int
+	maxCorridorWidth float64
+
+	// defaultTriggerRadius is the trigger radius of POIs without their own
+	// geofence when a search does not pass one.
+	defaultTriggerRadius int
+
+	// From individualPOIZoom on the map shows POIs instead of clusters.
+	individualPOIZoom int
+	maxClusters       int
+
+	rankingWeights domain.RankingWeights
+	// rankingPool is the number of closest POIs ranked in a nearby search.
+	rankingPool      int
+	popularityWindow time.Duration
+	recencyHalfLife  time.Duration
 }
 
-func NewPOIService(repo *repository.POIRepository, fileStorage FileStorage) *POIService {
+func NewPOIService(
+	repo *repository.POIRepository,
+	fileStorage FileStorage,
+	interests *InterestService,
+	plays *repository.PlaybackRepository,
+	rankingWeights domain.RankingWeights,
+) *POIService {
 	return &POIService{
 		repo:         repo,
 		fileStorage:  fileStorage,
+		interests:    interests,
+		plays:        plays,
 		maxImageSize: 10 << 20,
 		maxAudioSize: 50 << 20,
+
+		maxNearbyLimit:   50,
+		maxListLimit:     100,
+		defaultAreaLimit: 500,
+		maxAreaLimit:     2000,
+		maxCorridorWidth: 5000,
+
+		defaultTriggerRadius: 500,
+
+		individualPOIZoom: 16,
+		maxClusters:       2000,
+
+		rankingWeights:   rankingWeights,
+		rankingPool:      50,
+		popularityWindow: 30 * 24 * time.Hour,
+		recencyHalfLife:  7 * 24 * time.Hour,
 	}
 }
 
+// CreatePOI uploads the files of a POI and saves it. It runs as long as ctx
+// allows, so imports of large media are not cut short.
 func (s *POIService) CreatePOI(
+	ctx context.Context,
 	poi *domain.PointOfInterest,
 	imageFileData *domain.File,
-	imageFile multipart.File,
-	shortAudioFile multipart.File,
-	fullAudioFiles []multipart.File,
+	imageFile io.Reader,
+	shortAudioFile io.Reader,
+	fullAudioFiles []io.Reader,
 ) (*domain.PointOfInterest, error) {
-
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
 	if err := s.validatePOI(poi); err != nil {
 		return nil, fmt.Errorf("validation failed: %w", err)
 	}
 
-	imageS3Key, err := s.uploadImage(ctx, imageFile, imageFileData)
-	if err != nil {
-		return nil, fmt.Errorf("failed to upload image: %w", err)
+	uploads := s.NewFileUploads(ctx)
+	defer uploads.Discard()
+
+	if err := uploads.UploadImage(imageFile, imageFileData); err != nil {
+		return nil, err
 	}
-	imageFileData.S3Key = imageS3Key
 	poi.ImageFile = imageFileData
-	defer imageFile.Close()
 
-	var shortAudioS3Key string
 	if shortAudioFile != nil && poi.ShortAudioFile != nil {
-		shortAudioS3Key, err = s.uploadAudio(ctx, shortAudioFile, poi.ShortAudioFile)
-		if err != nil {
-			s.cleanupFile(imageS3Key)
-			return nil, fmt.Errorf("failed to upload short audio: %w", err)
+		if err := uploads.UploadAudio(shortAudioFile, poi.ShortAudioFile); err != nil {
+			return nil, err
 		}
-		poi.ShortAudioFile.S3Key = shortAudioS3Key
-		defer shortAudioFile.Close()
 	}
 
-	uploadedFullAudioKeys := make([]string, 0)
 	for i, fullAudioFile := range fullAudioFiles {
 		if i < len(poi.FullAudioFiles) {
-			fullAudioData := poi.FullAudioFiles[i]
-			fullAudioS3Key, err := s.uploadAudio(ctx, fullAudioFile, fullAudioData)
-			if err != nil {
-				s.cleanupFile(imageS3Key)
-				if shortAudioS3Key != "" {
-					s.cleanupFile(shortAudioS3Key)
-				}
-				for _, key := range uploadedFullAudioKeys {
-					s.cleanupFile(key)
-				}
-				return nil, fmt.Errorf("failed to upload full audio %s: %w", fullAudioData.FileName, err)
+			if err := uploads.UploadAudio(fullAudioFile, poi.FullAudioFiles[i]); err != nil {
+				return nil, err
 			}
-			fullAudioData.S3Key = fullAudioS3Key
-			uploadedFullAudioKeys = append(uploadedFullAudioKeys, fullAudioS3Key)
-			defer fullAudioFile.Close()
 		}
 	}
 
+	return s.CreateUploadedPOI(ctx, uploads, poi)
+}
+
+// CreateUploadedPOI saves a POI whose files were already streamed to storage
+// with uploads. On failure the uploaded files are left to uploads.Discard.
+func (s *POIService) CreateUploadedPOI(ctx context.Context, uploads *FileUploads, poi *domain.PointOfInterest) (*domain.PointOfInterest, error) {
+	if err := s.validatePOI(poi); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+	if poi.ImageFile == nil || poi.ImageFile.S3Key == "" {
+		return nil, fmt.Errorf("validation failed: %w: image is required", ErrInvalidPOI)
+	}
+
 	createdPOI, err := s.repo.CreatePOI(ctx, poi)
 	if err != nil {
-		s.cleanupFile(imageS3Key)
-		if shortAudioS3Key != "" {
-			s.cleanupFile(shortAudioS3Key)
-		}
-		for _, key := range uploadedFullAudioKeys {
-			s.cleanupFile(key)
-		}
 		return nil, fmt.Errorf("failed to save POI to database: %w", err)
 	}
+	uploads.keep()
 
 	return createdPOI, nil
 }
 
+// UpdatePOI applies a partial update. New files in the update must already
+// be streamed to storage with uploads; on failure they are left to
+// uploads.Discard.
+func (s *POIService) UpdatePOI(ctx context.Context, idPOI int, update *domain.POIUpdate, uploads *FileUploads) (*domain.PointOfInterest, error) {
+	if err := s.validatePOIUpdate(update); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+	if err := s.validateUpdatedGeofenceReach(ctx, idPOI, update); err != nil {
+		return nil, err
+	}
+
+	removedKeys, err := s.repo.UpdatePOI(ctx, int64(idPOI), update)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update POI in database: %w", err)
+	}
+	uploads.keep()
+
+	// Old files are only deleted once the new ones are committed, so a failed
+	// update never leaves the POI pointing at missing objects.
+	if err := s.fileStorage.DeleteFiles(removedKeys); err != nil {
+		logger.Error.Printf("Failed to delete replaced files of POI %d from s3: %v", idPOI, err)
+	}
+
+	poi, err := s.repo.GetPOIById(ctx, idPOI)
+	if err != nil {
+		return nil, err
+	}
+	// The updated POI shows the audio of the language that was changed.
+	poi.SelectAudio(update.AudioLanguage)
+	return poi, nil
+}
+
+// validateUpdatedGeofenceReach checks the reach of the polygon geofence of
+// the POI after the update, when the update moves the POI or sets a polygon.
+func (s *POIService) validateUpdatedGeofenceReach(ctx context.Context, idPOI int, update *domain.POIUpdate) error {
+	newPolygon := update.Geofence != nil && len(update.Geofence.Polygon) > 0
+	if update.Latitude == nil && !newPolygon {
+		return nil
+	}
+
+	poi, err := s.repo.GetPOIById(ctx, idPOI)
+	if err != nil {
+		return err
+	}
+
+	geofence, latitude, longitude := poi.Geofence, poi.Latitude, poi.Longitude
+	if update.Geofence != nil {
+		geofence = update.Geofence
+	}
+	if update.Latitude != nil {
+		latitude, longitude = *update.Latitude, *update.Longitude
+	}
+
+	if err := validateGeofenceReach(geofence, latitude, longitude); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+	return nil
+}
+
+func (s *POIService) validatePOIUpdate(update *domain.POIUpdate) error {
+	if update.Name != nil && *update.Name == "" {
+		return fmt.Errorf("%w: name must not be empty", ErrInvalidPOI)
+	}
+	if update.Description != nil && *update.Description == "" {
+		return fmt.Errorf("%w: description must not be empty", ErrInvalidPOI)
+	}
+	if (update.Latitude == nil) != (update.Longitude == nil) {
+		return fmt.Errorf("%w: latitude and longitude must be changed together", ErrInvalidPOI)
+	}
+	if update.Latitude != nil && (*update.Latitude < -90 || *update.Latitude > 90) {
+		return fmt.Errorf("%w: invalid latitude: %f", ErrInvalidPOI, *update.Latitude)
+	}
+	if update.Longitude != nil && (*update.Longitude < -180 || *update.Longitude > 180) {
+		return fmt.Errorf("%w: invalid longitude: %f", ErrInvalidPOI, *update.Longitude)
+	}
+	if update.Geofence != nil && (update.Geofence.Radius != nil || len(update.Geofence.Polygon) > 0) {
+		return validateGeofence(update.Geofence)
+	}
+	return nil
+}
+
 func (s *POIService) validatePOI(poi *domain.PointOfInterest) error {
 	if poi.Name == "" {
-		return fmt.Errorf("name is required")
+		return fmt.Errorf("%w: name is required", ErrInvalidPOI)
 	}
 	if poi.Description == "" {
-		return fmt.Errorf("description is required")
+		return fmt.Errorf("%w: description is required", ErrInvalidPOI)
 	}
 	if poi.Latitude < -90 || poi.Latitude > 90 {
-		return fmt.Errorf("invalid latitude: %f", poi.Latitude)
+		return fmt.Errorf("%w: invalid latitude: %f", ErrInvalidPOI, poi.Latitude)
 	}
 	if poi.Longitude < -180 || poi.Longitude > 180 {
-		return fmt.Errorf("invalid longitude: %f", poi.Longitude)
+		return fmt.Errorf("%w: invalid longitude: %f", ErrInvalidPOI, poi.Longitude)
+	}
+	if poi.Geofence != nil {
+		if err := validateGeofence(poi.Geofence); err != nil {
+			return err
+		}
+		return validateGeofenceReach(poi.Geofence, poi.Latitude, poi.Longitude)
 	}
 	return nil
 }
 
-func (s *POIService) uploadImage(_ context.Context, file multipart.File, fileData *domain.File) (string, error) {
-	if fileData.FileSize > s.maxImageSize {
-		return "", fmt.Errorf("image size %d exceeds maximum allowed %d", fileData.FileSize, s.maxImageSize)
+// validateGeofence checks that a geofence is either a radius up to
+// repository.MaxTriggerRadius meters or a valid polygon. How far the polygon
+// reaches from the POI is checked by validateGeofenceReach.
+func validateGeofence(geofence *domain.Geofence) error {
+	hasPolygon := len(geofence.Polygon) > 0
+	switch {
+	case geofence.Radius != nil && hasPolygon:
+		return fmt.Errorf("%w: it must be a radius or a polygon, not both", ErrInvalidGeofence)
+	case geofence.Radius != nil:
+		if *geofence.Radius <= 0 || *geofence.Radius > repository.MaxTriggerRadius {
+			return fmt.Errorf("%w: radius must be between 0 and %.0f meters", ErrInvalidGeofence, repository.MaxTriggerRadius)
+		}
+	case hasPolygon:
+		if err := validateGeofencePolygon(geofence.Polygon); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidGeofence, err)
+		}
+	default:
+		return fmt.Errorf("%w: it needs a radius or a polygon", ErrInvalidGeofence)
 	}
+	return nil
+}
 
-	if !s.isValidImageType(fileData.MimeType) {
-		return "", fmt.Errorf("unsupported image type: %s", fileData.MimeType)
+// validateGeofenceReach checks that a polygon geofence stays within
+// repository.MaxTriggerRadius meters of the POI at latitude, longitude, as
+// a radius has to.
+func validateGeofenceReach(geofence *domain.Geofence, latitude, longitude float64) error {
+	if geofence == nil || len(geofence.Polygon) == 0 {
+		return nil
 	}
-
-	s3Key, err := s.fileStorage.UploadFile(file, fileData)
+	reach, err := polygonReach(geofence.Polygon, latitude, longitude)
 	if err != nil {
-		return "", fmt.Errorf("failed to upload image to storage: %w", err)
+		return fmt.Errorf("%w: %v", ErrInvalidGeofence, err)
+	}
+	if reach > repository.MaxTriggerRadius {
+		return fmt.Errorf("%w: polygon reaches %.0f meters from the POI, at most %.0f allowed",
+			ErrInvalidGeofence, reach, repository.MaxTriggerRadius)
+	}
+	return nil
+}
+
+// checkImage validates the declared file before it is uploaded. The size is
+// only known up front for files that are not streamed.
+func (s *POIService) checkImage(fileData *domain.File) error {
+	if fileData.FileSize > s.maxImageSize {
+		return fmt.Errorf("%w: image size %d exceeds maximum allowed %d", ErrFileTooLarge, fileData.FileSize, s.maxImageSize)
 	}
 
-	return s3Key, nil
+	if !s.isValidImageType(fileData.MimeType) {
+		return fmt.Errorf("%w: image type %q", ErrUnsupportedFileType, fileData.MimeType)
+	}
+
+	return nil
 }
 
-func (s *POIService) uploadAudio(_ context.Context, file multipart.File, fileData *domain.File) (string, error) {
+func (s *POIService) checkAudio(fileData *domain.File) error {
 	if fileData.FileSize > s.maxAudioSize {
-		return "", fmt.Errorf("audio size %d exceeds maximum allowed %d", fileData.FileSize, s.maxAudioSize)
+		return fmt.Errorf("%w: audio size %d exceeds maximum allowed %d", ErrFileTooLarge, fileData.FileSize, s.maxAudioSize)
 	}
 
 	if !s.isValidAudioType(fileData.MimeType) {
-		return "", fmt.Errorf("unsupported audio type: %s", fileData.MimeType)
+		return fmt.Errorf("%w: audio type %q", ErrUnsupportedFileType, fileData.MimeType)
 	}
 
-	s3Key, err := s.fileStorage.UploadFile(file, fileData)
-	if err != nil {
-		return "", fmt.Errorf("failed to upload audio to storage: %w", err)
-	}
-
-	return s3Key, nil
+	return nil
 }
 
 func (s *POIService) isValidImageType(mimeType string) bool {
@@ -175,36 +339,263 @@ func (s *POIService) cleanupFile(s3Key string) error {
 	return s.fileStorage.DeleteFile(s3Key)
 }
 
-func (s *POIService) FindNearestPOI(latitude, longitude float64, radius int, interests []string) (*domain.PointOfInterest, error) {
-	if latitude < -90 || latitude > 90 {
-		return nil, fmt.Errorf("invalid latitude: must be between -90 and 90")
+func (s *POIService) FindNearestPOIs(ctx context.Context, query domain.NearbyQuery) ([]*domain.PointOfInterest, error) {
+	if err := s.validateNearbyQuery(query); err != nil {
+		return nil, err
+	}
+	if query.Radius == 0 {
+		query.Radius = s.defaultTriggerRadius
+	}
+	if query.Limit < 1 || query.Limit > s.maxNearbyLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidPOIQuery, s.maxNearbyLimit)
+	}
+	if query.Offset < 0 {
+		return nil, fmt.Errorf("%w: offset must not be negative", ErrInvalidPOIQuery)
+	}
+
+	var err error
+	query.Interests, err = s.interests.ExpandInterests(ctx, query.Interests)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.FindNearestPOIs(ctx, query)
+}
+
+// FindPOIsInArea returns the POIs inside a map viewport or polygon. A zero
+// limit means the default one.
+func (s *POIService) FindPOIsInArea(ctx context.Context, query domain.AreaQuery) (*domain.AreaResult, error) {
+	if (query.BoundingBox == nil) == (query.Polygon == nil) {
+		return nil, fmt.Errorf("%w: either a bounding box or a polygon is required", ErrInvalidPOIQuery)
+	}
+	if query.Polygon != nil {
+		if err := validatePolygon(query.Polygon); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPOIQuery, err)
+		}
+	}
+	if query.Limit == 0 {
+		query.Limit = s.defaultAreaLimit
+	}
+	if query.Limit < 1 || query.Limit > s.maxAreaLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidPOIQuery, s.maxAreaLimit)
+	}
+
+	interests, err := s.interests.ExpandInterests(ctx, query.Interests)
+	if err != nil {
+		return nil, err
+	}
+	query.Interests = interests
+
+	// One more POI than asked tells whether the result is cut.
+	limit := query.Limit
+	query.Limit++
+	pois, err := s.repo.FindPOIsInArea(ctx, query)
+	if err != nil {
+		return nil, err
 	}
-	if longitude < -180 || longitude > 180 {
-		return nil, fmt.Errorf("invalid longitude: must be between -180 and 180")
+
+	result := &domain.AreaResult{Items: pois}
+	if len(pois) > limit {
+		result.Items = pois[:limit]
+		result.Truncated = true
 	}
 
-	return s.repo.FindNearestPOI(latitude, longitude, radius, interests)
+	return result, nil
 }
 
-func (s *POIService) DeletePOI(idPOI int) (bool, error) {
-	poi, err := s.repo.GetPOIById(idPOI)
+// FindPOIsAlongRoute returns the POIs within query.Width meters of a route
+// in the order they are passed. A zero limit means the default one.
+func (s *POIService) FindPOIsAlongRoute(ctx context.Context, query domain.CorridorQuery) (*domain.AreaResult, error) {
+	if err := validateLine(query.Line); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPOIQuery, err)
+	}
+	if query.Width <= 0 || query.Width > s.maxCorridorWidth {
+		return nil, fmt.Errorf("%w: width must be greater than 0 and at most %g meters", ErrInvalidPOIQuery, s.maxCorridorWidth)
+	}
+	if query.Limit == 0 {
+		query.Limit = s.defaultAreaLimit
+	}
+	if query.Limit < 1 || query.Limit > s.maxAreaLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidPOIQuery, s.maxAreaLimit)
+	}
+
+	interests, err := s.interests.ExpandInterests(ctx, query.Interests)
 	if err != nil {
-		return false, fmt.Errorf("POI not found: %v", err)
+		return nil, err
+	}
+	query.Interests = interests
+
+	limit := query.Limit
+	query.Limit++
+	pois, err := s.repo.FindPOIsAlongRoute(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.AreaResult{Items: pois}
+	if len(pois) > limit {
+		result.Items = pois[:limit]
+		result.Truncated = true
+	}
+
+	return result, nil
+}
+
+// ClusterPOIs returns the POIs of a map viewport grouped into clusters of
+// about a quarter of a 256 px web map tile, or the POIs themselves when the
+// map is zoomed in far enough.
+func (s *POIService) ClusterPOIs(ctx context.Context, bbox domain.BoundingBox, zoom int, interests []string) (*domain.ClusterResult, error) {
+	if zoom < 0 || zoom > 22 {
+		return nil, fmt.Errorf("%w: zoom must be between 0 and 22", ErrInvalidPOIQuery)
+	}
+
+	if zoom >= s.individualPOIZoom {
+		area, err := s.FindPOIsInArea(ctx, domain.AreaQuery{BoundingBox: &bbox, Interests: interests})
+		if err != nil {
+			return nil, err
+		}
+		return &domain.ClusterResult{Zoom: zoom, POIs: area.Items, Truncated: area.Truncated}, nil
+	}
+
+	expanded, err := s.interests.ExpandInterests(ctx, interests)
+	if err != nil {
+		return nil, err
+	}
+
+	query := domain.ClusterQuery{
+		BoundingBox: bbox,
+		Interests:   expanded,
+		CellSize:    360 / math.Exp2(float64(zoom)) / 4,
+		Limit:       s.maxClusters + 1,
+	}
+
+	clusters, err := s.repo.ClusterPOIs(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.ClusterResult{Zoom: zoom, Clusters: clusters}
+	if len(clusters) > s.maxClusters {
+		result.Clusters = clusters[:s.maxClusters]
+		result.Truncated = true
+	}
+
+	return result, nil
+}
+
+func (s *POIService) validateNearbyQuery(query domain.NearbyQuery) error {
+	if query.Radius < 0 {
+		return fmt.Errorf("%w: radius must not be negative", ErrInvalidPOIQuery)
+	}
+	if query.Margin < 0 {
+		return fmt.Errorf("%w: margin must not be negative", ErrInvalidPOIQuery)
 	}
+	if query.Latitude < -90 || query.Latitude > 90 {
+		return fmt.Errorf("%w: latitude must be between -90 and 90", ErrInvalidPOIQuery)
+	}
+	if query.Longitude < -180 || query.Longitude > 180 {
+		return fmt.Errorf("%w: longitude must be between -180 and 180", ErrInvalidPOIQuery)
+	}
+	if query.Heading != nil && (*query.Heading < 0 || *query.Heading >= 360) {
+		return fmt.Errorf("%w: heading must be between 0 and 360", ErrInvalidPOIQuery)
+	}
+	if query.Speed != nil && *query.Speed < 0 {
+		return fmt.Errorf("%w: speed must not be negative", ErrInvalidPOIQuery)
+	}
+	return nil
+}
+
+// GetPOI returns the POI in the first of languages it is available in.
+func (s *POIService) GetPOI(ctx context.Context, idPOI int, languages []string) (*domain.PointOfInterest, error) {
+	poi, err := s.repo.GetPOIById(ctx, idPOI)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.localizePOIs(ctx, []*domain.PointOfInterest{poi}, languages); err != nil {
+		return nil, err
+	}
+	return poi, nil
+}
 
-	// Delete files from s3
-	s3FileKeys := make([]string, 0, len(poi.FullAudioFiles)+2)
-	for _, file := range poi.FullAudioFiles {
-		if file != nil {
-			s3FileKeys = append(s3FileKeys, file.S3Key)
+// GetPOIsByIDs returns the POIs with the given ids in the order of ids.
+func (s *POIService) GetPOIsByIDs(ctx context.Context, ids []int64) ([]*domain.PointOfInterest, error) {
+	return s.repo.GetPOIsByIDs(ctx, ids)
+}
+
+// ListPOIs returns one page of the POI catalog. The cursor is the opaque
+// next_cursor of the previous page, or empty for the first page.
+func (s *POIService) ListPOIs(ctx context.Context, filter domain.POIListFilter, cursor string, limit int) (*domain.POIPage, error) {
+	if limit < 1 || limit > s.maxListLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidPOIQuery, s.maxListLimit)
+	}
+	if filter.CreatedFrom != nil && filter.CreatedTo != nil && filter.CreatedFrom.After(*filter.CreatedTo) {
+		return nil, fmt.Errorf("%w: created_from is after created_to", ErrInvalidPOIQuery)
+	}
+
+	var after *domain.POICursor
+	if cursor != "" {
+		decoded, err := decodePOICursor(cursor)
+		if err != nil {
+			return nil, err
 		}
+		after = decoded
+	}
+
+	interests, err := s.interests.ExpandInterests(ctx, filter.Interests)
+	if err != nil {
+		return nil, err
+	}
+	filter.Interests = interests
+
+	pois, err := s.repo.ListPOIs(ctx, filter, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &domain.POIPage{Items: pois}
+	if len(pois) > limit {
+		page.Items = pois[:limit]
+		last := page.Items[limit-1]
+		page.NextCursor = encodePOICursor(domain.POICursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+
+	return page, nil
+}
+
+func encodePOICursor(cursor domain.POICursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodePOICursor(cursor string) (*domain.POICursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid cursor", ErrInvalidPOIQuery)
+	}
+
+	var decoded domain.POICursor
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, fmt.Errorf("%w: invalid cursor", ErrInvalidPOIQuery)
+	}
+
+	return &decoded, nil
+}
+
+func (s *POIService) DeletePOI(ctx context.Context, idPOI int) (bool, error) {
+	poi, err := s.repo.GetPOIById(ctx, idPOI)
+	if err != nil {
+		return false, fmt.Errorf("POI not found: %v", err)
+	}
+
+	// Delete files from s3, the audio of every language
+	s3FileKeys := make([]string, 0, len(poi.AudioFiles)+1)
+	for _, file := range poi.AudioFiles {
+		s3FileKeys = append(s3FileKeys, file.S3Key)
 	}
 	if poi.ImageFile != nil {
 		s3FileKeys = append(s3FileKeys, poi.ImageFile.S3Key)
 	}
-	if poi.ShortAudioFile != nil {
-		s3FileKeys = append(s3FileKeys, poi.ShortAudioFile.S3Key)
-	}
 
 	err = s.fileStorage.DeleteFiles(s3FileKeys)
 	if err != nil {
@@ -212,18 +603,13 @@ func (s *POIService) DeletePOI(idPOI int) (bool, error) {
 	}
 
 	// Delete poi and files from db
-	idFiles := make([]int64, 0, len(poi.FullAudioFiles)+2)
-	for _, file := range poi.FullAudioFiles {
-		if file != nil {
-			idFiles = append(idFiles, file.ID)
-		}
+	idFiles := make([]int64, 0, len(poi.AudioFiles)+1)
+	for _, file := range poi.AudioFiles {
+		idFiles = append(idFiles, file.ID)
 	}
 	if poi.ImageFile != nil {
 		idFiles = append(idFiles, poi.ImageFile.ID)
 	}
-	if poi.ShortAudioFile != nil {
-		idFiles = append(idFiles, poi.ShortAudioFile.ID)
-	}
 
-	return s.repo.DeletePOI(idPOI, idFiles)
+	return s.repo.DeletePOI(ctx, idPOI, idFiles)
 }
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"aigpsservice/internal/domain"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var ErrInvalidTranslation = errors.New("invalid translation")
+
+// primaryLanguagePattern matches the primary subtag of a BCP 47 language
+// tag, such as en or de.
+var primaryLanguagePattern = regexp.MustCompile(`^[a-z]{2,3}$`)
+
+// NormalizeLanguage returns the lower-case primary subtag of a language tag,
+// so en-GB becomes en, or "" if tag is not a language tag.
+func NormalizeLanguage(tag string) string {
+	primary, _, _ := strings.Cut(strings.TrimSpace(tag), "-")
+	primary = strings.ToLower(primary)
+	if !primaryLanguagePattern.MatchString(primary) {
+		return ""
+	}
+	return primary
+}
+
+// localizePOIs sets the name, description and audio of the POIs to the
+// first of languages each of them is available in. Text and audio fall back
+// to the default language separately, so a POI may have a translated name
+// and Russian audio.
+func (s *POIService) localizePOIs(ctx context.Context, pois []*domain.PointOfInterest, languages []string) error {
+	if len(pois) == 0 || len(languages) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(pois))
+	for _, poi := range pois {
+		ids = append(ids, poi.ID)
+	}
+
+	translations, err := s.repo.GetTranslations(ctx, ids, languages)
+	if err != nil {
+		return err
+	}
+
+	for _, poi := range pois {
+		poi.Language = domain.DefaultLanguage
+		for _, language := range languages {
+			if language == domain.DefaultLanguage {
+				break
+			}
+			if translation, ok := translations[poi.ID][language]; ok {
+				poi.Name = translation.Name
+				poi.Description = translation.Description
+				poi.Language = language
+				break
+			}
+		}
+		poi.SelectAudio(languages...)
+	}
+
+	return nil
+}
+
+// ListTranslations returns the translations of the POI into languages other
+// than the default one.
+func (s *POIService) ListTranslations(ctx context.Context, poiID int64) ([]*domain.POITranslation, error) {
+	return s.repo.ListTranslations(ctx, poiID)
+}
+
+// SaveTranslation creates or replaces the translation of the POI into
+// translation.Language.
+func (s *POIService) SaveTranslation(ctx context.Context, poiID int64, translation *domain.POITranslation) error {
+	if err := validateTranslationLanguage(translation.Language); err != nil {
+		return err
+	}
+	translation.Name = strings.TrimSpace(translation.Name)
+	if translation.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidTranslation)
+	}
+	if len([]rune(translation.Name)) > 255 {
+		return fmt.Errorf("%w: name must be at most 255 characters", ErrInvalidTranslation)
+	}
+	if strings.TrimSpace(translation.Description) == "" {
+		return fmt.Errorf("%w: description is required", ErrInvalidTranslation)
+	}
+
+	return s.repo.UpsertTranslation(ctx, poiID, translation)
+}
+
+// DeleteTranslation removes the translation of the POI into language.
+func (s *POIService) DeleteTranslation(ctx context.Context, poiID int64, language string) error {
+	if err := validateTranslationLanguage(language); err != nil {
+		return err
+	}
+	return s.repo.DeleteTranslation(ctx, poiID, language)
+}
+
+func validateTranslationLanguage(language string) error {
+	if !primaryLanguagePattern.MatchString(language) {
+		return fmt.Errorf("%w: language must be a lower-case ISO 639 code such as en", ErrInvalidTranslation)
+	}
+	if language == domain.DefaultLanguage {
+		return fmt.Errorf("%w: the name and description of the POI itself are in %s", ErrInvalidTranslation, domain.DefaultLanguage)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"aigpsservice/internal/domain"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var (
+	ErrFileTooLarge        = errors.New("file is too large")
+	ErrUnsupportedFileType = errors.New("unsupported file type")
+)
+
+// FileUploads streams the files of one POI request to storage as they
+// arrive, so a request never holds a whole file in memory. Everything
+// uploaded is deleted by Discard unless the POI was saved with it.
+type FileUploads struct {
+	service *POIService
+	ctx     context.Context
+	keys    []string
+}
+
+func (s *POIService) NewFileUploads(ctx context.Context) *FileUploads {
+	return &FileUploads{service: s, ctx: ctx}
+}
+
+// UploadImage checks the image type, streams the image to storage and sets
+// the key and size of fileData. The size limit is enforced while streaming.
+func (u *FileUploads) UploadImage(file io.Reader, fileData *domain.File) error {
+	if err := u.service.checkImage(fileData); err != nil {
+		return err
+	}
+
+	if err := u.upload(file, fileData, u.service.maxImageSize); err != nil {
+		return fmt.Errorf("failed to upload image %s: %w", fileData.FileName, err)
+	}
+
+	return nil
+}
+
+// UploadAudio is UploadImage for audio files.
+func (u *FileUploads) UploadAudio(file io.Reader, fileData *domain.File) error {
+	if err := u.service.checkAudio(fileData); err != nil {
+		return err
+	}
+
+	if err := u.upload(file, fileData, u.service.maxAudioSize); err != nil {
+		return fmt.Errorf("failed to upload audio %s: %w", fileData.FileName, err)
+	}
+
+	return nil
+}
+
+func (u *FileUploads) upload(file io.Reader, fileData *domain.File, maxSize int64) error {
+	limited := &sizeLimitedReader{r: file, remaining: maxSize}
+	s3Key, err := u.service.fileStorage.UploadFile(u.ctx, limited, fileData)
+	// The storage client does not keep the reader error in its chain.
+	if limited.remaining < 0 {
+		return fmt.Errorf("%w: maximum allowed size is %d bytes", ErrFileTooLarge, maxSize)
+	}
+	if err != nil {
+		return err
+	}
+
+	fileData.S3Key = s3Key
+	u.keys = append(u.keys, s3Key)
+
+	return nil
+}
+
+// keep stops tracking the uploaded files once they belong to a saved POI.
+func (u *FileUploads) keep() {
+	u.keys = nil
+}
+
+// Discard deletes the files uploaded since the last save. It is a no-op
+// after the POI is saved, so callers can defer it.
+func (u *FileUploads) Discard() {
+	for _, key := range u.keys {
+		u.service.cleanupFile(key)
+	}
+	u.keys = nil
+}
+
+// sizeLimitedReader fails with ErrFileTooLarge as soon as more than
+// remaining bytes are read, without reading the rest of the file.
+type sizeLimitedReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func (l *sizeLimitedReader) Read(p []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, ErrFileTooLarge
+	}
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n, ErrFileTooLarge
+	}
+
+	return n, err
+}
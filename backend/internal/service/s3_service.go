@@ -3,7 +3,7 @@ package service
 import (
 	"aigpsservice/internal/config"
 	"aigpsservice/internal/domain"
-	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"path/filepath"
@@ -14,17 +14,21 @@ import (
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 	"github.com/google/uuid"
 )
 
 type S3FileStorage struct {
 	s3Client   *s3.S3
+	uploader   *s3manager.Uploader
 	bucketName string
 	presignTTL time.Duration
 }
 
 type FileStorage interface {
-	UploadFile(file io.Reader, fileData *domain.File) (string, error)
+	// UploadFile streams the file to storage and returns its key. The size of
+	// fileData is set to the number of bytes uploaded.
+	UploadFile(ctx context.Context, file io.Reader, fileData *domain.File) (string, error)
 	DeleteFile(s3Key string) error
 	DeleteFiles(s3Keys []string) error
 }
@@ -51,42 +55,59 @@ func NewS3FileStorage(conf config.Config) (*S3FileStorage, error) {
 
 	s3Client := s3.New(sess)
 
+	// Files are sent in parts of PartSize, so an upload holds at most
+	// Concurrency+1 parts in memory whatever the size of the file.
+	uploader := s3manager.NewUploaderWithClient(s3Client, func(u *s3manager.Uploader) {
+		u.PartSize = s3manager.MinUploadPartSize
+		u.Concurrency = 2
+	})
+
 	return &S3FileStorage{
 		s3Client:   s3Client,
+		uploader:   uploader,
 		bucketName: conf.S3Bucket,
 		presignTTL: 24 * time.Hour,
 	}, nil
 }
 
-func (s *S3FileStorage) UploadFile(file io.Reader, fileData *domain.File) (string, error) {
+func (s *S3FileStorage) UploadFile(ctx context.Context, file io.Reader, fileData *domain.File) (string, error) {
 	fileExt := filepath.Ext(fileData.FileName)
 	s3Key := s.generateS3Key(fileData, fileExt)
 
-	fileBytes, err := io.ReadAll(file)
-	if err != nil {
-		return "", fmt.Errorf("failed to read file: %w", err)
-	}
-
-	input := &s3.PutObjectInput{
-		Bucket:        aws.String(s.bucketName),
-		Key:           aws.String(s3Key),
-		Body:          bytes.NewReader(fileBytes),
-		ContentLength: aws.Int64(int64(len(fileBytes))),
-		ContentType:   aws.String(fileData.MimeType),
+	counter := &countingReader{r: file}
+	input := &s3manager.UploadInput{
+		Bucket:      aws.String(s.bucketName),
+		Key:         aws.String(s3Key),
+		Body:        counter,
+		ContentType: aws.String(fileData.MimeType),
 		Metadata: map[string]*string{
 			"original-filename": aws.String(fileData.FileName),
 			"upload-timestamp":  aws.String(time.Now().Format(time.RFC3339)),
 		},
 	}
 
-	_, err = s.s3Client.PutObject(input)
+	_, err := s.uploader.UploadWithContext(ctx, input)
 	if err != nil {
 		return "", fmt.Errorf("failed to upload file to S3: %w", err)
 	}
 
+	fileData.FileSize = counter.n
+
 	return s3Key, nil
 }
 
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
 func (s *S3FileStorage) DeleteFile(s3Key string) error {
 	if s3Key == "" {
 		return nil
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"aigpsservice/internal/domain"
+	"aigpsservice/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+var ErrInvalidSession = errors.New("invalid listening session")
+
+// SessionService hands out POIs to a listener one at a time, skipping what
+// was already heard in the session.
+type SessionService struct {
+	repo      *repository.SessionRepository
+	pois      *POIService
+	interests *InterestService
+	users     *UserService
+
+	defaultRadius   int
+	maxRadius       int
+	defaultCooldown int
+	maxCooldown     int
+}
+
+func NewSessionService(repo *repository.SessionRepository, pois *POIService, interests *InterestService, users *UserService) *SessionService {
+	return &SessionService{
+		repo:      repo,
+		pois:      pois,
+		interests: interests,
+		users:     users,
+
+		defaultRadius:   500,
+		maxRadius:       5000,
+		defaultCooldown: 30,
+		maxCooldown:     3600,
+	}
+}
+
+// StartSession creates a session. A zero radius or cooldown means the
+// default one; a negative cooldown disables it.
+func (s *SessionService) StartSession(ctx context.Context, session *domain.ListeningSession) (*domain.ListeningSession, error) {
+	if session.Radius == 0 {
+		session.Radius = s.defaultRadius
+	}
+	if session.Radius < 1 || session.Radius > s.maxRadius {
+		return nil, fmt.Errorf("%w: radius must be between 1 and %d meters", ErrInvalidSession, s.maxRadius)
+	}
+
+	switch {
+	case session.CooldownSeconds == 0:
+		session.CooldownSeconds = s.defaultCooldown
+	case session.CooldownSeconds < 0:
+		session.CooldownSeconds = 0
+	case session.CooldownSeconds > s.maxCooldown:
+		return nil, fmt.Errorf("%w: cooldown must be at most %d seconds", ErrInvalidSession, s.maxCooldown)
+	}
+
+	if session.Interests == nil {
+		session.Interests = []string{}
+	}
+	if err := s.interests.ValidateInterests(ctx, session.Interests); err != nil {
+		return nil, err
+	}
+
+	return s.repo.CreateSession(ctx, session)
+}
+
+func (s *SessionService) GetSession(ctx context.Context, id string) (*domain.ListeningSession, error) {
+	return s.repo.GetSession(ctx, id)
+}
+
+// UpdateLocation records the position of the listener and returns the
+// nearest POI not heard in the session yet, unless the cooldown after the
+// last story is still running. A listener with the short narration length
+// in the profile only gets the short audio of POIs that have one.
+func (s *SessionService) UpdateLocation(ctx context.Context, id string, location domain.SessionLocation) (*domain.SessionNext, error) {
+	if location.Latitude < -90 || location.Latitude > 90 {
+		return nil, fmt.Errorf("%w: latitude must be between -90 and 90", ErrInvalidSession)
+	}
+	if location.Longitude < -180 || location.Longitude > 180 {
+		return nil, fmt.Errorf("%w: longitude must be between -180 and 180", ErrInvalidSession)
+	}
+
+	if err := s.repo.UpdateLocation(ctx, id, location.Latitude, location.Longitude); err != nil {
+		return nil, err
+	}
+
+	session, err := s.repo.GetSession(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.LastPlayedAt != nil {
+		remaining := time.Until(session.LastPlayedAt.Add(time.Duration(session.CooldownSeconds) * time.Second))
+		if remaining > 0 {
+			return &domain.SessionNext{CooldownSeconds: int(math.Ceil(remaining.Seconds()))}, nil
+		}
+	}
+
+	pois, err := s.pois.FindNearestPOIs(ctx, domain.NearbyQuery{
+		Latitude:   location.Latitude,
+		Longitude:  location.Longitude,
+		Radius:     session.Radius,
+		Interests:  session.Interests,
+		Limit:      1,
+		Heading:    location.Heading,
+		Speed:      location.Speed,
+		ExcludeIDs: session.HeardPOIIDs,
+	})
+	if errors.Is(err, ErrInvalidPOIQuery) {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSession, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(pois) == 0 {
+		return &domain.SessionNext{}, nil
+	}
+
+	// A signed-in listener gets the audio their profile asks for.
+	profile, err := s.users.CurrentProfile(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if profile != nil {
+		pois[0].ApplyNarrationLength(profile.NarrationLength)
+	}
+
+	next := &domain.SessionNext{POI: pois[0]}
+	next.RemainingAudio, err = s.remainingAudio(ctx, id, next.POI)
+	if err != nil {
+		return nil, err
+	}
+
+	return next, nil
+}
+
+// RecordPlay remembers audio of a POI played in the session. Without file
+// ids the whole POI counts as heard.
+func (s *SessionService) RecordPlay(ctx context.Context, id string, play *domain.SessionPlay) error {
+	seen := make(map[int64]bool, len(play.FileIDs))
+	fileIDs := make([]int64, 0, len(play.FileIDs))
+	for _, fileID := range play.FileIDs {
+		if !seen[fileID] {
+			seen[fileID] = true
+			fileIDs = append(fileIDs, fileID)
+		}
+	}
+	play.FileIDs = fileIDs
+
+	return s.repo.RecordPlay(ctx, id, play)
+}
+
+func (s *SessionService) remainingAudio(ctx context.Context, id string, poi *domain.PointOfInterest) ([]*domain.File, error) {
+	playedIDs, err := s.repo.GetPlayedFileIDs(ctx, id, poi.ID)
+	if err != nil {
+		return nil, err
+	}
+	played := make(map[int64]bool, len(playedIDs))
+	for _, fileID := range playedIDs {
+		played[fileID] = true
+	}
+
+	audio := make([]*domain.File, 0, len(poi.FullAudioFiles)+1)
+	if poi.ShortAudioFile != nil && !played[poi.ShortAudioFile.ID] {
+		audio = append(audio, poi.ShortAudioFile)
+	}
+	for _, file := range poi.FullAudioFiles {
+		if !played[file.ID] {
+			audio = append(audio, file)
+		}
+	}
+
+	return audio, nil
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"aigpsservice/internal/domain"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrTooManyStreams = errors.New("too many streams")
+	ErrInvalidFix     = errors.New("invalid location fix")
+)
+
+// nearbyPOIFinder finds the POIs around a location fix of a stream.
+type nearbyPOIFinder interface {
+	FindNearestPOIs(ctx context.Context, query domain.NearbyQuery) ([]*domain.PointOfInterest, error)
+}
+
+// StreamService turns the location fixes of streaming clients into
+// approaching, entered and left events of POIs, checked against their
+// geofences. The state of a stream is kept in memory for resumeTTL after a
+// disconnect, so a client reconnecting with its resume token to the same
+// instance continues where it left off.
+type StreamService struct {
+	pois        nearbyPOIFinder
+	nearbyLimit int
+
+	// A POI is entered inside its geofence, or within triggerRadius meters
+	// if it has none, and approached within approachMeters of that area. It
+	// is only left leaveMeters outside the area, so GPS jitter on the border
+	// does not flap.
+	triggerRadius  int
+	approachMeters float64
+	leaveMeters    float64
+
+	maxStreamsPerClient int
+	resumeTTL           time.Duration
+
+	mu        sync.Mutex
+	streams   map[string]int
+	suspended map[string]*suspendedStream
+}
+
+// StreamState is the progress of one stream. It is owned by the connection
+// that resumed it.
+type StreamState struct {
+	Token     string
+	Interests []string
+	zones     map[int64]*poiZone
+	seq       int64
+}
+
+type poiZone struct {
+	event string
+	poi   *domain.PointOfInterest
+}
+
+type suspendedStream struct {
+	state   *StreamState
+	expires time.Time
+}
+
+func NewStreamService(pois *POIService, maxStreamsPerClient int) *StreamService {
+	return &StreamService{
+		pois:           pois,
+		nearbyLimit:    pois.maxNearbyLimit,
+		triggerRadius:  50,
+		approachMeters: 200,
+		leaveMeters:    10,
+
+		maxStreamsPerClient: maxStreamsPerClient,
+		resumeTTL:           5 * time.Minute,
+
+		streams:   make(map[string]int),
+		suspended: make(map[string]*suspendedStream),
+	}
+}
+
+// Acquire counts a stream of the client against the per-client cap. The
+// returned release must be called once the stream ends.
+func (s *StreamService) Acquire(client string) (func(), error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.streams[client] >= s.maxStreamsPerClient {
+		return nil, fmt.Errorf("%w: at most %d per client", ErrTooManyStreams, s.maxStreamsPerClient)
+	}
+	s.streams[client]++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			if s.streams[client]--; s.streams[client] <= 0 {
+				delete(s.streams, client)
+			}
+		})
+	}, nil
+}
+
+// Resume returns the suspended stream with the token, or a new stream if
+// the token is empty, unknown or expired.
+func (s *StreamService) Resume(token string, interests []string) *StreamState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for key, suspended := range s.suspended {
+		if now.After(suspended.expires) {
+			delete(s.suspended, key)
+		}
+	}
+
+	if suspended, ok := s.suspended[token]; ok {
+		delete(s.suspended, token)
+		suspended.state.Interests = interests
+		return suspended.state
+	}
+
+	return &StreamState{
+		Token:     uuid.NewString(),
+		Interests: interests,
+		zones:     make(map[int64]*poiZone),
+	}
+}
+
+// Suspend keeps the state of an ended stream for resuming.
+func (s *StreamService) Suspend(state *StreamState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.suspended[state.Token] = &suspendedStream{state: state, expires: time.Now().Add(s.resumeTTL)}
+}
+
+// Locate moves the client of the stream to a new position and returns the
+// events it caused.
+func (s *StreamService) Locate(ctx context.Context, state *StreamState, latitude, longitude float64) ([]*domain.POIEvent, error) {
+	if latitude < -90 || latitude > 90 {
+		return nil, fmt.Errorf("%w: latitude must be between -90 and 90", ErrInvalidFix)
+	}
+	if longitude < -180 || longitude > 180 {
+		return nil, fmt.Errorf("%w: longitude must be between -180 and 180", ErrInvalidFix)
+	}
+
+	pois, err := s.pois.FindNearestPOIs(ctx, domain.NearbyQuery{
+		Latitude:  latitude,
+		Longitude: longitude,
+		Radius:    s.triggerRadius,
+		Margin:    math.Max(s.approachMeters, s.leaveMeters),
+		Interests: state.Interests,
+		Limit:     s.nearbyLimit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]*domain.POIEvent, 0)
+	emit := func(event string, poi *domain.PointOfInterest, distance float64) {
+		state.seq++
+		events = append(events, &domain.POIEvent{Type: event, POI: poi, DistanceMeters: distance, Seq: state.seq})
+	}
+
+	seen := make(map[int64]bool, len(pois))
+	for _, poi := range pois {
+		seen[poi.ID] = true
+		outside := *poi.GeofenceMeters
+
+		zone, known := state.zones[poi.ID]
+		if !known {
+			zone = &poiZone{}
+		}
+		zone.poi = poi
+
+		switch {
+		case outside == 0:
+			if zone.event != domain.POIEventEntered {
+				zone.event = domain.POIEventEntered
+				emit(domain.POIEventEntered, poi, *poi.DistanceMeters)
+			}
+		case zone.event == domain.POIEventEntered:
+			if outside > s.leaveMeters {
+				zone.event = domain.POIEventApproaching
+				emit(domain.POIEventLeft, poi, *poi.DistanceMeters)
+			}
+		case outside <= s.approachMeters:
+			if zone.event == "" {
+				zone.event = domain.POIEventApproaching
+				emit(domain.POIEventApproaching, poi, *poi.DistanceMeters)
+			}
+		}
+
+		if zone.event != "" {
+			state.zones[poi.ID] = zone
+		}
+	}
+
+	// POIs out of the search radius are far enough to be left.
+	for id, zone := range state.zones {
+		if seen[id] {
+			continue
+		}
+		if zone.event == domain.POIEventEntered {
+			emit(domain.POIEventLeft, zone.poi, greatCircleMeters(latitude, longitude, zone.poi.Latitude, zone.poi.Longitude))
+		}
+		delete(state.zones, id)
+	}
+
+	return events, nil
+}
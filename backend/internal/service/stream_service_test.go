@@ -0,0 +1,231 @@
+package service
+
+import (
+	"aigpsservice/internal/domain"
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+// stubPOIFinder answers every search with the POIs set for the next fix.
+type stubPOIFinder struct {
+	pois    []*domain.PointOfInterest
+	queries []domain.NearbyQuery
+}
+
+func (f *stubPOIFinder) FindNearestPOIs(ctx context.Context, query domain.NearbyQuery) ([]*domain.PointOfInterest, error) {
+	f.queries = append(f.queries, query)
+	return f.pois, nil
+}
+
+// at sets the POIs found by the next search: id is found outside meters
+// away from its geofence.
+func (f *stubPOIFinder) at(outside map[int64]float64) {
+	f.pois = f.pois[:0]
+	for id, meters := range outside {
+		geofence, distance := meters, meters+50
+		f.pois = append(f.pois, &domain.PointOfInterest{
+			ID:             id,
+			Latitude:       56.8389,
+			Longitude:      60.6057,
+			GeofenceMeters: &geofence,
+			DistanceMeters: &distance,
+		})
+	}
+	slices.SortFunc(f.pois, func(a, b *domain.PointOfInterest) int { return int(a.ID - b.ID) })
+}
+
+func newTestStreamService(finder nearbyPOIFinder) *StreamService {
+	s := NewStreamService(&POIService{maxNearbyLimit: 50}, 2)
+	s.pois = finder
+	return s
+}
+
+type streamEvent struct {
+	event string
+	poiID int64
+	seq   int64
+}
+
+func locate(t *testing.T, s *StreamService, state *StreamState) []streamEvent {
+	t.Helper()
+	events, err := s.Locate(context.Background(), state, 56.84, 60.61)
+	if err != nil {
+		t.Fatalf("Locate() error = %v", err)
+	}
+	got := make([]streamEvent, 0, len(events))
+	for _, event := range events {
+		got = append(got, streamEvent{event.Type, event.POI.ID, event.Seq})
+	}
+	return got
+}
+
+func TestStreamLocateHysteresis(t *testing.T) {
+	finder := &stubPOIFinder{}
+	s := newTestStreamService(finder)
+	state := s.Resume("", []string{"history"})
+
+	steps := []struct {
+		name    string
+		outside float64
+		want    []streamEvent
+	}{
+		{name: "far away", outside: 500, want: []streamEvent{}},
+		{name: "approaching", outside: 150, want: []streamEvent{{domain.POIEventApproaching, 1, 1}}},
+		{name: "still approaching", outside: 80, want: []streamEvent{}},
+		{name: "entered", outside: 0, want: []streamEvent{{domain.POIEventEntered, 1, 2}}},
+		{name: "jitter inside leave margin", outside: s.leaveMeters, want: []streamEvent{}},
+		{name: "back inside", outside: 0, want: []streamEvent{}},
+		{name: "left past leave margin", outside: s.leaveMeters + 1, want: []streamEvent{{domain.POIEventLeft, 1, 3}}},
+		{name: "no second approaching", outside: 100, want: []streamEvent{}},
+		{name: "entered again", outside: 0, want: []streamEvent{{domain.POIEventEntered, 1, 4}}},
+	}
+
+	for _, step := range steps {
+		finder.at(map[int64]float64{1: step.outside})
+		if got := locate(t, s, state); !slices.Equal(got, step.want) {
+			t.Errorf("%s: events = %v, want %v", step.name, got, step.want)
+		}
+	}
+
+	query := finder.queries[0]
+	if query.Limit != 50 || query.Radius != s.triggerRadius || !slices.Equal(query.Interests, []string{"history"}) {
+		t.Errorf("query = %+v, want the stream interests, trigger radius and nearby limit", query)
+	}
+	if query.Margin < s.approachMeters || query.Margin < s.leaveMeters {
+		t.Errorf("query margin = %v, want at least the approach and leave distances", query.Margin)
+	}
+}
+
+func TestStreamLocateDroppedPOIs(t *testing.T) {
+	finder := &stubPOIFinder{}
+	s := newTestStreamService(finder)
+	state := s.Resume("", nil)
+
+	finder.at(map[int64]float64{1: 0, 2: 100})
+	got := locate(t, s, state)
+	want := []streamEvent{{domain.POIEventEntered, 1, 1}, {domain.POIEventApproaching, 2, 2}}
+	if !slices.Equal(got, want) {
+		t.Fatalf("events = %v, want %v", got, want)
+	}
+
+	// Both POIs drop out of the search: the entered one is left, the
+	// approached one is forgotten without an event.
+	finder.at(nil)
+	got = locate(t, s, state)
+	want = []streamEvent{{domain.POIEventLeft, 1, 3}}
+	if !slices.Equal(got, want) {
+		t.Fatalf("events after dropping out = %v, want %v", got, want)
+	}
+	if len(state.zones) != 0 {
+		t.Errorf("zones = %v, want none", state.zones)
+	}
+
+	// Found again, both start over.
+	finder.at(map[int64]float64{1: 0, 2: 100})
+	got = locate(t, s, state)
+	want = []streamEvent{{domain.POIEventEntered, 1, 4}, {domain.POIEventApproaching, 2, 5}}
+	if !slices.Equal(got, want) {
+		t.Errorf("events after coming back = %v, want %v", got, want)
+	}
+}
+
+func TestStreamResume(t *testing.T) {
+	finder := &stubPOIFinder{}
+	s := newTestStreamService(finder)
+
+	state := s.Resume("", []string{"history"})
+	finder.at(map[int64]float64{1: 0})
+	locate(t, s, state)
+	s.Suspend(state)
+
+	resumed := s.Resume(state.Token, []string{"architecture"})
+	if resumed != state {
+		t.Fatalf("Resume() returned a new stream, want the suspended one")
+	}
+	if !slices.Equal(resumed.Interests, []string{"architecture"}) {
+		t.Errorf("interests = %v, want the ones of the new connection", resumed.Interests)
+	}
+
+	// The POI entered before the reconnect is not entered twice, and the
+	// sequence goes on.
+	if got := locate(t, s, resumed); len(got) != 0 {
+		t.Errorf("events after resume = %v, want none", got)
+	}
+	finder.at(nil)
+	want := []streamEvent{{domain.POIEventLeft, 1, 2}}
+	if got := locate(t, s, resumed); !slices.Equal(got, want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+
+	// A token is resumed once.
+	if again := s.Resume(state.Token, nil); again == state {
+		t.Error("Resume() returned the same stream twice")
+	}
+}
+
+func TestStreamResumeExpired(t *testing.T) {
+	s := newTestStreamService(&stubPOIFinder{})
+	s.resumeTTL = -time.Second
+
+	state := s.Resume("", nil)
+	state.seq = 7
+	s.Suspend(state)
+
+	resumed := s.Resume(state.Token, nil)
+	if resumed == state || resumed.Token == state.Token || resumed.seq != 0 {
+		t.Errorf("Resume() of an expired token = %+v, want a new stream", resumed)
+	}
+	if len(s.suspended) != 0 {
+		t.Errorf("%d suspended streams kept, want the expired one removed", len(s.suspended))
+	}
+
+	unknown := s.Resume("unknown", nil)
+	if unknown.Token == "unknown" || unknown.zones == nil {
+		t.Errorf("Resume() of an unknown token = %+v, want a new stream", unknown)
+	}
+}
+
+func TestStreamLocateInvalidFix(t *testing.T) {
+	finder := &stubPOIFinder{}
+	s := newTestStreamService(finder)
+	state := s.Resume("", nil)
+
+	for _, fix := range [][2]float64{{91, 0}, {-91, 0}, {0, 181}, {0, -181}} {
+		if _, err := s.Locate(context.Background(), state, fix[0], fix[1]); !errors.Is(err, ErrInvalidFix) {
+			t.Errorf("Locate(%v) error = %v, want %v", fix, err, ErrInvalidFix)
+		}
+	}
+	if len(finder.queries) != 0 {
+		t.Errorf("%d searches for invalid fixes, want none", len(finder.queries))
+	}
+}
+
+func TestStreamAcquire(t *testing.T) {
+	s := newTestStreamService(&stubPOIFinder{})
+
+	first, err := s.Acquire("client")
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	if _, err := s.Acquire("client"); err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	if _, err := s.Acquire("client"); !errors.Is(err, ErrTooManyStreams) {
+		t.Fatalf("third Acquire() error = %v, want %v", err, ErrTooManyStreams)
+	}
+	if _, err := s.Acquire("other"); err != nil {
+		t.Errorf("Acquire() of another client error = %v", err)
+	}
+
+	first()
+	first()
+	if _, err := s.Acquire("client"); err != nil {
+		t.Errorf("Acquire() after release error = %v", err)
+	}
+	if _, err := s.Acquire("client"); !errors.Is(err, ErrTooManyStreams) {
+		t.Errorf("Acquire() after a double release error = %v, want %v", err, ErrTooManyStreams)
+	}
+}
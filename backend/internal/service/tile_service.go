@@ -0,0 +1,58 @@
+package service
+
+import (
+	"aigpsservice/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+)
+
+// maxTileZoom is the deepest zoom level tiles are rendered for.
+const maxTileZoom = 22
+
+var ErrInvalidTile = errors.New("invalid tile")
+
+type TileService struct {
+	repo      *repository.TileRepository
+	interests *InterestService
+}
+
+func NewTileService(repo *repository.TileRepository, interests *InterestService) *TileService {
+	return &TileService{
+		repo:      repo,
+		interests: interests,
+	}
+}
+
+// CatalogVersion changes whenever a POI or the interest taxonomy changes, so
+// it identifies the content of every tile.
+func (s *TileService) CatalogVersion(ctx context.Context) (int64, error) {
+	return s.repo.GetCatalogVersion(ctx)
+}
+
+// ValidateTile checks that the tile exists: the zoom is at most maxTileZoom
+// and x and y are within the 2^z tiles of the zoom.
+func (s *TileService) ValidateTile(z, x, y int) error {
+	if z < 0 || z > maxTileZoom {
+		return fmt.Errorf("%w: zoom must be between 0 and %d", ErrInvalidTile, maxTileZoom)
+	}
+	if size := 1 << z; x < 0 || x >= size || y < 0 || y >= size {
+		return fmt.Errorf("%w: x and y must be between 0 and %d at zoom %d", ErrInvalidTile, size-1, z)
+	}
+	return nil
+}
+
+// POITile renders the vector tile z/x/y with the POIs matching any of the
+// interests or their children. Empty interests match every POI.
+func (s *TileService) POITile(ctx context.Context, z, x, y int, interests []string) ([]byte, error) {
+	if err := s.ValidateTile(z, x, y); err != nil {
+		return nil, err
+	}
+
+	expanded, err := s.interests.ExpandInterests(ctx, interests)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetPOITile(ctx, z, x, y, expanded)
+}
@@ -0,0 +1,293 @@
+package service
+
+import (
+	"aigpsservice/internal/domain"
+	"context"
+	"fmt"
+	"math"
+)
+
+const (
+	earthRadiusMeters = 6371008.8
+	// detourFactor turns the great-circle distance between two points into
+	// an estimate of the walking distance along streets.
+	detourFactor = 1.3
+	// maxPlanRadius limits how far from the start POIs are considered.
+	maxPlanRadius = 5000.0
+	// maxPlanCandidates is the number of nearest POIs the planner picks from.
+	maxPlanCandidates = 50
+
+	// The audio duration is estimated from the file size: WAV as produced by
+	// the ml service is 16 bit mono at 24 kHz, anything else is assumed to
+	// be compressed at 128 kbps. Stops without audio get defaultStopSeconds.
+	wavBitsPerSecond        = 16 * 24000.0
+	compressedBitsPerSecond = 128000.0
+	defaultStopSeconds      = 60.0
+)
+
+// PlanTour plans a walk from the start point through POIs matching the
+// interests that fits into the time budget, counting the walking time and
+// the time to listen to the stories of every stop.
+//
+// The stops are picked with the nearest neighbor heuristic, the order is
+// then shortened with 2-opt and the time saved is filled with the POIs that
+// cost the least extra time, until nothing fits anymore.
+func (s *TourService) PlanTour(ctx context.Context, request domain.TourPlanRequest) (*domain.TourPlan, error) {
+	if request.Latitude < -90 || request.Latitude > 90 {
+		return nil, fmt.Errorf("%w: latitude must be between -90 and 90", ErrInvalidTour)
+	}
+	if request.Longitude < -180 || request.Longitude > 180 {
+		return nil, fmt.Errorf("%w: longitude must be between -180 and 180", ErrInvalidTour)
+	}
+	if request.BudgetSeconds < s.minPlanBudget || request.BudgetSeconds > s.maxPlanBudget {
+		return nil, fmt.Errorf("%w: budget must be between %d and %d minutes",
+			ErrInvalidTour, s.minPlanBudget/60, s.maxPlanBudget/60)
+	}
+
+	// The farthest stop must be reachable within the budget, and back again
+	// on a round trip.
+	reach := float64(request.BudgetSeconds) * s.walkingSpeed / detourFactor
+	if request.RoundTrip {
+		reach /= 2
+	}
+
+	// Candidates are the POIs whose trigger area is within reach. Legs are
+	// still measured to the POIs themselves, which keeps the plan in budget.
+	candidates, err := s.pois.FindNearestPOIs(ctx, domain.NearbyQuery{
+		Latitude:  request.Latitude,
+		Longitude: request.Longitude,
+		Margin:    math.Min(reach, maxPlanRadius),
+		Interests: request.Interests,
+		Limit:     maxPlanCandidates,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find tour candidates: %w", err)
+	}
+
+	planner := newTourPlanner(request, candidates, s.walkingSpeed)
+	planner.nearestNeighbor()
+	planner.twoOpt()
+	planner.fill()
+
+	return planner.plan(), nil
+}
+
+// tourPlanner orders candidate POIs. Node 0 is the start point, node i is
+// candidates[i-1].
+type tourPlanner struct {
+	request    domain.TourPlanRequest
+	candidates []*domain.PointOfInterest
+	speed      float64
+
+	// distance holds the estimated walking distance between every two nodes.
+	distance [][]float64
+	audio    []float64
+	route    []int
+	visited  []bool
+}
+
+func newTourPlanner(request domain.TourPlanRequest, candidates []*domain.PointOfInterest, speed float64) *tourPlanner {
+	n := len(candidates) + 1
+	latitudes := make([]float64, n)
+	longitudes := make([]float64, n)
+	latitudes[0], longitudes[0] = request.Latitude, request.Longitude
+
+	p := &tourPlanner{
+		request:    request,
+		candidates: candidates,
+		speed:      speed,
+		distance:   make([][]float64, n),
+		audio:      make([]float64, n),
+		route:      []int{0},
+		visited:    make([]bool, n),
+	}
+	for i, poi := range candidates {
+		latitudes[i+1], longitudes[i+1] = poi.Latitude, poi.Longitude
+		p.audio[i+1] = estimateAudioSeconds(poi)
+	}
+	for i := range p.distance {
+		p.distance[i] = make([]float64, n)
+		for j := range p.distance[i] {
+			p.distance[i][j] = greatCircleMeters(latitudes[i], longitudes[i], latitudes[j], longitudes[j]) * detourFactor
+		}
+	}
+	p.visited[0] = true
+
+	return p
+}
+
+// leg is the walking distance from node a to node b. The end of an open
+// walk is the node -1, reached from anywhere for free.
+func (p *tourPlanner) leg(a, b int) float64 {
+	if a < 0 || b < 0 {
+		return 0
+	}
+	return p.distance[a][b]
+}
+
+// next is the node after position i of the route.
+func (p *tourPlanner) next(i int) int {
+	if i+1 < len(p.route) {
+		return p.route[i+1]
+	}
+	if p.request.RoundTrip {
+		return 0
+	}
+	return -1
+}
+
+func (p *tourPlanner) seconds() float64 {
+	total := 0.0
+	for i, node := range p.route {
+		total += p.leg(node, p.next(i))/p.speed + p.audio[node]
+	}
+	return total
+}
+
+func (p *tourPlanner) nearestNeighbor() {
+	budget := float64(p.request.BudgetSeconds)
+	elapsed := 0.0
+	for {
+		current := p.route[len(p.route)-1]
+		best := -1
+		for candidate := range p.distance {
+			if p.visited[candidate] {
+				continue
+			}
+			cost := p.distance[current][candidate]/p.speed + p.audio[candidate]
+			if p.request.RoundTrip {
+				cost += p.distance[candidate][0] / p.speed
+			}
+			if elapsed+cost > budget {
+				continue
+			}
+			if best < 0 || p.distance[current][candidate] < p.distance[current][best] {
+				best = candidate
+			}
+		}
+		if best < 0 {
+			return
+		}
+
+		elapsed += p.distance[current][best]/p.speed + p.audio[best]
+		p.route = append(p.route, best)
+		p.visited[best] = true
+	}
+}
+
+// twoOpt reverses parts of the route while that makes it shorter. The start
+// stays in place.
+func (p *tourPlanner) twoOpt() {
+	for improved := true; improved; {
+		improved = false
+		for i := 1; i < len(p.route)-1; i++ {
+			for k := i + 1; k < len(p.route); k++ {
+				before, first, last, after := p.route[i-1], p.route[i], p.route[k], p.next(k)
+				delta := p.leg(before, last) + p.leg(first, after) - p.leg(before, first) - p.leg(last, after)
+				if delta < -1e-6 {
+					for a, b := i, k; a < b; a, b = a+1, b-1 {
+						p.route[a], p.route[b] = p.route[b], p.route[a]
+					}
+					improved = true
+				}
+			}
+		}
+	}
+}
+
+// fill inserts the remaining candidates at their cheapest position while
+// they fit into the budget.
+func (p *tourPlanner) fill() {
+	budget := float64(p.request.BudgetSeconds)
+	for {
+		remaining := budget - p.seconds()
+		bestNode, bestPosition, bestCost := -1, 0, math.Inf(1)
+		for candidate := range p.distance {
+			if p.visited[candidate] {
+				continue
+			}
+			for i, node := range p.route {
+				after := p.next(i)
+				added := p.leg(node, candidate) + p.leg(candidate, after) - p.leg(node, after)
+				cost := added/p.speed + p.audio[candidate]
+				if cost <= remaining && cost < bestCost {
+					bestNode, bestPosition, bestCost = candidate, i+1, cost
+				}
+			}
+		}
+		if bestNode < 0 {
+			return
+		}
+
+		p.route = append(p.route[:bestPosition], append([]int{bestNode}, p.route[bestPosition:]...)...)
+		p.visited[bestNode] = true
+		p.twoOpt()
+	}
+}
+
+func (p *tourPlanner) plan() *domain.TourPlan {
+	plan := &domain.TourPlan{
+		Latitude:      p.request.Latitude,
+		Longitude:     p.request.Longitude,
+		RoundTrip:     p.request.RoundTrip,
+		Stops:         make([]*domain.TourPlanStop, 0, len(p.route)-1),
+		BudgetSeconds: p.request.BudgetSeconds,
+	}
+
+	elapsed := 0.0
+	for i := 1; i < len(p.route); i++ {
+		node := p.route[i]
+		leg := p.distance[p.route[i-1]][node]
+		elapsed += leg / p.speed
+		plan.Stops = append(plan.Stops, &domain.TourPlanStop{
+			POI:            p.candidates[node-1],
+			LegMeters:      math.Round(leg),
+			ArrivalSeconds: int(math.Round(elapsed)),
+			AudioSeconds:   int(math.Round(p.audio[node])),
+		})
+		elapsed += p.audio[node]
+		plan.DistanceMeters += leg
+		plan.AudioSeconds += int(math.Round(p.audio[node]))
+	}
+	if p.request.RoundTrip && len(p.route) > 1 {
+		plan.DistanceMeters += p.distance[p.route[len(p.route)-1]][0]
+	}
+
+	plan.DistanceMeters = math.Round(plan.DistanceMeters)
+	plan.WalkingSeconds = int(math.Round(plan.DistanceMeters / p.speed))
+	plan.DurationSeconds = plan.WalkingSeconds + plan.AudioSeconds
+
+	return plan
+}
+
+// estimateAudioSeconds estimates how long the full stories of a POI play,
+// or its short audio if it has no full one.
+func estimateAudioSeconds(poi *domain.PointOfInterest) float64 {
+	files := poi.FullAudioFiles
+	if len(files) == 0 && poi.ShortAudioFile != nil {
+		files = []*domain.File{poi.ShortAudioFile}
+	}
+
+	seconds := 0.0
+	for _, file := range files {
+		bitsPerSecond := compressedBitsPerSecond
+		if file.MimeType == "audio/wav" || file.MimeType == "audio/x-wav" {
+			bitsPerSecond = wavBitsPerSecond
+		}
+		seconds += float64(file.FileSize) * 8 / bitsPerSecond
+	}
+	if seconds == 0 {
+		return defaultStopSeconds
+	}
+	return seconds
+}
+
+// greatCircleMeters is the haversine distance between two WGS 84 points.
+func greatCircleMeters(lat1, lon1, lat2, lon2 float64) float64 {
+	phi1, phi2 := lat1*math.Pi/180, lat2*math.Pi/180
+	dPhi := phi2 - phi1
+	dLambda := (lon2 - lon1) * math.Pi / 180
+
+	a := math.Sin(dPhi/2)*math.Sin(dPhi/2) + math.Cos(phi1)*math.Cos(phi2)*math.Sin(dLambda/2)*math.Sin(dLambda/2)
+	return 2 * earthRadiusMeters * math.Asin(math.Min(1, math.Sqrt(a)))
+}
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"aigpsservice/internal/domain"
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+const testWalkingSpeed = 1.4
+
+// randomCandidates scatters n POIs with compressed audio of 1 to 5 minutes
+// within about radius meters of the start.
+func randomCandidates(seed uint64, n int, latitude, longitude, radius float64) []*domain.PointOfInterest {
+	random := rand.New(rand.NewPCG(seed, seed))
+	metersPerDegree := earthRadiusMeters * math.Pi / 180
+
+	candidates := make([]*domain.PointOfInterest, 0, n)
+	for i := range n {
+		audioSeconds := 60 + random.IntN(240)
+		candidates = append(candidates, &domain.PointOfInterest{
+			ID:        int64(i + 1),
+			Latitude:  latitude + (random.Float64()*2-1)*radius/metersPerDegree,
+			Longitude: longitude + (random.Float64()*2-1)*radius/metersPerDegree/math.Cos(latitude*math.Pi/180),
+			FullAudioFiles: []*domain.File{
+				{MimeType: "audio/mpeg", FileSize: int64(audioSeconds) * compressedBitsPerSecond / 8},
+			},
+		})
+	}
+	return candidates
+}
+
+// routeMeters is the walking length of the current route of the planner.
+func routeMeters(p *tourPlanner) float64 {
+	total := 0.0
+	for i, node := range p.route {
+		total += p.leg(node, p.next(i))
+	}
+	return total
+}
+
+func TestTourPlannerBudget(t *testing.T) {
+	tests := []struct {
+		name       string
+		seed       uint64
+		candidates int
+		radius     float64
+		budget     int
+		roundTrip  bool
+		// wantStops is the number of stops of the plan, -1 for any.
+		wantStops int
+	}{
+		{name: "open short", seed: 1, candidates: 30, radius: 1500, budget: 20 * 60, wantStops: -1},
+		{name: "open long", seed: 2, candidates: 50, radius: 3000, budget: 3 * 60 * 60, wantStops: -1},
+		{name: "round trip short", seed: 3, candidates: 30, radius: 1500, budget: 20 * 60, roundTrip: true, wantStops: -1},
+		{name: "round trip long", seed: 4, candidates: 50, radius: 3000, budget: 3 * 60 * 60, roundTrip: true, wantStops: -1},
+		{name: "nothing fits", seed: 5, candidates: 10, radius: 4000, budget: 60, roundTrip: true, wantStops: 0},
+		{name: "everything fits", seed: 6, candidates: 5, radius: 200, budget: 4 * 60 * 60, wantStops: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := domain.TourPlanRequest{
+				Latitude:      56.8389,
+				Longitude:     60.6057,
+				BudgetSeconds: tt.budget,
+				RoundTrip:     tt.roundTrip,
+			}
+			candidates := randomCandidates(tt.seed, tt.candidates, request.Latitude, request.Longitude, tt.radius)
+
+			planner := newTourPlanner(request, candidates, testWalkingSpeed)
+			planner.nearestNeighbor()
+			if seconds := planner.seconds(); seconds > float64(tt.budget) {
+				t.Errorf("nearest neighbor route takes %.0f s, budget is %d s", seconds, tt.budget)
+			}
+			planner.twoOpt()
+			planner.fill()
+			if seconds := planner.seconds(); seconds > float64(tt.budget) {
+				t.Errorf("filled route takes %.0f s, budget is %d s", seconds, tt.budget)
+			}
+
+			plan := planner.plan()
+			// The walking time is rounded once, the audio is a whole number
+			// of seconds.
+			if plan.DurationSeconds > tt.budget+1 {
+				t.Errorf("plan takes %d s, budget is %d s", plan.DurationSeconds, tt.budget)
+			}
+			if plan.DurationSeconds != plan.WalkingSeconds+plan.AudioSeconds {
+				t.Errorf("plan takes %d s, want walking %d s plus audio %d s", plan.DurationSeconds, plan.WalkingSeconds, plan.AudioSeconds)
+			}
+
+			seen := make(map[int64]bool)
+			for _, stop := range plan.Stops {
+				if seen[stop.POI.ID] {
+					t.Errorf("POI %d visited twice", stop.POI.ID)
+				}
+				seen[stop.POI.ID] = true
+			}
+			if tt.wantStops >= 0 && len(plan.Stops) != tt.wantStops {
+				t.Errorf("plan has %d stops, want %d", len(plan.Stops), tt.wantStops)
+			}
+		})
+	}
+}
+
+func TestTourPlannerTwoOptNeverLengthens(t *testing.T) {
+	for _, roundTrip := range []bool{false, true} {
+		for seed := uint64(1); seed <= 20; seed++ {
+			request := domain.TourPlanRequest{Latitude: 56.8389, Longitude: 60.6057, BudgetSeconds: 4 * 60 * 60, RoundTrip: roundTrip}
+			planner := newTourPlanner(request, randomCandidates(seed, 25, request.Latitude, request.Longitude, 2000), testWalkingSpeed)
+			planner.nearestNeighbor()
+
+			// A shuffled route gives 2-opt crossings to remove.
+			random := rand.New(rand.NewPCG(seed, 0))
+			random.Shuffle(len(planner.route)-1, func(i, j int) {
+				planner.route[i+1], planner.route[j+1] = planner.route[j+1], planner.route[i+1]
+			})
+
+			stops := len(planner.route)
+			before := routeMeters(planner)
+			planner.twoOpt()
+			after := routeMeters(planner)
+
+			if after > before+1e-6 {
+				t.Errorf("round trip %t, seed %d: 2-opt lengthened the route from %.0f m to %.0f m", roundTrip, seed, before, after)
+			}
+			if planner.route[0] != 0 {
+				t.Errorf("round trip %t, seed %d: route starts at node %d, want the start point", roundTrip, seed, planner.route[0])
+			}
+			if len(planner.route) != stops {
+				t.Errorf("round trip %t, seed %d: route has %d nodes, want %d", roundTrip, seed, len(planner.route), stops)
+			}
+		}
+	}
+}
+
+func TestTourPlannerTwoOptUncrosses(t *testing.T) {
+	// Four corners of a square visited crosswise: start, far corner, side,
+	// side. 2-opt walks around the square instead.
+	request := domain.TourPlanRequest{Latitude: 0, Longitude: 0, BudgetSeconds: 4 * 60 * 60, RoundTrip: true}
+	candidates := []*domain.PointOfInterest{
+		{ID: 1, Latitude: 0.01, Longitude: 0.01},
+		{ID: 2, Latitude: 0.01, Longitude: 0},
+		{ID: 3, Latitude: 0, Longitude: 0.01},
+	}
+	planner := newTourPlanner(request, candidates, testWalkingSpeed)
+	planner.route = []int{0, 2, 3, 1}
+	planner.visited = []bool{true, true, true, true}
+
+	before := routeMeters(planner)
+	planner.twoOpt()
+	after := routeMeters(planner)
+
+	if after >= before {
+		t.Errorf("2-opt left the crossing route at %.0f m, was %.0f m", after, before)
+	}
+	if planner.route[2] != 1 {
+		t.Errorf("route = %v, want the far corner in the middle", planner.route)
+	}
+}
+
+func TestTourPlannerNoCandidates(t *testing.T) {
+	for _, roundTrip := range []bool{false, true} {
+		request := domain.TourPlanRequest{Latitude: 56.8389, Longitude: 60.6057, BudgetSeconds: 60 * 60, RoundTrip: roundTrip}
+
+		planner := newTourPlanner(request, nil, testWalkingSpeed)
+		planner.nearestNeighbor()
+		planner.twoOpt()
+		planner.fill()
+		plan := planner.plan()
+
+		if len(plan.Stops) != 0 || plan.DistanceMeters != 0 || plan.DurationSeconds != 0 {
+			t.Errorf("round trip %t: plan = %+v, want an empty one", roundTrip, plan)
+		}
+		if plan.Stops == nil {
+			t.Errorf("round trip %t: stops are nil, want an empty list", roundTrip)
+		}
+		if plan.BudgetSeconds != request.BudgetSeconds {
+			t.Errorf("round trip %t: budget = %d, want %d", roundTrip, plan.BudgetSeconds, request.BudgetSeconds)
+		}
+	}
+}
+
+func TestEstimateAudioSeconds(t *testing.T) {
+	tests := []struct {
+		name string
+		poi  *domain.PointOfInterest
+		want float64
+	}{
+		{
+			name: "no audio",
+			poi:  &domain.PointOfInterest{},
+			want: defaultStopSeconds,
+		},
+		{
+			name: "compressed full audio",
+			poi: &domain.PointOfInterest{FullAudioFiles: []*domain.File{
+				{MimeType: "audio/mpeg", FileSize: 16000 * 90},
+				{MimeType: "audio/ogg", FileSize: 16000 * 30},
+			}},
+			want: 120,
+		},
+		{
+			name: "wav full audio",
+			poi: &domain.PointOfInterest{FullAudioFiles: []*domain.File{
+				{MimeType: "audio/wav", FileSize: 48000 * 100},
+				{MimeType: "audio/x-wav", FileSize: 48000 * 20},
+			}},
+			want: 120,
+		},
+		{
+			name: "full audio wins over short",
+			poi: &domain.PointOfInterest{
+				ShortAudioFile: &domain.File{MimeType: "audio/mpeg", FileSize: 16000 * 10},
+				FullAudioFiles: []*domain.File{{MimeType: "audio/mpeg", FileSize: 16000 * 200}},
+			},
+			want: 200,
+		},
+		{
+			name: "short audio only",
+			poi: &domain.PointOfInterest{
+				ShortAudioFile: &domain.File{MimeType: "audio/wav", FileSize: 48000 * 15},
+				FullAudioFiles: []*domain.File{},
+			},
+			want: 15,
+		},
+		{
+			name: "unknown sizes",
+			poi: &domain.PointOfInterest{FullAudioFiles: []*domain.File{
+				{MimeType: "audio/mpeg"},
+			}},
+			want: defaultStopSeconds,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := estimateAudioSeconds(tt.poi); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("estimateAudioSeconds() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"aigpsservice/internal/domain"
+	"aigpsservice/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+)
+
+var ErrInvalidTour = errors.New("invalid tour")
+
+type TourService struct {
+	repo *repository.TourRepository
+	pois *POIService
+
+	maxStops     int
+	maxListLimit int
+	// walkingSpeed in meters per second turns the tour distance into its
+	// estimated duration.
+	walkingSpeed float64
+	// Planned walks last from minPlanBudget to maxPlanBudget seconds.
+	minPlanBudget int
+	maxPlanBudget int
+}
+
+func NewTourService(repo *repository.TourRepository, pois *POIService) *TourService {
+	return &TourService{
+		repo:         repo,
+		pois:         pois,
+		maxStops:     200,
+		maxListLimit: 100,
+		walkingSpeed: 1.25,
+
+		minPlanBudget: 10 * 60,
+		maxPlanBudget: 8 * 60 * 60,
+	}
+}
+
+// NewFileUploads streams the cover image and the intro and outro audio of a
+// tour to storage, with the same checks and limits as POI files.
+func (s *TourService) NewFileUploads(ctx context.Context) *FileUploads {
+	return s.pois.NewFileUploads(ctx)
+}
+
+// CreateTour saves a tour whose files were already streamed to storage with
+// uploads. On failure the uploaded files are left to uploads.Discard.
+func (s *TourService) CreateTour(ctx context.Context, uploads *FileUploads, tour *domain.Tour) (*domain.Tour, error) {
+	if tour.Name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidTour)
+	}
+	if err := s.validateStops(tour.POIIDs); err != nil {
+		return nil, err
+	}
+
+	created, err := s.repo.CreateTour(ctx, tour)
+	if err != nil {
+		return nil, err
+	}
+	uploads.keep()
+
+	return s.GetTour(ctx, created.ID)
+}
+
+// GetTour returns the tour with its stops.
+func (s *TourService) GetTour(ctx context.Context, id int64) (*domain.Tour, error) {
+	tour, err := s.repo.GetTour(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	tour.POIs, err = s.pois.GetPOIsByIDs(ctx, tour.POIIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tour stops: %w", err)
+	}
+	s.estimate(tour)
+
+	return tour, nil
+}
+
+// ListTours returns one page of tours without their stops. The cursor is
+// the opaque next_cursor of the previous page, or empty for the first page.
+func (s *TourService) ListTours(ctx context.Context, cursor string, limit int) (*domain.TourPage, error) {
+	if limit < 1 || limit > s.maxListLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidTour, s.maxListLimit)
+	}
+
+	var after *domain.POICursor
+	if cursor != "" {
+		decoded, err := decodePOICursor(cursor)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidTour, err)
+		}
+		after = decoded
+	}
+
+	tours, err := s.repo.ListTours(ctx, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	for _, tour := range tours {
+		s.estimate(tour)
+	}
+
+	page := &domain.TourPage{Items: tours}
+	if len(tours) > limit {
+		page.Items = tours[:limit]
+		last := page.Items[limit-1]
+		page.NextCursor = encodePOICursor(domain.POICursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+
+	return page, nil
+}
+
+// SetTourPOIs replaces the stops of a tour, which reorders, adds or removes
+// them, and returns the updated tour.
+func (s *TourService) SetTourPOIs(ctx context.Context, id int64, poiIDs []int64) (*domain.Tour, error) {
+	if err := s.validateStops(poiIDs); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SetTourPOIs(ctx, id, poiIDs); err != nil {
+		return nil, err
+	}
+
+	return s.GetTour(ctx, id)
+}
+
+func (s *TourService) validateStops(poiIDs []int64) error {
+	if len(poiIDs) == 0 {
+		return fmt.Errorf("%w: at least one point of interest is required", ErrInvalidTour)
+	}
+	if len(poiIDs) > s.maxStops {
+		return fmt.Errorf("%w: at most %d points of interest are allowed", ErrInvalidTour, s.maxStops)
+	}
+
+	seen := make(map[int64]bool, len(poiIDs))
+	for _, id := range poiIDs {
+		if seen[id] {
+			return fmt.Errorf("%w: point of interest %d occurs more than once", ErrInvalidTour, id)
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+func (s *TourService) estimate(tour *domain.Tour) {
+	tour.DurationSeconds = int(math.Round(tour.DistanceMeters / s.walkingSpeed))
+}
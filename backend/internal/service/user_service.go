@@ -0,0 +1,177 @@
+package service
+
+import (
+	"aigpsservice/internal/domain"
+	"aigpsservice/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrInvalidUser  = errors.New("invalid user")
+	ErrInvalidLogin = errors.New("invalid email or password")
+)
+
+// voices are the TTS voices of the ml service.
+var voices = map[string]bool{"aidar": true, "baya": true}
+
+type UserService struct {
+	repo      *repository.UserRepository
+	auth      *AuthService
+	interests *InterestService
+
+	minPasswordLength int
+	maxInterests      int
+	defaultLanguage   string
+}
+
+func NewUserService(repo *repository.UserRepository, auth *AuthService, interests *InterestService) *UserService {
+	return &UserService{
+		repo:      repo,
+		auth:      auth,
+		interests: interests,
+
+		minPasswordLength: 8,
+		maxInterests:      50,
+		defaultLanguage:   domain.DefaultLanguage,
+	}
+}
+
+// Register creates a user and signs them in.
+func (s *UserService) Register(ctx context.Context, email, password string, profile domain.UserProfile) (*domain.AuthToken, error) {
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != strings.TrimSpace(email) {
+		return nil, fmt.Errorf("%w: email is not valid", ErrInvalidUser)
+	}
+	if len(password) < s.minPasswordLength {
+		return nil, fmt.Errorf("%w: password must be at least %d characters", ErrInvalidUser, s.minPasswordLength)
+	}
+	// bcrypt ignores everything past 72 bytes.
+	if len(password) > 72 {
+		return nil, fmt.Errorf("%w: password must be at most 72 bytes", ErrInvalidUser)
+	}
+	if err := s.normalizeProfile(ctx, &profile); err != nil {
+		return nil, err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	user, err := s.repo.CreateUser(ctx, &domain.User{Email: address.Address, Profile: profile}, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.auth.IssueToken(user)
+}
+
+// Login checks the password of a user and signs them in.
+func (s *UserService) Login(ctx context.Context, email, password string) (*domain.AuthToken, error) {
+	user, hash, err := s.repo.GetUserByEmail(ctx, strings.TrimSpace(email))
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return nil, ErrInvalidLogin
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword(hash, []byte(password)); err != nil {
+		return nil, ErrInvalidLogin
+	}
+
+	return s.auth.IssueToken(user)
+}
+
+func (s *UserService) GetUser(ctx context.Context, id int64) (*domain.User, error) {
+	return s.repo.GetUser(ctx, id)
+}
+
+// UpdateProfile replaces the profile of a user and returns the user.
+func (s *UserService) UpdateProfile(ctx context.Context, id int64, profile domain.UserProfile) (*domain.User, error) {
+	if err := s.normalizeProfile(ctx, &profile); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateProfile(ctx, id, &profile); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetUser(ctx, id)
+}
+
+// CurrentProfile returns the profile of the signed-in user of ctx, nil for
+// anonymous callers and API keys.
+func (s *UserService) CurrentProfile(ctx context.Context) (*domain.UserProfile, error) {
+	principal := PrincipalFrom(ctx)
+	if principal == nil || principal.UserID == 0 {
+		return nil, nil
+	}
+
+	user, err := s.repo.GetUser(ctx, principal.UserID)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &user.Profile, nil
+}
+
+// normalizeProfile validates the profile and fills in the defaults.
+func (s *UserService) normalizeProfile(ctx context.Context, profile *domain.UserProfile) error {
+	if len(profile.Interests) > s.maxInterests {
+		return fmt.Errorf("%w: at most %d interests are allowed", ErrInvalidUser, s.maxInterests)
+	}
+
+	seen := make(map[string]bool, len(profile.Interests))
+	for i := range profile.Interests {
+		interest := &profile.Interests[i]
+		if seen[interest.Interest] {
+			return fmt.Errorf("%w: interest %q occurs more than once", ErrInvalidUser, interest.Interest)
+		}
+		seen[interest.Interest] = true
+
+		if interest.Weight == 0 {
+			interest.Weight = 1
+		}
+		if interest.Weight < 0 || interest.Weight > 1 {
+			return fmt.Errorf("%w: interest weight must be between 0 and 1", ErrInvalidUser)
+		}
+	}
+	if profile.Interests == nil {
+		profile.Interests = []domain.InterestWeight{}
+	}
+	if err := s.interests.ValidateInterests(ctx, profile.InterestIDs()); err != nil {
+		return err
+	}
+
+	if profile.Voice != "" && !voices[profile.Voice] {
+		return fmt.Errorf("%w: voice must be aidar or baya", ErrInvalidUser)
+	}
+
+	switch profile.NarrationLength {
+	case "":
+		profile.NarrationLength = domain.NarrationFull
+	case domain.NarrationShort, domain.NarrationFull:
+	default:
+		return fmt.Errorf("%w: narration length must be short or full", ErrInvalidUser)
+	}
+
+	profile.Language = strings.TrimSpace(profile.Language)
+	if profile.Language == "" {
+		profile.Language = s.defaultLanguage
+	}
+	if !languagePattern.MatchString(profile.Language) {
+		return fmt.Errorf("%w: language must be a language tag such as ru or en", ErrInvalidUser)
+	}
+
+	return nil
+}
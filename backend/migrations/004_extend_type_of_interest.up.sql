@@ -0,0 +1,20 @@
+ALTER TABLE type_of_interest
+    ADD COLUMN IF NOT EXISTS parent_id VARCHAR(32),
+    ADD COLUMN IF NOT EXISTS names JSONB NOT NULL DEFAULT '{}'::jsonb,
+    ADD COLUMN IF NOT EXISTS icon VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+ALTER TABLE type_of_interest
+    ADD CONSTRAINT fk_type_of_interest_parent
+        FOREIGN KEY (parent_id)
+        REFERENCES type_of_interest(id),
+    ADD CONSTRAINT chk_type_of_interest_parent
+        CHECK (parent_id IS NULL OR parent_id <> id);
+
+CREATE INDEX IF NOT EXISTS idx_type_of_interest_parent_id ON type_of_interest(parent_id);
+
+UPDATE type_of_interest SET names = '{"ru": "Природа", "en": "Nature"}'::jsonb WHERE id = 'nature';
+UPDATE type_of_interest SET names = '{"ru": "Архитектура", "en": "Architecture"}'::jsonb WHERE id = 'architecture';
+UPDATE type_of_interest SET names = '{"ru": "Еда", "en": "Food"}'::jsonb WHERE id = 'food';
+UPDATE type_of_interest SET names = '{"ru": "История", "en": "History"}'::jsonb WHERE id = 'history';
@@ -0,0 +1,30 @@
+-- catalog_version counts changes of the POI catalog, so cached views of it
+-- such as map tiles can be revalidated cheaply.
+CREATE TABLE IF NOT EXISTS catalog_version (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE,
+    version BIGINT NOT NULL DEFAULT 1,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT chk_catalog_version_single_row CHECK (id)
+);
+
+INSERT INTO catalog_version (id) VALUES (TRUE) ON CONFLICT (id) DO NOTHING;
+
+CREATE OR REPLACE FUNCTION bump_catalog_version() RETURNS trigger AS $$
+BEGIN
+    UPDATE catalog_version SET version = version + 1, updated_at = CURRENT_TIMESTAMP;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_points_of_interest_catalog_version
+    AFTER INSERT OR UPDATE OR DELETE ON points_of_interest
+    FOR EACH STATEMENT EXECUTE FUNCTION bump_catalog_version();
+
+CREATE TRIGGER trg_points_of_interest_type_catalog_version
+    AFTER INSERT OR UPDATE OR DELETE ON points_of_interest_type
+    FOR EACH STATEMENT EXECUTE FUNCTION bump_catalog_version();
+
+CREATE TRIGGER trg_type_of_interest_catalog_version
+    AFTER INSERT OR UPDATE OR DELETE ON type_of_interest
+    FOR EACH STATEMENT EXECUTE FUNCTION bump_catalog_version();
@@ -0,0 +1,37 @@
+CREATE TABLE IF NOT EXISTS tours (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_tours_created_at ON tours(created_at);
+
+-- The stops of a tour in walking order.
+CREATE TABLE IF NOT EXISTS tour_points (
+    tour_id INTEGER NOT NULL REFERENCES tours(id) ON DELETE CASCADE,
+    poi_id INTEGER NOT NULL,
+    position INTEGER NOT NULL,
+
+    PRIMARY KEY (tour_id, position),
+    CONSTRAINT uq_tour_points_poi UNIQUE (tour_id, poi_id),
+    CONSTRAINT fk_tour_points_poi FOREIGN KEY (poi_id) REFERENCES points_of_interest(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_tour_points_poi_id ON tour_points(poi_id);
+
+-- The cover image and the audio played before the first and after the last stop.
+CREATE TABLE IF NOT EXISTS tour_files (
+    id SERIAL PRIMARY KEY,
+    tour_id INTEGER NOT NULL REFERENCES tours(id) ON DELETE CASCADE,
+    kind VARCHAR(16) NOT NULL,
+    s3_key VARCHAR(500) NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    file_size BIGINT,
+    mime_type VARCHAR(100),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT uq_tour_files_kind UNIQUE (tour_id, kind),
+    CONSTRAINT chk_tour_files_kind CHECK (kind IN ('cover', 'intro', 'outro'))
+);
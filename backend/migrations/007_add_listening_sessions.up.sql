@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS listening_sessions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    interests TEXT[] NOT NULL DEFAULT '{}',
+    radius INTEGER NOT NULL,
+    cooldown_seconds INTEGER NOT NULL,
+    last_location GEOMETRY(Point, 4326),
+    last_played_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Audio played in a session. A row without file_id marks the POI as heard
+-- even if some of its audio was skipped.
+CREATE TABLE IF NOT EXISTS session_plays (
+    id BIGSERIAL PRIMARY KEY,
+    session_id UUID NOT NULL REFERENCES listening_sessions(id) ON DELETE CASCADE,
+    poi_id INTEGER NOT NULL,
+    file_id INTEGER REFERENCES poi_files(id) ON DELETE SET NULL,
+    played_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_session_plays_poi FOREIGN KEY (poi_id) REFERENCES points_of_interest(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_session_plays_session_poi ON session_plays(session_id, poi_id);
@@ -0,0 +1,15 @@
+-- The trigger area of a POI is a circle of trigger_radius meters around its
+-- location or the geofence polygon. POIs with neither use the radius of the
+-- search.
+ALTER TABLE points_of_interest
+    ADD COLUMN IF NOT EXISTS trigger_radius DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS geofence GEOMETRY(Geometry, 4326);
+
+ALTER TABLE points_of_interest
+    ADD CONSTRAINT chk_poi_trigger_area CHECK (
+        (trigger_radius IS NULL OR geofence IS NULL)
+        AND (trigger_radius IS NULL OR trigger_radius > 0)
+        AND (geofence IS NULL OR GeometryType(geofence) IN ('POLYGON', 'MULTIPOLYGON'))
+    );
+
+CREATE INDEX IF NOT EXISTS idx_poi_geofence ON points_of_interest USING GIST(geofence);
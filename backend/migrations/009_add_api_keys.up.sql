@@ -0,0 +1,14 @@
+-- API keys are stored as SHA-256 hashes, the secret is only shown once when
+-- the key is created. prefix is the start of the secret to tell keys apart.
+CREATE TABLE IF NOT EXISTS api_keys (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    prefix VARCHAR(16) NOT NULL,
+    key_hash BYTEA NOT NULL,
+    role VARCHAR(16) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+
+    CONSTRAINT uq_api_keys_hash UNIQUE (key_hash),
+    CONSTRAINT chk_api_keys_role CHECK (role IN ('listener', 'editor', 'admin'))
+);
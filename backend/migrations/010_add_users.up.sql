@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS users (
+    id BIGSERIAL PRIMARY KEY,
+    email VARCHAR(255) NOT NULL,
+    password_hash TEXT NOT NULL,
+    voice VARCHAR(32) NOT NULL DEFAULT '',
+    narration_length VARCHAR(8) NOT NULL DEFAULT 'full',
+    language VARCHAR(8) NOT NULL DEFAULT 'ru',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT chk_users_narration_length CHECK (narration_length IN ('short', 'full'))
+);
+
+-- Emails are compared case-insensitively.
+CREATE UNIQUE INDEX IF NOT EXISTS uq_users_email ON users (lower(email));
+
+-- Interests of a user profile, weight tells how much the user cares.
+CREATE TABLE IF NOT EXISTS user_interests (
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    interest_id VARCHAR(32) NOT NULL REFERENCES type_of_interest(id) ON DELETE CASCADE,
+    weight DOUBLE PRECISION NOT NULL DEFAULT 1,
+
+    PRIMARY KEY (user_id, interest_id),
+    CONSTRAINT chk_user_interests_weight CHECK (weight > 0 AND weight <= 1)
+);
@@ -0,0 +1,32 @@
+-- Playback events reported by the apps. The table is append-only: rows are
+-- never changed, and there are no foreign keys, so replacing or deleting
+-- media keeps the history. poi_id is copied from poi_files on insert.
+CREATE TABLE IF NOT EXISTS playback_events (
+    id BIGSERIAL PRIMARY KEY,
+    file_id INTEGER NOT NULL,
+    poi_id INTEGER NOT NULL,
+    user_id BIGINT,
+    session_id UUID,
+    event VARCHAR(16) NOT NULL,
+    position_seconds DOUBLE PRECISION NOT NULL,
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    received_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT chk_playback_events_event CHECK (event IN ('started', 'completed', 'skipped')),
+    CONSTRAINT chk_playback_events_position CHECK (position_seconds >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_playback_events_poi_occurred ON playback_events(poi_id, occurred_at);
+CREATE INDEX IF NOT EXISTS idx_playback_events_occurred ON playback_events(occurred_at);
+CREATE INDEX IF NOT EXISTS idx_playback_events_user ON playback_events(user_id, poi_id) WHERE user_id IS NOT NULL;
+
+CREATE OR REPLACE FUNCTION reject_playback_event_change() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'playback_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_playback_events_append_only ON playback_events;
+CREATE TRIGGER trg_playback_events_append_only
+    BEFORE UPDATE OR DELETE ON playback_events
+    FOR EACH ROW EXECUTE FUNCTION reject_playback_event_change();
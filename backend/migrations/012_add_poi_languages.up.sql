@@ -0,0 +1,25 @@
+-- Audio tracks have a language, images have none. Everything recorded so
+-- far is Russian, the default language.
+ALTER TABLE poi_files
+    ADD COLUMN IF NOT EXISTS language VARCHAR(16);
+
+UPDATE poi_files SET language = 'ru' WHERE language IS NULL AND serial_number <> 0;
+
+ALTER TABLE poi_files
+    ADD CONSTRAINT chk_poi_files_language CHECK ((serial_number = 0) = (language IS NULL));
+
+CREATE INDEX IF NOT EXISTS idx_poi_files_poi_language ON poi_files(poi_id, language);
+
+-- Names and descriptions of POIs in other languages. The ones in
+-- points_of_interest are in the default language.
+CREATE TABLE IF NOT EXISTS poi_translations (
+    poi_id INTEGER NOT NULL,
+    language VARCHAR(16) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (poi_id, language),
+    CONSTRAINT fk_poi_translations_poi FOREIGN KEY (poi_id) REFERENCES points_of_interest(id) ON DELETE CASCADE
+);
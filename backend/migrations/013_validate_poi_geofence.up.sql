@@ -0,0 +1,13 @@
+-- Geofences must be valid geometries: PostGIS answers distance and
+-- containment queries on self-intersecting polygons with errors or wrong
+-- results. Invalid ones already stored are repaired first.
+UPDATE points_of_interest
+SET geofence = ST_Multi(ST_CollectionExtract(ST_MakeValid(geofence), 3))
+WHERE geofence IS NOT NULL AND NOT ST_IsValid(geofence);
+
+UPDATE points_of_interest
+SET geofence = NULL
+WHERE geofence IS NOT NULL AND ST_IsEmpty(geofence);
+
+ALTER TABLE points_of_interest
+    ADD CONSTRAINT chk_poi_geofence_valid CHECK (geofence IS NULL OR ST_IsValid(geofence));
@@ -22,6 +22,15 @@ llm_system_message = {
     "content": system_promt
 }
 
+# Названия языков рассказа по коду ISO 639
+language_names = {
+    "ru": "русском",
+    "en": "английском",
+    "de": "немецком",
+    "es": "испанском",
+    "fr": "французском",
+}
+
 @router.post('/generate', response_model=models.LLMResponse)
 async def create_desc(promt: models.LLMCreate):
     try:
@@ -40,6 +49,10 @@ async def create_desc(promt: models.LLMCreate):
         },
         messages=[
             llm_system_message,
+            {
+                "role": "system",
+                "content": f"Напиши рассказ на {language_names.get(promt.language, promt.language)} языке."
+            },
             {
                 "role": "user",
                 "content": (
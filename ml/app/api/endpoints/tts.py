@@ -1,21 +1,39 @@
 import io
 import tempfile
 from silero import silero_tts
-from fastapi import APIRouter
+from fastapi import APIRouter, HTTPException
 from fastapi.responses import FileResponse
 from app.schemas import models
 
 router = APIRouter()
 
+# Модель silero и голос по умолчанию для каждого языка
+TTS_MODELS = {
+    'ru': ('v5_ru', 'aidar'),
+    'en': ('v3_en', 'en_0'),
+    'de': ('v3_de', 'karlsson'),
+    'es': ('v3_es', 'es_0'),
+    'fr': ('v3_fr', 'fr_0'),
+}
+
 @router.post('/generate', response_class=FileResponse)
 async def create_file(tts_info: models.TTSGenerate):
+    if tts_info.language not in TTS_MODELS:
+        raise HTTPException(status_code=400, detail=f'unsupported language: {tts_info.language}')
+    model_id, default_speaker = TTS_MODELS[tts_info.language]
+
     try:
-        model, _ = silero_tts('ru', speaker='v5_ru')
+        model, _ = silero_tts(tts_info.language, speaker=model_id)
     except:
         return None
     
     buffer = io.BytesIO()
-    model.save_wav(**tts_info.model_dump(), audio_path=buffer)
+    model.save_wav(
+        text=tts_info.text,
+        speaker=tts_info.speaker or default_speaker,
+        sample_rate=tts_info.sample_rate,
+        audio_path=buffer,
+    )
     
     buffer.seek(0)
     audio_bytes = buffer.read()
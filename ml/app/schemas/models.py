@@ -1,15 +1,17 @@
 from pydantic import BaseModel
-from typing import Literal
 
 class LLMCreate(BaseModel):
     poi_name: str
     poi_description: str
     poi_facts: list[str]
+    language: str = 'ru'
 
 class LLMResponse(BaseModel):
     poi_generated_text: str
 
 class TTSGenerate(BaseModel):
     text: str
-    speaker: Literal['aidar', 'baya'] = 'aidar'
+    language: str = 'ru'
+    # Голос модели языка, по умолчанию голос языка из TTS_MODELS
+    speaker: str | None = None
     sample_rate: int = 24_000
\ No newline at end of file